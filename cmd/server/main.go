@@ -2,67 +2,553 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/divijg19/Dahlia/internal/api"
 	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/grpcserver"
+	"github.com/divijg19/Dahlia/internal/health"
+	"github.com/divijg19/Dahlia/internal/lifecycle"
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/divijg19/Dahlia/internal/middleware"
+	"github.com/divijg19/Dahlia/internal/server"
+	"github.com/divijg19/Dahlia/internal/tracing"
+	"github.com/divijg19/Dahlia/internal/upstream"
 	"github.com/divijg19/Dahlia/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 func main() {
+	started := time.Now()
+
 	// Load configuration
-	cfg := config.Load()
-	
+	cfg, err := config.Load()
+	if err != nil {
+		fatal(logger.New("info"), fmt.Sprintf("Invalid configuration: %v", err))
+	}
+
 	// Initialize logger
-	logger := logger.New(cfg.LogLevel)
-	
+	logger, syslogErr, err := setupLogger(cfg)
+	if err != nil {
+		fatal(logger, fmt.Sprintf("Invalid logging configuration: %v", err))
+	}
+	if syslogErr != nil {
+		logger.Error(fmt.Sprintf("Syslog unavailable, logging to stdout instead: %v", syslogErr))
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal(logger, fmt.Sprintf("Invalid configuration: %v", err))
+	}
+	for _, key := range cfg.UnknownFileKeys {
+		logger.Warn(fmt.Sprintf("Ignoring unknown key %q in config file %s", strings.ToLower(key), cfg.ConfigFile))
+	}
+
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
-	
-	router := gin.New()
-	router.Use(gin.Recovery())
-	
-	// Setup API routes
-	api.SetupRoutes(router, logger)
-	
-	// Setup server
-	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Port),
-		Handler: router,
-	}
-	
-	// Start server in goroutine
-	go func() {
-		logger.Info(fmt.Sprintf("🌸 Dahlia server starting on port %d", cfg.Port))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error(fmt.Sprintf("Failed to start server: %v", err))
-			os.Exit(1)
+
+	escalator := newLogEscalator(cfg, logger)
+
+	lc := lifecycle.New(logger, lifecycle.WithSlowHookThreshold(cfg.SlowShutdownHookThreshold))
+
+	// Registered first so buffered log lines are flushed after every other hook
+	lc.OnShutdown("logger", lifecycle.PriorityResources, func(context.Context) error {
+		return logger.Close()
+	})
+
+	// Hot-reloadable settings are applied on SIGHUP or POST /admin/reload
+	reloader := config.NewReloader(cfg, func() (*config.Config, error) {
+		loaded, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		if err := loaded.Validate(); err != nil {
+			return nil, err
+		}
+		return loaded, nil
+	}, func(effective *config.Config) {
+		if escalator != nil {
+			escalator.SetBaseLevel(effective.LogLevel)
+		} else {
+			logger.SetLevel(effective.LogLevel)
+		}
+		logger.SetStackLevel(effective.LogStackLevel)
+	})
+
+	var (
+		observations *metrics.Aggregator
+		latency      = metrics.NewLatencyWindow(cfg.LatencyWindowSize)
+		readiness    *health.Aggregator
+		prober       *health.Prober
+		report       health.Report
+		rateLimiter  *middleware.RateLimiter
+		upstreams    map[string]*upstream.Client
+		drain        = &api.Drain{}
+		conns        = server.NewConnTracker()
+		handler      http.Handler
+	)
+
+	// Start subsystems in dependency order; a failing step rolls back the
+	// ones before it
+	err = lc.Start(context.Background(),
+		lifecycle.Step{Name: "metrics", Start: func(context.Context) (lifecycle.HookFunc, error) {
+			return nil, metrics.Init(cfg.MetricsNamespace, cfg.MetricsSubsystem, cfg.LatencyBuckets)
+		}},
+		lifecycle.Step{Name: "tracing", Priority: lifecycle.PriorityResources, Start: func(ctx context.Context) (lifecycle.HookFunc, error) {
+			tracerProvider, err := tracing.Setup(ctx, cfg.TraceExporter, cfg.TraceSampleRate)
+			if err != nil || tracerProvider == nil {
+				return nil, err
+			}
+			logger.Info(fmt.Sprintf("Tracing enabled (%s exporter, sample rate %.2f)", cfg.TraceExporter, cfg.TraceSampleRate))
+			// Resources shut down after the listeners, so spans from the
+			// last requests are in the queue by the time it is flushed
+			return func(ctx context.Context) error {
+				ctx, cancel := context.WithTimeout(ctx, cfg.TraceShutdownTimeout)
+				defer cancel()
+				stats, err := tracerProvider.Shutdown(ctx)
+				logger.Info(fmt.Sprintf("Tracing stopped: %d spans flushed, %d dropped", stats.Flushed, stats.Dropped))
+				return err
+			}, nil
+		}},
+		lifecycle.Step{Name: "readiness", Priority: lifecycle.PriorityWorkers, Start: func(ctx context.Context) (lifecycle.HookFunc, error) {
+			var err error
+			if readiness, err = setupReadiness(cfg, logger); err != nil {
+				return nil, err
+			}
+			logger.Info(fmt.Sprintf("Readiness gated on: %v", readiness.Names()))
+			readiness.OnCheck(func(r health.Result) { latency.Record(r.Name, r.Duration) })
+			if escalator != nil {
+				readiness.OnChange(escalator.Update)
+			}
+			var stop lifecycle.HookFunc
+			if cfg.ReadinessWebhookURL != "" {
+				notifier := health.NewWebhookNotifier(cfg.ReadinessWebhookURL, cfg.ReadinessWebhookDebounce, logger)
+				readiness.OnTransition(notifier.Notify)
+				stop = notifier.Stop
+			}
+
+			// Ping dependencies once so slow cold starts show up in the logs
+			startupCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			report = readiness.Run(startupCtx)
+			if cfg.ReadinessProbeInterval > 0 {
+				prober = health.NewProber(readiness, cfg.ReadinessProbeInterval, cfg.ReadinessTimeout)
+			}
+			return stop, nil
+		}},
+		lifecycle.Step{Name: "upstreams", Priority: lifecycle.PriorityResources, Start: func(context.Context) (lifecycle.HookFunc, error) {
+			var err error
+			upstreams, err = upstream.NewSet(cfg.UpstreamURLs, cfg.UpstreamTimeouts, upstream.Options{
+				Timeout:         cfg.UpstreamTimeout,
+				MaxIdleConns:    cfg.UpstreamMaxIdleConns,
+				MaxConns:        cfg.UpstreamMaxConns,
+				IdleConnTimeout: cfg.UpstreamIdleConnTimeout,
+				Latency:         latency,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return func(context.Context) error {
+				for _, client := range upstreams {
+					client.CloseIdleConnections()
+				}
+				return nil
+			}, nil
+		}},
+		lifecycle.Step{Name: "routes", Start: func(context.Context) (lifecycle.HookFunc, error) {
+			observations = metrics.NewAggregator(cfg.MetricsAggregationInterval)
+
+			router := newRouter(cfg)
+			router.Use(api.Recovery(logger))
+			router.Use(middleware.Tracing())
+			router.Use(middleware.Observe(observations))
+
+			if cfg.RateLimitRPS > 0 || cfg.TenantRateLimitRPS > 0 {
+				rateLimiter = middleware.NewRateLimiter(cfg.RateLimitRPS, cfg.RateLimitBurst, cfg.RateLimitBucketTTL, cfg.RateLimitMaxBuckets)
+			}
+			if cfg.TenantRateLimitRPS > 0 {
+				overrides, err := middleware.ParseTenantRates(cfg.TenantRateLimits)
+				if err != nil {
+					return nil, err
+				}
+				identify, err := api.TenantIdentifier(cfg.TenantHeader, cfg.TenantClaim, api.JWTOptionsFromConfig(cfg), cfg.TrustedProxies)
+				if err != nil {
+					return nil, err
+				}
+				rateLimiter.LimitTenants(middleware.TenantLimits{
+					Identify:  identify,
+					Rate:      cfg.TenantRateLimitRPS,
+					Burst:     cfg.TenantRateLimitBurst,
+					Overrides: overrides,
+				})
+			}
+			deps := api.Dependencies{
+				Logger:      logger,
+				Readiness:   readiness,
+				Reloader:    reloader,
+				Drain:       drain,
+				RateLimiter: rateLimiter,
+				Latency:     latency,
+				Prober:      prober,
+				Connections: conns,
+			}
+			aliases, err := api.ParseRouteAliases(cfg.RouteAliases)
+			if err != nil {
+				return nil, err
+			}
+			handler = api.WithRouteAliases(aliases, router)
+			return nil, api.SetupRoutes(router, cfg, deps)
+		}},
+		lifecycle.Step{Name: "grpc-server", Priority: lifecycle.PriorityListeners, Start: func(context.Context) (lifecycle.HookFunc, error) {
+			if cfg.GRPCPort == 0 {
+				return nil, nil
+			}
+			return startGRPC(cfg, logger)
+		}},
+	)
+	if err != nil {
+		fatal(logger, fmt.Sprintf("Startup failed: %v", err))
+	}
+
+	// Aggregate derived metrics in the background
+	lc.Go("metrics-aggregator", observations.Run)
+	if cfg.SchedulerProbeInterval > 0 {
+		probe := metrics.NewSchedulerProbe(cfg.SchedulerProbeInterval, cfg.SchedulerLagThreshold, logger)
+		lc.Go("scheduler-probe", probe.Run)
+	}
+	if cfg.GoroutineSampleInterval > 0 {
+		sampler := metrics.NewGoroutineSampler(cfg.GoroutineSampleInterval, cfg.GoroutineThreshold, logger)
+		lc.Go("goroutine-sampler", sampler.Run)
+	}
+	if rateLimiter != nil {
+		lc.Go("rate-limit-sweeper", rateLimiter.Sweep)
+	}
+	if prober != nil {
+		lc.Go("readiness-prober", prober.Run)
+	}
+	if until, _ := readiness.Warmup(); !until.IsZero() {
+		lc.Go("readiness-warmup", func(ctx context.Context) {
+			select {
+			case <-time.After(time.Until(until)):
+				_, tolerated := readiness.Warmup()
+				logger.Info(fmt.Sprintf("Readiness warmup of %s complete, %d failing runs tolerated", cfg.ReadinessWarmup, tolerated))
+			case <-ctx.Done():
+			}
+		})
+	}
+	if cfg.MetricsPushURL != "" {
+		pusher := metrics.NewPusher(cfg.MetricsPushURL, cfg.MetricsPushJob, cfg.MetricsPushInterval, cfg.MetricsPushDeleteOnShutdown, logger)
+		lc.Go("metrics-pusher", pusher.Run)
+		// The final push runs once the HTTP server has stopped, alongside the
+		// gRPC server
+		lc.OnShutdown("metrics-push-final", lifecycle.PriorityListeners, pusher.Shutdown)
+	}
+
+	logStartup(logger, started, report)
+
+	// Reload configuration on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	lifecycle.SafeGo(logger, "config-reload", func() {
+		for range hup {
+			reloadOnSignal(logger, reloader)
 		}
-	}()
-	
+	})
+
+	// Restart periodically when a maximum lifetime is configured
+	expired := lifetimeExpiry(logger, cfg, started)
+
 	// Wait for interrupt signal for graceful shutdown
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	
+	lifecycle.SafeGo(logger, "shutdown-signal", func() {
+		awaitShutdown(logger, drain, cfg, started, quit, expired)
+		stop()
+	})
+
+	// Serve until shut down; the remaining hooks run once the HTTP server has
+	// stopped, and keep running after one fails or times out, so resources are
+	// still released before exiting non-zero
+	err = server.Run(ctx, cfg, handler, server.Options{
+		Logger: logger,
+		Conns:  conns,
+		Configure: func(srv *http.Server) {
+			// Stop reusing connections while drained so clients move to other instances
+			drain.OnChange(func(draining bool) {
+				srv.SetKeepAlivesEnabled(!draining)
+			})
+		},
+		Cleanup: lc.Shutdown,
+	})
+	if err != nil {
+		fatal(logger, fmt.Sprintf("Server %v", err))
+	}
+}
+
+// startGRPC serves the gRPC API on GRPC_PORT and returns the hook stopping
+// it. Shutdown waits for in-flight RPCs until ctx is done, then cancels them.
+func startGRPC(cfg *config.Config, logger *logger.Logger) (lifecycle.HookFunc, error) {
+	grpcLn, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		return nil, err
+	}
+	grpcSrv := grpc.NewServer()
+	grpcserver.Register(grpcSrv, logger)
+
+	lifecycle.SafeGo(logger, "grpc-server", func() {
+		logger.Info(fmt.Sprintf("gRPC server starting on port %d", cfg.GRPCPort))
+		if err := grpcSrv.Serve(grpcLn); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			fatal(logger, fmt.Sprintf("Failed to start gRPC server: %v", err))
+		}
+	})
+
+	return func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			grpcSrv.Stop()
+			return ctx.Err()
+		}
+	}, nil
+}
+
+// setupLogger builds the logger from configuration. An invalid configuration
+// is returned as err alongside a usable stdout logger to report it with. When
+// syslog output is requested but the daemon can't be reached, the logger
+// falls back to stdout and the connection error is returned as syslogErr.
+func setupLogger(cfg *config.Config) (l *logger.Logger, syslogErr, err error) {
+	fieldKeys, keysErr := logger.ParseFieldKeys(cfg.LogFieldKeys)
+	fieldOrder, orderErr := logger.ParseFieldOrder(cfg.LogFieldOrder)
+	rateLimits, limitsErr := logger.ParseRateLimits(cfg.LogRateLimits)
+	err = errors.Join(
+		keysErr,
+		orderErr,
+		limitsErr,
+		logger.ValidateBuffer(cfg.LogBufferSize, cfg.LogOverflowPolicy),
+		logger.ValidateOutput(cfg.LogOutput),
+		logger.ValidateColor(cfg.LogColor),
+	)
+
+	format := cfg.LogFormat
+	if format == "" {
+		format = logger.FormatForEnvironment(cfg.Environment)
+	}
+	opts := []logger.Option{
+		logger.WithFormat(format),
+		logger.WithColor(cfg.LogColor),
+		logger.WithSeparateErrorStream(cfg.LogSeparateErrorStream),
+		logger.WithFieldKeys(fieldKeys),
+		logger.WithFieldOrder(fieldOrder),
+		logger.WithStackLevel(cfg.LogStackLevel),
+		logger.WithBuffer(cfg.LogBufferSize, cfg.LogOverflowPolicy),
+		logger.WithDrainTimeout(cfg.LogDrainTimeout),
+		logger.WithDropHook(func() { metrics.LogsDroppedTotal.Inc() }),
+		logger.WithRateLimits(rateLimits),
+		logger.WithSuppressHook(func(level logger.LogLevel) {
+			metrics.LogsSuppressedTotal.WithLabelValues(level.String()).Inc()
+		}),
+	}
+	if err == nil && strings.EqualFold(cfg.LogOutput, logger.OutputSyslog) {
+		w, dialErr := logger.DialSyslog(cfg.SyslogNetwork, cfg.SyslogAddress, cfg.SyslogFacility, "dahlia")
+		if dialErr != nil {
+			syslogErr = dialErr
+		} else {
+			opts = append(opts, logger.WithSyslog(w))
+		}
+	}
+
+	return logger.New(cfg.LogLevel, opts...), syslogErr, err
+}
+
+// newRouter returns a bare engine configured from cfg, before any middleware
+// or routes are registered
+func newRouter(cfg *config.Config) *gin.Engine {
+	router := gin.New()
+	router.MaxMultipartMemory = int64(cfg.MaxMultipartMemory)
+	return router
+}
+
+// newLogEscalator returns an escalator raising the log level while readiness
+// fails, or nil when no incident log level is configured
+func newLogEscalator(cfg *config.Config, l *logger.Logger) *logger.Escalator {
+	if cfg.IncidentLogLevel == "" {
+		return nil
+	}
+	return logger.NewEscalator(l, cfg.IncidentLogLevel, cfg.LogLevel, cfg.IncidentLogRestoreDelay)
+}
+
+// setupReadiness registers the dependency checkers and selects the ones
+// configured to gate readiness
+func setupReadiness(cfg *config.Config, logger *logger.Logger) (*health.Aggregator, error) {
+	database, err := health.NewPostgresChecker("database", cfg.DatabaseURL)
+	if err != nil {
+		return nil, err
+	}
+	redis, err := health.NewRedisChecker("redis", cfg.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+
+	checkers := []health.Checker{database, redis}
+	if cfg.HealthCommand != "" {
+		command, err := health.NewCommandChecker("command", cfg.HealthCommand, cfg.HealthCommandTimeout, logger)
+		if err != nil {
+			return nil, err
+		}
+		checkers = append(checkers, command)
+	}
+	weights, err := health.ParseWeights(cfg.HealthCheckWeights)
+	if err != nil {
+		return nil, err
+	}
+	if err := health.SetWeights(checkers, weights); err != nil {
+		return nil, err
+	}
+	if err := health.SetNonCritical(checkers, cfg.HealthCheckNonCritical); err != nil {
+		return nil, err
+	}
+
+	readiness, err := health.NewAggregator(cfg.ReadinessChecks, checkers...)
+	if err != nil {
+		return nil, err
+	}
+	readiness.SetScoreThresholds(cfg.HealthyScore, cfg.DegradedScore)
+	readiness.SetHysteresis(cfg.ReadinessSuccessThreshold, cfg.ReadinessFailureThreshold)
+	readiness.SetConcurrency(cfg.HealthCheckConcurrency)
+	readiness.SetCheckTimeout(cfg.HealthCheckTimeout)
+	readiness.SetWarmup(cfg.ReadinessWarmup)
+	return readiness, nil
+}
+
+// logStartup emits a single summary line with the total startup time and the
+// latency of each dependency check, with per-dependency detail at DEBUG
+func logStartup(logger *logger.Logger, started time.Time, report health.Report) {
+	names := make([]string, 0, len(report.Results))
+	for name := range report.Results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := make([]string, 0, len(names)+1)
+	total := time.Since(started)
+	fields = append(fields, fmt.Sprintf("total=%s", total.Round(time.Millisecond)))
+	for _, name := range names {
+		result := report.Results[name]
+		fields = append(fields, fmt.Sprintf("%s=%s", name, result.Duration.Round(time.Millisecond)))
+		if result.Healthy {
+			logger.Debug(fmt.Sprintf("Startup check %s succeeded in %s", name, result.Duration))
+		} else {
+			logger.Debug(fmt.Sprintf("Startup check %s failed in %s: %s", name, result.Duration, result.Error))
+		}
+	}
+
+	metrics.StartupDuration.Set(total.Seconds())
+	logger.Info("Startup complete " + strings.Join(fields, " "))
+}
+
+// reloadOnSignal reloads configuration and logs what changed
+func reloadOnSignal(logger *logger.Logger, reloader *config.Reloader) {
+	changes, err := reloader.Reload()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Config reload failed: %s", config.RedactError(err)))
+		return
+	}
+	for _, change := range changes {
+		if change.RestartRequired {
+			logger.Warn(fmt.Sprintf("Config field %s changed but requires a restart", change.Field))
+		} else {
+			logger.Info(fmt.Sprintf("Config field %s reloaded: %s -> %s", change.Field, change.Old, change.New))
+		}
+	}
+	logger.Info(fmt.Sprintf("Config reloaded on SIGHUP: %d fields changed", len(changes)))
+}
+
+// defaultProbeWait bounds the wait for failed readiness probes when no
+// shutdown readiness delay is configured
+const defaultProbeWait = 30 * time.Second
+
+// lifetimeExpiry returns a channel receiving once the planned process
+// lifetime since started has elapsed, or nil when no lifetime is configured
+func lifetimeExpiry(logger *logger.Logger, cfg *config.Config, started time.Time) <-chan time.Time {
+	if cfg.MaxProcessLifetime <= 0 {
+		return nil
+	}
+	lifetime := plannedLifetime(cfg.MaxProcessLifetime, cfg.MaxProcessLifetimeJitter)
+	logger.Info(fmt.Sprintf("Maximum process lifetime %s: planned restart at %s (in %s)",
+		cfg.MaxProcessLifetime, started.Add(lifetime).UTC().Format(time.RFC3339), time.Until(started.Add(lifetime)).Round(time.Second)))
+	return time.After(time.Until(started.Add(lifetime)))
+}
+
+// plannedLifetime returns how long the process runs before restarting:
+// lifetime shortened by a random amount of up to jitter (0-1) of it
+func plannedLifetime(lifetime time.Duration, jitter float64) time.Duration {
+	return lifetime - time.Duration(rand.Float64()*jitter*float64(lifetime))
+}
+
+// awaitShutdown blocks until a shutdown signal arrives or the process
+// lifetime expires, then until the load balancer has deregistered the
+// instance
+func awaitShutdown(logger *logger.Logger, drain *api.Drain, cfg *config.Config, started time.Time, quit <-chan os.Signal, expired <-chan time.Time) {
+	select {
+	case <-quit:
+	case <-expired:
+		logger.Info(fmt.Sprintf("Maximum process lifetime reached after %s, shutting down for restart", time.Since(started).Round(time.Second)))
+	}
 	logger.Info("Shutting down server...")
-	
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error(fmt.Sprintf("Server forced to shutdown: %v", err))
-		os.Exit(1)
-	}
-	
-	logger.Info("Server exited")
-}
\ No newline at end of file
+	awaitDeregistration(logger, drain, cfg)
+}
+
+// awaitDeregistration flips readiness to failing and waits for the load
+// balancer to notice before shutdown stops the listeners, either by observing
+// the configured number of failed /ready probes or by waiting a fixed delay.
+// Other requests keep being served meanwhile.
+func awaitDeregistration(logger *logger.Logger, drain *api.Drain, cfg *config.Config) {
+	drain.SetShuttingDown()
+	probes, delay := cfg.ShutdownReadinessProbes, cfg.ShutdownReadinessDelay
+	if probes <= 0 && delay <= 0 {
+		return
+	}
+
+	if probes <= 0 {
+		logger.Info(fmt.Sprintf("Reporting not ready for %s before shutdown", delay))
+		time.Sleep(delay)
+		return
+	}
+
+	if delay <= 0 {
+		delay = defaultProbeWait
+	}
+	started := time.Now()
+	seen := drain.WaitForProbes(probes, delay)
+	if seen < probes {
+		logger.Warn(fmt.Sprintf("Only %d of %d failed readiness probes observed after %s; shutting down anyway", seen, probes, delay))
+		return
+	}
+	logger.Info(fmt.Sprintf("Observed %d failed readiness probes in %s; load balancer has deregistered", seen, time.Since(started).Round(time.Millisecond)))
+}
+
+// fatal logs msg, flushes any buffered log output and exits
+func fatal(logger *logger.Logger, msg string) {
+	logger.Error(msg)
+	logger.Close()
+	os.Exit(1)
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/api"
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/health"
+	"github.com/divijg19/Dahlia/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func nopLogger() *logger.Logger {
+	return logger.New("error", logger.WithOutput(io.Discard, io.Discard))
+}
+
+func TestPlannedLifetime(t *testing.T) {
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		jitter   float64
+		min, max time.Duration
+	}{
+		{name: "no jitter", lifetime: time.Hour, jitter: 0, min: time.Hour, max: time.Hour},
+		{name: "ten percent", lifetime: time.Hour, jitter: 0.1, min: 54 * time.Minute, max: time.Hour},
+		{name: "full jitter", lifetime: time.Hour, jitter: 1, min: 0, max: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				got := plannedLifetime(tt.lifetime, tt.jitter)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("plannedLifetime(%s, %g) = %s, want within [%s, %s]", tt.lifetime, tt.jitter, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestLifetimeExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		wantFire bool
+	}{
+		{name: "disabled", lifetime: 0},
+		{name: "short lifetime", lifetime: 50 * time.Millisecond, wantFire: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MaxProcessLifetime: tt.lifetime}
+			started := time.Now()
+			expired := lifetimeExpiry(nopLogger(), cfg, started)
+
+			if !tt.wantFire {
+				if expired != nil {
+					t.Fatal("lifetimeExpiry() returned a channel with no lifetime configured")
+				}
+				return
+			}
+			select {
+			case <-expired:
+				if elapsed := time.Since(started); elapsed < tt.lifetime {
+					t.Fatalf("shutdown initiated after %s, before the %s lifetime", elapsed, tt.lifetime)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("shutdown not initiated within a second of a %s lifetime", tt.lifetime)
+			}
+		})
+	}
+}
+
+// newShutdownRouter serves the routes with drain as the instance's drain state
+func newShutdownRouter(t *testing.T, drain *api.Drain) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readiness, err := health.NewAggregator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	deps := api.Dependencies{Logger: nopLogger(), Drain: drain, Readiness: readiness}
+	if err := api.SetupRoutes(router, cfg, deps); err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func get(router http.Handler, path string) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestAwaitDeregistration(t *testing.T) {
+	tests := []struct {
+		name    string
+		probes  int
+		delay   time.Duration
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{name: "no wait configured", maxWait: 100 * time.Millisecond},
+		{name: "fixed delay", delay: 200 * time.Millisecond, minWait: 200 * time.Millisecond, maxWait: time.Second},
+		{name: "probes observed", probes: 2, delay: 5 * time.Second, maxWait: 2 * time.Second},
+		{name: "probes never arrive", probes: 50, delay: 200 * time.Millisecond, minWait: 200 * time.Millisecond, maxWait: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drain := &api.Drain{}
+			router := newShutdownRouter(t, drain)
+			cfg := &config.Config{ShutdownReadinessProbes: tt.probes, ShutdownReadinessDelay: tt.delay}
+
+			start := time.Now()
+			done := make(chan struct{})
+			go func() {
+				awaitDeregistration(nopLogger(), drain, cfg)
+				close(done)
+			}()
+
+			// Simulate the load balancer probing while clients keep sending
+			// requests: only /ready fails
+			for !drain.ShuttingDown() {
+				time.Sleep(time.Millisecond)
+			}
+		probing:
+			for range 3 {
+				select {
+				case <-done:
+					break probing
+				default:
+				}
+				if code := get(router, "/api/v1/status"); code != http.StatusOK {
+					t.Fatalf("GET /api/v1/status during deregistration = %d, want 200", code)
+				}
+				if code := get(router, "/ready"); code != http.StatusServiceUnavailable {
+					t.Fatalf("GET /ready during deregistration = %d, want 503", code)
+				}
+			}
+			<-done
+			elapsed := time.Since(start)
+
+			if drain.Draining() {
+				t.Fatal("deregistration drained the instance, rejecting requests")
+			}
+			if elapsed < tt.minWait || elapsed > tt.maxWait {
+				t.Fatalf("awaitDeregistration took %s, want within [%s, %s]", elapsed, tt.minWait, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestLifetimeShutdownKeepsServing(t *testing.T) {
+	drain := &api.Drain{}
+	router := newShutdownRouter(t, drain)
+	cfg := &config.Config{MaxProcessLifetime: 20 * time.Millisecond, ShutdownReadinessDelay: 300 * time.Millisecond}
+
+	started := time.Now()
+	done := make(chan struct{})
+	go func() {
+		awaitShutdown(nopLogger(), drain, cfg, started, nil, lifetimeExpiry(nopLogger(), cfg, started))
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for get(router, "/ready") != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatal("readiness didn't fail after the process lifetime expired")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("shutdown didn't wait for deregistration")
+	default:
+	}
+	if code := get(router, "/api/v1/status"); code != http.StatusOK {
+		t.Fatalf("GET /api/v1/status during the lifetime restart wait = %d, want 200", code)
+	}
+	<-done
+}
+
+func TestLogStartupSummary(t *testing.T) {
+	report := health.Report{Results: map[string]health.Result{
+		"database": {Name: "database", Healthy: true, Duration: 12 * time.Millisecond},
+		"redis":    {Name: "redis", Error: "connection refused", Duration: 3 * time.Millisecond},
+	}}
+
+	tests := []struct {
+		level     string
+		wantDebug bool
+	}{
+		{level: "info"},
+		{level: "debug", wantDebug: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			var out bytes.Buffer
+			l := logger.New(tt.level, logger.WithOutput(&out, &out), logger.WithColor(logger.ColorNever))
+			logStartup(l, time.Now().Add(-time.Second), report)
+
+			var summary string
+			for _, line := range strings.Split(out.String(), "\n") {
+				if strings.Contains(line, "Startup complete") {
+					summary = line
+				}
+			}
+			for _, want := range []string{"total=1", "database=12ms", "redis=3ms"} {
+				if !strings.Contains(summary, want) {
+					t.Errorf("summary %q is missing %s", summary, want)
+				}
+			}
+			detailed := strings.Contains(out.String(), "Startup check database succeeded") &&
+				strings.Contains(out.String(), "Startup check redis failed in 3ms: connection refused")
+			if detailed != tt.wantDebug {
+				t.Errorf("per-dependency detail logged = %v, want %v:\n%s", detailed, tt.wantDebug, out.String())
+			}
+		})
+	}
+}
+
+func TestNewRouterMaxMultipartMemory(t *testing.T) {
+	// a part larger than the memory limit is spooled to a temporary file
+	// rather than held in memory
+	tests := []struct {
+		name   string
+		limit  int
+		onDisk bool
+	}{
+		{name: "under limit", limit: 1 << 20, onDisk: false},
+		{name: "over limit", limit: 1 << 10, onDisk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newRouter(&config.Config{MaxMultipartMemory: tt.limit})
+			if router.MaxMultipartMemory != int64(tt.limit) {
+				t.Fatalf("MaxMultipartMemory = %d, want %d", router.MaxMultipartMemory, tt.limit)
+			}
+
+			var onDisk bool
+			router.POST("/upload", func(c *gin.Context) {
+				header, err := c.FormFile("file")
+				if err != nil {
+					c.AbortWithError(http.StatusBadRequest, err)
+					return
+				}
+				f, err := header.Open()
+				if err != nil {
+					c.AbortWithError(http.StatusInternalServerError, err)
+					return
+				}
+				defer f.Close()
+				_, onDisk = f.(*os.File)
+				c.Status(http.StatusNoContent)
+			})
+
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			part, err := form.CreateFormFile("file", "payload.bin")
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write(bytes.Repeat([]byte("x"), 4<<10))
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusNoContent)
+			}
+			if onDisk != tt.onDisk {
+				t.Errorf("part on disk = %v, want %v", onDisk, tt.onDisk)
+			}
+		})
+	}
+}
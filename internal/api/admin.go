@@ -0,0 +1,62 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth middleware requires the configured admin token as a Bearer token
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "unauthorized",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// reloadConfig reloads configuration and reports what changed. A failed
+// reload names only the offending settings, since the error itself may
+// quote secret values or the config file.
+func reloadConfig(reloader *config.Reloader, logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		changes, err := reloader.Reload()
+		if err != nil {
+			logger.Error(fmt.Sprintf("Config reload failed: %s", config.RedactError(err)))
+			fields := config.ErrorFields(err)
+			if fields == nil {
+				fields = []string{}
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":  "reload failed",
+				"fields": fields,
+			})
+			return
+		}
+
+		if changes == nil {
+			changes = []config.Change{}
+		}
+		restart := []string{}
+		for _, change := range changes {
+			if change.RestartRequired {
+				restart = append(restart, change.Field)
+			}
+		}
+		logger.Info(fmt.Sprintf("Config reloaded via admin endpoint: %d changed, %d require restart", len(changes), len(restart)))
+
+		c.JSON(http.StatusOK, gin.H{
+			"changed":          changes,
+			"restart_required": restart,
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// recordingLogger keeps the messages logged at each level
+type recordingLogger struct {
+	nopLogger
+	errors []string
+	warns  []string
+	infos  []string
+}
+
+func (l *recordingLogger) Error(msg string) { l.errors = append(l.errors, msg) }
+func (l *recordingLogger) Warn(msg string)  { l.warns = append(l.warns, msg) }
+func (l *recordingLogger) Info(msg string)  { l.infos = append(l.infos, msg) }
+
+func TestReloadConfigFailureOmitsSecrets(t *testing.T) {
+	const password = "hunter2"
+
+	tests := []struct {
+		name       string
+		err        error
+		wantFields []string
+	}{
+		{
+			name: "invalid setting",
+			err: errors.Join(
+				&config.FieldError{Field: "DATABASE_URL", Err: errors.New("DATABASE_URL: invalid URL")},
+				&config.FieldError{Field: "PORT", Err: errors.New("PORT 0 is outside 1-65535")},
+			),
+			wantFields: []string{"DATABASE_URL", "PORT"},
+		},
+		{
+			name:       "unattributed error",
+			err:        fmt.Errorf("parse config file: database_url: postgres://app:%s@db", password),
+			wantFields: []string{},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloader := config.NewReloader(&config.Config{}, func() (*config.Config, error) {
+				return nil, fmt.Errorf("%w (password %s)", tt.err, password)
+			}, func(*config.Config) {})
+			logger := &recordingLogger{}
+			router := gin.New()
+			router.POST("/admin/reload", reloadConfig(reloader, logger))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/reload", nil))
+
+			if w.Code != http.StatusInternalServerError {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+			}
+			var body struct {
+				Error  string   `json:"error"`
+				Fields []string `json:"fields"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Error != "reload failed" || !slices.Equal(body.Fields, tt.wantFields) {
+				t.Errorf("body = %+v, want error %q and fields %q", body, "reload failed", tt.wantFields)
+			}
+			if strings.Contains(w.Body.String(), password) {
+				t.Errorf("response %s leaks the password", w.Body)
+			}
+			for _, msg := range logger.errors {
+				if strings.Contains(msg, password) {
+					t.Errorf("log %q leaks the password", msg)
+				}
+			}
+		})
+	}
+}
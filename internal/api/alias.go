@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Route alias modes
+const (
+	// AliasRewrite serves the new path's handler under the old path
+	AliasRewrite = "rewrite"
+	// AliasMovedPermanently redirects with 301, which clients may turn into a GET
+	AliasMovedPermanently = "301"
+	// AliasPermanentRedirect redirects with 308, preserving the method and body
+	AliasPermanentRedirect = "308"
+)
+
+// RouteAlias maps a deprecated path to the path that replaced it
+type RouteAlias struct {
+	From string
+	To   string
+	Mode string
+}
+
+// ParseRouteAliases parses aliases keyed by old path, each valued
+// "<mode>:<new path>" with mode rewrite, 301 or 308
+func ParseRouteAliases(aliases map[string]string) ([]RouteAlias, error) {
+	parsed := make([]RouteAlias, 0, len(aliases))
+	for from, spec := range aliases {
+		mode, to, ok := strings.Cut(spec, ":")
+		if !ok || !strings.HasPrefix(to, "/") || !strings.HasPrefix(from, "/") {
+			return nil, fmt.Errorf("route alias %s=%s: want /old=<mode>:/new", from, spec)
+		}
+		switch mode {
+		case AliasRewrite, AliasMovedPermanently, AliasPermanentRedirect:
+		default:
+			return nil, fmt.Errorf("route alias %s: unknown mode %q (want %s, %s or %s)", from, mode, AliasRewrite, AliasMovedPermanently, AliasPermanentRedirect)
+		}
+		if from == to {
+			return nil, fmt.Errorf("route alias %s points to itself", from)
+		}
+		parsed = append(parsed, RouteAlias{From: from, To: to, Mode: mode})
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].From < parsed[j].From })
+	return parsed, nil
+}
+
+// WithRouteAliases serves requests for aliased paths before routing: rewrite
+// aliases are handled by next as if the new path had been requested, so the
+// middleware chain runs once, and redirect aliases answer with a redirect to
+// the new path, keeping the query string. Other requests pass through.
+func WithRouteAliases(aliases []RouteAlias, next http.Handler) http.Handler {
+	if len(aliases) == 0 {
+		return next
+	}
+	byPath := make(map[string]RouteAlias, len(aliases))
+	for _, alias := range aliases {
+		byPath[alias.From] = alias
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		alias, ok := byPath[r.URL.Path]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		switch alias.Mode {
+		case AliasRewrite:
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = alias.To
+			r2.URL.RawPath = ""
+			next.ServeHTTP(w, r2)
+		case AliasMovedPermanently:
+			http.Redirect(w, r, redirectTarget(alias.To, r), http.StatusMovedPermanently)
+		default:
+			http.Redirect(w, r, redirectTarget(alias.To, r), http.StatusPermanentRedirect)
+		}
+	})
+}
+
+func redirectTarget(path string, r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return path
+	}
+	return path + "?" + r.URL.RawQuery
+}
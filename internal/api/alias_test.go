@@ -0,0 +1,86 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWithRouteAliases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	aliases, err := ParseRouteAliases(map[string]string{
+		"/v0/items":  "rewrite:/v1/items",
+		"/v0/moved":  "301:/v1/items",
+		"/v0/upload": "308:/v1/items",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	var served int
+	handler := func(c *gin.Context) {
+		served++
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "%s %s %s", c.Request.Method, c.Request.URL.Path, body)
+	}
+	router.GET("/v1/items", handler)
+	router.POST("/v1/items", handler)
+	h := WithRouteAliases(aliases, router)
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		want     int
+		wantBody string
+		location string
+	}{
+		{name: "rewrite", method: http.MethodPost, target: "/v0/items", body: "payload", want: http.StatusOK, wantBody: "POST /v1/items payload"},
+		{name: "new path", method: http.MethodGet, target: "/v1/items", want: http.StatusOK, wantBody: "GET /v1/items "},
+		{name: "moved permanently", method: http.MethodGet, target: "/v0/moved?page=2", want: http.StatusMovedPermanently, location: "/v1/items?page=2"},
+		{name: "permanent redirect", method: http.MethodPost, target: "/v0/upload", want: http.StatusPermanentRedirect, location: "/v1/items"},
+		{name: "unaliased", method: http.MethodGet, target: "/v0/other", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			served = 0
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.wantBody != "" {
+				if got := w.Body.String(); got != tt.wantBody {
+					t.Errorf("body = %q, want %q", got, tt.wantBody)
+				}
+				if served != 1 {
+					t.Errorf("handler ran %d times, want once", served)
+				}
+			}
+			if got := w.Header().Get("Location"); got != tt.location {
+				t.Errorf("Location = %q, want %q", got, tt.location)
+			}
+		})
+	}
+}
+
+func TestParseRouteAliasesRejects(t *testing.T) {
+	for _, spec := range []map[string]string{
+		{"/old": "/new"},
+		{"/old": "302:/new"},
+		{"/old": "rewrite:new"},
+		{"old": "rewrite:/new"},
+		{"/same": "rewrite:/same"},
+	} {
+		if _, err := ParseRouteAliases(spec); err == nil {
+			t.Errorf("ParseRouteAliases(%v) succeeded, want an error", spec)
+		}
+	}
+}
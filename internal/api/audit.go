@@ -0,0 +1,19 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit middleware records who called which endpoint and the outcome, for
+// groups whose calls change server state and should leave a trail. Rejected
+// calls are recorded too when it runs ahead of the group's auth.
+func Audit(logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		LoggerFromContext(c, logger).Info(fmt.Sprintf("Audit: %s %s -> %d from %s",
+			c.Request.Method, c.Request.URL.Path, c.Writer.Status(), c.ClientIP()))
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const testSecret = "test-secret"
+
+// signToken signs claims with method and key, for tokens GenerateToken can't
+// produce
+func signToken(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthRequired(t *testing.T) {
+	valid, err := GenerateToken(testSecret, jwt.MapClaims{"sub": "alice"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired := signToken(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{
+		"sub": "alice",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	wrongKey, err := GenerateToken("other-secret", jwt.MapClaims{"sub": "alice"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"sub": "alice"})
+	hs512 := signToken(t, jwt.SigningMethodHS512, []byte(testSecret), jwt.MapClaims{"sub": "alice"})
+
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantSub string
+		// reason is the failure reason counted, empty when accepted
+		reason string
+	}{
+		{name: "valid", header: "Bearer " + valid, want: http.StatusOK, wantSub: "alice"},
+		{name: "expired", header: "Bearer " + expired, want: http.StatusUnauthorized, reason: authExpired},
+		{name: "bad signature", header: "Bearer " + wrongKey, want: http.StatusUnauthorized, reason: authInvalidSignature},
+		{name: "alg none", header: "Bearer " + unsigned, want: http.StatusUnauthorized, reason: authInvalidSignature},
+		{name: "wrong alg", header: "Bearer " + hs512, want: http.StatusUnauthorized, reason: authInvalidSignature},
+		{name: "malformed", header: "Bearer not-a-token", want: http.StatusUnauthorized, reason: authMalformed},
+		{name: "missing header", want: http.StatusUnauthorized, reason: authMissing},
+		{name: "not a bearer token", header: "Basic " + valid, want: http.StatusUnauthorized, reason: authMissing},
+	}
+	reasons := []string{authMissing, authExpired, authInvalidSignature, authMalformed, authInvalidClaims, authInvalidIssuer, authInvalidAudience}
+	failures := func() map[string]float64 {
+		counts := map[string]float64{}
+		for _, reason := range reasons {
+			counts[reason] = testutil.ToFloat64(metrics.AuthFailuresTotal.WithLabelValues(reason))
+		}
+		return counts
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sub string
+			logger := &recordingLogger{}
+			router := gin.New()
+			router.GET("/me", AuthRequired(JWTOptions{Secret: testSecret}, logger), func(c *gin.Context) {
+				claims, _ := ClaimsFromContext(c)
+				sub, _ = claims.GetSubject()
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			before := failures()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if sub != tt.wantSub {
+				t.Errorf("claims subject = %q, want %q", sub, tt.wantSub)
+			}
+			for reason, count := range failures() {
+				want := before[reason]
+				if reason == tt.reason {
+					want++
+				}
+				if count != want {
+					t.Errorf("auth failures{reason=%q} = %v, want %v", reason, count, want)
+				}
+			}
+			if tt.reason == "" {
+				return
+			}
+			if len(logger.warns) != 1 || !strings.Contains(logger.warns[0], "reason="+tt.reason) {
+				t.Errorf("warnings = %q, want one naming reason %s", logger.warns, tt.reason)
+			}
+			if raw, _ := strings.CutPrefix(tt.header, "Bearer "); raw != "" && strings.Contains(logger.warns[0], raw) {
+				t.Errorf("warning %q contains the token", logger.warns[0])
+			}
+		})
+	}
+}
+
+func TestAuthRequiredIssuerAndAudience(t *testing.T) {
+	opts := JWTOptions{Secret: testSecret, Issuers: []string{"issuer-a", "issuer-b"}, Audiences: []string{"dahlia"}}
+	token := func(claims jwt.MapClaims) string {
+		raw, err := GenerateToken(testSecret, claims, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		want   int
+		reason string
+	}{
+		{name: "accepted", claims: jwt.MapClaims{"iss": "issuer-b", "aud": "dahlia"}, want: http.StatusOK},
+		{name: "audience in a list", claims: jwt.MapClaims{"iss": "issuer-a", "aud": []string{"other", "dahlia"}}, want: http.StatusOK},
+		{name: "wrong audience", claims: jwt.MapClaims{"iss": "issuer-a", "aud": "other"}, want: http.StatusUnauthorized, reason: authInvalidAudience},
+		// the parser reports a missing aud as a missing required claim
+		{name: "missing audience", claims: jwt.MapClaims{"iss": "issuer-a"}, want: http.StatusUnauthorized, reason: authInvalidClaims},
+		{name: "wrong issuer", claims: jwt.MapClaims{"iss": "issuer-c", "aud": "dahlia"}, want: http.StatusUnauthorized, reason: authInvalidIssuer},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			router := gin.New()
+			router.GET("/me", AuthRequired(opts, logger), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token(tt.claims))
+			var before float64
+			if tt.reason != "" {
+				before = testutil.ToFloat64(metrics.AuthFailuresTotal.WithLabelValues(tt.reason))
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.reason == "" {
+				return
+			}
+			if got := testutil.ToFloat64(metrics.AuthFailuresTotal.WithLabelValues(tt.reason)) - before; got != 1 {
+				t.Errorf("auth failures{reason=%q} grew by %v, want 1", tt.reason, got)
+			}
+			if len(logger.warns) != 1 || !strings.Contains(logger.warns[0], "reason="+tt.reason) {
+				t.Errorf("warnings = %q, want one naming reason %s", logger.warns, tt.reason)
+			}
+		})
+	}
+}
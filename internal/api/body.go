@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedBodyKey is the context key holding the buffered request body
+const cachedBodyKey = "cached_body"
+
+// CacheBody middleware reads the request body once, rejecting bodies larger
+// than limit bytes with 413, and replaces it with an in-memory copy. Code
+// that reads the body before the handler, such as a signature check, gets
+// the bytes from CachedBody, which also rewinds the body for the next reader.
+func CacheBody(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			rejectLargeBody(c, limit)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+		c.Request.Body.Close()
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, APIError{
+				Code:    CodeInvalidRequest,
+				Message: fmt.Sprintf("failed to read request body: %v", err),
+			})
+			return
+		}
+		if int64(len(body)) > limit {
+			rejectLargeBody(c, limit)
+			return
+		}
+
+		c.Set(cachedBodyKey, body)
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// CachedBody returns the request body buffered by CacheBody and rewinds the
+// request body to its start, so whoever reads it next sees it in full. It
+// reports false when the body wasn't cached.
+func CachedBody(c *gin.Context) ([]byte, bool) {
+	v, ok := c.Get(cachedBodyKey)
+	if !ok {
+		return nil, false
+	}
+	body, ok := v.([]byte)
+	if ok {
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return body, ok
+}
+
+func rejectLargeBody(c *gin.Context, limit int64) {
+	abortWithError(c, http.StatusRequestEntityTooLarge, APIError{
+		Code:    CodeRequestTooLarge,
+		Message: fmt.Sprintf("request body exceeds %d bytes", limit),
+	})
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCacheBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "within limit", body: `{"name":"dahlia"}`, status: http.StatusOK},
+		{name: "empty", body: "", status: http.StatusOK},
+		{name: "over limit", body: strings.Repeat("x", 65), status: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CacheBody(64))
+			router.POST("/", func(c *gin.Context) {
+				// A signature check reads the body before the handler
+				cached, _ := CachedBody(c)
+				if _, err := io.ReadAll(c.Request.Body); err != nil {
+					t.Errorf("first read: %v", err)
+				}
+
+				// The handler reads it again in full
+				cached, _ = CachedBody(c)
+				got, err := io.ReadAll(c.Request.Body)
+				if err != nil {
+					t.Errorf("second read: %v", err)
+				}
+				if string(got) != tt.body || string(cached) != tt.body {
+					t.Errorf("body = %q (cached %q), want %q", got, cached, tt.body)
+				}
+
+				// Reading past the end keeps reporting EOF instead of replaying
+				buf := make([]byte, 8)
+				for range 3 {
+					if n, err := c.Request.Body.Read(buf); n != 0 || !errors.Is(err, io.EOF) {
+						t.Fatalf("read after EOF = %d, %v; want 0, EOF", n, err)
+					}
+				}
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// clientCertSubjectKey is the context key holding the verified client certificate subject
+const clientCertSubjectKey = "client_cert_subject"
+
+// ClientCert middleware exposes the subject of a verified client certificate
+// in the request context for use in authorization decisions
+func ClientCert() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tls := c.Request.TLS; tls != nil && len(tls.VerifiedChains) > 0 {
+			c.Set(clientCertSubjectKey, tls.VerifiedChains[0][0].Subject.String())
+		}
+		c.Next()
+	}
+}
+
+// ClientCertSubject returns the verified client certificate subject, or an
+// empty string when the client didn't present one
+func ClientCertSubject(c *gin.Context) string {
+	return c.GetString(clientCertSubjectKey)
+}
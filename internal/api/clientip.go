@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedProxiesNone disables trusting forwarding headers from any proxy
+const TrustedProxiesNone = "none"
+
+// ConfigureTrustedProxies sets which proxies may supply the client IP through
+// forwarding headers and returns a description of the result for the startup
+// log. An empty list or "none" trusts no proxy, so the client IP is always
+// the connection's address and clients can't spoof it.
+func ConfigureTrustedProxies(router *gin.Engine, proxies []string) (string, error) {
+	if len(proxies) == 0 || len(proxies) == 1 && strings.EqualFold(proxies[0], TrustedProxiesNone) {
+		if err := router.SetTrustedProxies(nil); err != nil {
+			return "", err
+		}
+		return "Trusting no proxies; client IP is the connection address", nil
+	}
+
+	if err := router.SetTrustedProxies(proxies); err != nil {
+		return "", fmt.Errorf("trusted proxies: %w", err)
+	}
+	return fmt.Sprintf("Trusting forwarding headers from %s (headers: %s)",
+		strings.Join(proxies, ", "), strings.Join(router.RemoteIPHeaders, ", ")), nil
+}
+
+// LogClientIP middleware logs at DEBUG how the client IP of every request
+// was resolved: the connection address, the forwarding headers and the
+// resulting IP. It is meant for verifying the trusted proxy setup.
+func LogClientIP(router *gin.Engine, logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields := []string{"remote_addr=" + c.RemoteIP()}
+		for _, name := range router.RemoteIPHeaders {
+			if value := c.GetHeader(name); value != "" {
+				fields = append(fields, fmt.Sprintf("%s=%q", strings.ToLower(name), value))
+			}
+		}
+		fields = append(fields, "client_ip="+c.ClientIP(), "request_id="+RequestIDFromContext(c))
+		logger.Debug("Client IP resolved: " + strings.Join(fields, " "))
+		c.Next()
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		path    string
+		// forwarded are the X-Forwarded-For values of two requests from the
+		// same connection address
+		forwarded [2]string
+		want      int
+	}{
+		{name: "forwarded header ignored by default", path: "/api/v1/status", forwarded: [2]string{"198.51.100.1", "198.51.100.2"}, want: http.StatusTooManyRequests},
+		{name: "forwarded header ignored with none", proxies: []string{"none"}, path: "/api/v1/status", forwarded: [2]string{"198.51.100.1", "198.51.100.2"}, want: http.StatusTooManyRequests},
+		{name: "forwarded header from trusted proxy", proxies: []string{"10.0.0.0/8"}, path: "/api/v1/status", forwarded: [2]string{"198.51.100.1", "198.51.100.2"}, want: http.StatusOK},
+		{name: "health is not limited", path: "/health", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.TrustedProxies = tt.proxies
+			}, Dependencies{RateLimiter: middleware.NewRateLimiter(0.001, 1, time.Minute, 0)})
+
+			var code int
+			for _, forwarded := range tt.forwarded {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				req.RemoteAddr = "10.0.0.1:4000"
+				if forwarded != "" {
+					req.Header.Set("X-Forwarded-For", forwarded)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				code = w.Code
+			}
+			if code != tt.want {
+				t.Errorf("second request status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustedProxyChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		proxies   []string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{name: "chain of trusted proxies", proxies: []string{"10.0.0.0/8"}, remote: "10.0.0.1:4000", forwarded: "203.0.113.9, 10.1.1.1, 10.2.2.2", want: "203.0.113.9"},
+		{name: "spoofed entry before an untrusted hop", proxies: []string{"10.0.0.0/8"}, remote: "10.0.0.1:4000", forwarded: "198.51.100.7, 203.0.113.9, 10.1.1.1", want: "203.0.113.9"},
+		{name: "several trusted ranges", proxies: []string{"10.0.0.0/8", "172.16.0.0/12"}, remote: "172.16.0.5:4000", forwarded: "203.0.113.9, 10.1.1.1", want: "203.0.113.9"},
+		{name: "untrusted connection", proxies: []string{"10.0.0.0/8"}, remote: "192.0.2.1:4000", forwarded: "203.0.113.9", want: "192.0.2.1"},
+		{name: "no trusted proxies", proxies: []string{TrustedProxiesNone}, remote: "10.0.0.1:4000", forwarded: "203.0.113.9", want: "10.0.0.1"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if _, err := ConfigureTrustedProxies(router, tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
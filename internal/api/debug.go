@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"slices"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/divijg19/Dahlia/internal/server"
+	"github.com/gin-gonic/gin"
+)
+
+// heapStats is the subset of runtime.MemStats reported by the GC endpoint
+type heapStats struct {
+	HeapAlloc   uint64 `json:"heap_alloc_bytes"`
+	HeapInuse   uint64 `json:"heap_inuse_bytes"`
+	HeapObjects uint64 `json:"heap_objects"`
+	HeapSys     uint64 `json:"heap_sys_bytes"`
+	NumGC       uint32 `json:"num_gc"`
+}
+
+func readHeapStats() heapStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return heapStats{
+		HeapAlloc:   m.HeapAlloc,
+		HeapInuse:   m.HeapInuse,
+		HeapObjects: m.HeapObjects,
+		HeapSys:     m.HeapSys,
+		NumGC:       m.NumGC,
+	}
+}
+
+// forceGC runs a garbage collection and reports heap stats from before and
+// after it, to tell a real leak from garbage that simply hasn't been
+// collected yet
+func forceGC(logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		before := readHeapStats()
+		start := time.Now()
+		runtime.GC()
+		elapsed := time.Since(start)
+		after := readHeapStats()
+
+		logger.Info(fmt.Sprintf("Forced GC via debug endpoint in %s: heap %d -> %d bytes", elapsed, before.HeapAlloc, after.HeapAlloc))
+		c.JSON(http.StatusOK, gin.H{
+			"before":      before,
+			"after":       after,
+			"duration_ms": elapsed.Milliseconds(),
+		})
+	}
+}
+
+// listConnections reports the HTTP server's open connections by state, to
+// diagnose connection leaks and keep-alive behaviour
+func listConnections(conns *server.ConnTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, conns.Counts())
+	}
+}
+
+// listOpenConnections streams every open connection of the HTTP server, so
+// a server holding many connections doesn't build the whole list in memory
+// as JSON
+func listOpenConnections(conns *server.ConnTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		StreamJSONArray(c, http.StatusOK, slices.Values(conns.Open()))
+	}
+}
+
+// resetMetrics zeroes every counter and histogram to start a clean
+// measurement window, keeping gauges and uptime
+func resetMetrics(logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics.Reset()
+		logger.Warn("Metrics counters and histograms reset via admin endpoint")
+		c.JSON(http.StatusOK, gin.H{
+			"reset": true,
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/server"
+)
+
+func TestForceGC(t *testing.T) {
+	logger := &recordingLogger{}
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.AdminToken = "admin-secret"
+		cfg.DebugEndpoints = true
+	}, Dependencies{Logger: logger})
+
+	req := httptest.NewRequest(http.MethodPost, "/debug/gc", nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var body struct {
+		Before heapStats `json:"before"`
+		After  heapStats `json:"after"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.After.NumGC <= body.Before.NumGC {
+		t.Errorf("num_gc went from %d to %d, want a forced collection", body.Before.NumGC, body.After.NumGC)
+	}
+	if body.Before.HeapSys == 0 || body.After.HeapSys == 0 {
+		t.Errorf("heap_sys_bytes = %d -> %d, want memory stats reported", body.Before.HeapSys, body.After.HeapSys)
+	}
+	if !slices.ContainsFunc(logger.infos, func(msg string) bool { return strings.HasPrefix(msg, "Forced GC") }) {
+		t.Errorf("infos = %q, want the forced GC logged", logger.infos)
+	}
+}
+
+func TestForceGCRequiresDebugEndpoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		debug bool
+		token string
+		want  int
+	}{
+		{name: "debug disabled", debug: false, token: "admin-secret", want: http.StatusNotFound},
+		{name: "missing admin token", debug: true, token: "", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.AdminToken = "admin-secret"
+				cfg.DebugEndpoints = tt.debug
+			}, Dependencies{})
+
+			req := httptest.NewRequest(http.MethodPost, "/debug/gc", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebugConnections(t *testing.T) {
+	tracker := server.NewConnTracker()
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.AdminToken = "admin-secret"
+		cfg.DebugEndpoints = true
+	}, Dependencies{Connections: tracker})
+	ts := httptest.NewUnstartedServer(router)
+	tracker.Track(ts.Config)
+	ts.Start()
+	defer ts.Close()
+
+	// counts are read straight from the router, so the probe itself isn't
+	// one of the connections
+	counts := func() server.ConnCounts {
+		req := httptest.NewRequest(http.MethodGet, "/debug/connections", nil)
+		req.Header.Set("Authorization", "Bearer admin-secret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+		}
+		var got server.ConnCounts
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	waitFor := func(total, idle int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			got := counts()
+			if got.Total == total && got.ByState["idle"] == idle {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("counts = %+v, want %d open and %d idle", got, total, idle)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitFor(0, 0)
+	client := ts.Client()
+	resp, err := client.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	waitFor(1, 1)
+
+	client.CloseIdleConnections()
+	waitFor(0, 0)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// routeDedup installs webhook deduplication on the configured POST routes,
+// behind their auth middleware, so a remembered response is only replayed
+// to a caller that passed the route's auth
+type routeDedup struct {
+	handler   gin.HandlerFunc
+	routes    []string
+	installed map[string]bool
+}
+
+func newRouteDedup(window time.Duration, keySource string, bodyLimit int64, routes []string) (*routeDedup, error) {
+	d := &routeDedup{routes: routes, installed: make(map[string]bool)}
+	if window <= 0 {
+		return d, nil
+	}
+	key, err := middleware.ParseDedupKey(keySource, bodyLimit)
+	if err != nil {
+		return nil, err
+	}
+	d.handler = middleware.Deduplicate(window, key, dedupPrincipal)
+	return d, nil
+}
+
+// middleware returns the deduplication handler for the route, or nil when
+// it isn't deduplicated
+func (d *routeDedup) middleware(method, path string) gin.HandlerFunc {
+	if d.handler == nil || method != http.MethodPost || !slices.Contains(d.routes, path) {
+		return nil
+	}
+	d.installed[path] = true
+	return d.handler
+}
+
+// unknown returns the configured routes that matched no registered route
+func (d *routeDedup) unknown() []string {
+	if d.handler == nil {
+		return nil
+	}
+	var unknown []string
+	for _, path := range d.routes {
+		if !d.installed[path] {
+			unknown = append(unknown, path)
+		}
+	}
+	return unknown
+}
+
+// dedupPrincipal names the caller a request was authenticated as: the JWT
+// subject, or a hash of the API key so the key itself isn't kept in memory
+func dedupPrincipal(c *gin.Context) string {
+	if claims, ok := ClaimsFromContext(c); ok {
+		if sub, err := claims.GetSubject(); err == nil && sub != "" {
+			return "jwt:" + sub
+		}
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		sum := sha256.Sum256([]byte(key))
+		return "api-key:" + hex.EncodeToString(sum[:])
+	}
+	return ""
+}
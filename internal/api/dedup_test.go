@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/middleware"
+)
+
+func TestWebhookDedupRoute(t *testing.T) {
+	tests := []struct {
+		name       string
+		routes     []string
+		firstKey   string
+		secondKey  string
+		wantReplay bool
+	}{
+		{name: "duplicate is replayed", routes: []string{"/api/v1/echo"}, wantReplay: true},
+		{name: "same API key is replayed", routes: []string{"/api/v1/echo"}, firstKey: "k1", secondKey: "k1", wantReplay: true},
+		{name: "other API key runs", routes: []string{"/api/v1/echo"}, firstKey: "k1", secondKey: "k2"},
+		{name: "unlisted route runs", routes: []string{"/api/v1/other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.WebhookDedupWindow = time.Minute
+				cfg.WebhookDedupRoutes = tt.routes
+			}, Dependencies{})
+
+			var replayed bool
+			for i, key := range []string{tt.firstKey, tt.secondKey} {
+				req := httptest.NewRequest(http.MethodPost, "/api/v1/echo", strings.NewReader(`{"n":1}`))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Delivery-ID", "d1")
+				if key != "" {
+					req.Header.Set(APIKeyHeader, key)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Fatalf("delivery %d = %d, want %d: %s", i+1, w.Code, http.StatusOK, w.Body)
+				}
+				replayed = w.Header().Get(middleware.DuplicateDeliveryHeader) == "true"
+			}
+			if replayed != tt.wantReplay {
+				t.Fatalf("second delivery replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+		})
+	}
+}
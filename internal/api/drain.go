@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Drain tracks whether the instance is being taken out of rotation. While
+// draining, readiness fails, keep-alives are disabled and new requests are
+// rejected, but the server keeps running so it can be undrained without a
+// restart. The zero value is ready to use.
+type Drain struct {
+	draining atomic.Bool
+
+	// shuttingDown fails readiness ahead of a shutdown without rejecting
+	// requests, so traffic keeps being served while the load balancer
+	// deregisters the instance
+	shuttingDown atomic.Bool
+
+	mu    sync.Mutex
+	hooks []func(draining bool)
+
+	// probes counts readiness probes answered with 503 since draining began;
+	// probed is closed and replaced on every such probe to wake waiters
+	probes int
+	probed chan struct{}
+}
+
+// Draining reports whether the instance is draining
+func (d *Drain) Draining() bool {
+	return d.draining.Load()
+}
+
+// OnChange registers fn to be called whenever the drain state changes
+func (d *Drain) OnChange(fn func(draining bool)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hooks = append(d.hooks, fn)
+}
+
+// Set changes the drain state, reporting whether it changed
+func (d *Drain) Set(draining bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.draining.Swap(draining) == draining {
+		return false
+	}
+	d.probes = 0
+	for _, fn := range d.hooks {
+		fn(draining)
+	}
+	return true
+}
+
+// ShuttingDown reports whether readiness is failing for an upcoming shutdown
+func (d *Drain) ShuttingDown() bool {
+	return d.shuttingDown.Load()
+}
+
+// SetShuttingDown makes /ready fail from now on while every other request is
+// still served, and restarts the count of failed probes
+func (d *Drain) SetShuttingDown() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.shuttingDown.Swap(true) {
+		d.probes = 0
+	}
+}
+
+// probeFailed records a readiness probe that was told the instance is draining
+func (d *Drain) probeFailed() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.probes++
+	if d.probed != nil {
+		close(d.probed)
+		d.probed = nil
+	}
+}
+
+// WaitForProbes blocks until n readiness probes have been answered with 503
+// since draining or shutting down began, or until max has elapsed, and returns the number of
+// failed probes observed. Seeing the load balancer's own probes fail confirms
+// it has noticed the instance is leaving before listeners are stopped.
+func (d *Drain) WaitForProbes(n int, max time.Duration) int {
+	deadline := time.NewTimer(max)
+	defer deadline.Stop()
+
+	for {
+		d.mu.Lock()
+		seen := d.probes
+		if seen >= n {
+			d.mu.Unlock()
+			return seen
+		}
+		if d.probed == nil {
+			d.probed = make(chan struct{})
+		}
+		probed := d.probed
+		d.mu.Unlock()
+
+		select {
+		case <-probed:
+		case <-deadline.C:
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			return d.probes
+		}
+	}
+}
+
+// Reject middleware turns away new requests with 503 while draining. Health
+// probes and admin endpoints keep working so the instance can be observed and
+// undrained. Exempt paths ending in "*" match by prefix.
+func (d *Drain) Reject(exempt []string) gin.HandlerFunc {
+	skip := middleware.NewPathSet(exempt)
+
+	return func(c *gin.Context) {
+		if !d.Draining() {
+			c.Next()
+			return
+		}
+
+		c.Header("Connection", "close")
+		path := c.Request.URL.Path
+		if skip.Match(path) || strings.HasPrefix(path, "/admin/") {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": "server draining",
+		})
+	}
+}
+
+// setDrain puts the instance into or out of the drain state
+func setDrain(d *Drain, draining bool, logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d.Set(draining) {
+			if draining {
+				logger.Warn(fmt.Sprintf("Instance draining via admin endpoint (client_ip=%s)", c.ClientIP()))
+			} else {
+				logger.Info(fmt.Sprintf("Instance undrained via admin endpoint (client_ip=%s)", c.ClientIP()))
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"draining": d.Draining(),
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/health"
+	"github.com/gin-gonic/gin"
+)
+
+func TestDrainRejectExemptsHealthPaths(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/health", want: http.StatusOK},
+		{path: "/ready", want: http.StatusOK},
+		{path: "/ready/database", want: http.StatusOK},
+		{path: "/admin/undrain", want: http.StatusOK},
+		{path: "/api/v1/info", want: http.StatusServiceUnavailable},
+		{path: "/readyz", want: http.StatusServiceUnavailable},
+	}
+
+	gin.SetMode(gin.TestMode)
+	drain := &Drain{}
+	drain.Set(true)
+	router := gin.New()
+	router.Use(drain.Reject(healthPaths))
+	router.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("GET %s while draining = %d, want %d", tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrainUndrainTransition(t *testing.T) {
+	readiness, err := health.NewAggregator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(t, func(cfg *config.Config) { cfg.AdminToken = "admin" }, Dependencies{Readiness: readiness})
+	send := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer admin")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	steps := []struct {
+		action    string
+		wantReady int
+		wantAPI   int
+	}{
+		{action: "", wantReady: http.StatusOK, wantAPI: http.StatusOK},
+		{action: "/admin/drain", wantReady: http.StatusServiceUnavailable, wantAPI: http.StatusServiceUnavailable},
+		{action: "/admin/undrain", wantReady: http.StatusOK, wantAPI: http.StatusOK},
+	}
+	for _, step := range steps {
+		if step.action != "" {
+			if got := send(http.MethodPost, step.action); got != http.StatusOK {
+				t.Fatalf("POST %s = %d, want %d", step.action, got, http.StatusOK)
+			}
+		}
+		if got := send(http.MethodGet, "/ready"); got != step.wantReady {
+			t.Errorf("after %q: GET /ready = %d, want %d", step.action, got, step.wantReady)
+		}
+		if got := send(http.MethodGet, "/api/v1/info"); got != step.wantAPI {
+			t.Errorf("after %q: GET /api/v1/info = %d, want %d", step.action, got, step.wantAPI)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes used in APIError responses
+const (
+	CodeInternalError        = "INTERNAL_ERROR"
+	CodeInvalidRequest       = "INVALID_REQUEST"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRequestTooLarge      = "REQUEST_TOO_LARGE"
+	CodeIncompatibleSchema   = "INCOMPATIBLE_SCHEMA_VERSION"
+	CodeTooManyHeaders       = "TOO_MANY_HEADERS"
+)
+
+// APIError is the structured error body returned by API endpoints
+type APIError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+	// Details carries error-specific context, such as the accepted media types
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// errorPage is the minimal HTML page served to browsers on errors
+var errorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Status}} {{.StatusText}}</title></head>
+<body>
+<h1>{{.Status}} {{.StatusText}}</h1>
+<p>{{.Err.Message}}</p>
+{{if .Err.RequestID}}<p>Request ID: <code>{{.Err.RequestID}}</code></p>{{end}}
+</body>
+</html>
+`))
+
+// abortWithError writes apiErr with status, as an HTML page when the client
+// prefers text/html and as JSON otherwise, and stops the chain
+func abortWithError(c *gin.Context, status int, apiErr APIError) {
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = RequestIDFromContext(c)
+	}
+
+	if prefersHTML(c) {
+		abortWithErrorPage(c, status, apiErr)
+		return
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{"error": apiErr})
+}
+
+// prefersHTML reports whether the client prefers an HTML error page to JSON
+func prefersHTML(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+}
+
+// abortWithErrorPage writes apiErr with status as the HTML error page and
+// stops the chain
+func abortWithErrorPage(c *gin.Context, status int, apiErr APIError) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	errorPage.Execute(c.Writer, struct {
+		Status     int
+		StatusText string
+		Err        APIError
+	}{status, http.StatusText(status), apiErr})
+	c.Abort()
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Route groups that accept configured middleware stacks
+const (
+	GroupAPI   = "api"
+	GroupAdmin = "admin"
+	GroupDebug = "debug"
+)
+
+// knownGroups are the groups GROUP_MIDDLEWARE may name
+var knownGroups = map[string]bool{
+	GroupAPI:   true,
+	GroupAdmin: true,
+	GroupDebug: true,
+}
+
+// groupStacks holds the configured middleware for each route group, in the
+// order they run, ahead of the group's built-in middleware
+type groupStacks map[string][]gin.HandlerFunc
+
+// newGroupStacks resolves stacks, a mapping from group to middleware names
+// joined by "+" (e.g. admin=jwt+audit), against the named middleware
+// available for groups. Unknown groups and names are rejected.
+func newGroupStacks(stacks map[string]string, auth routeAuth, logger Logger) (groupStacks, error) {
+	available := map[string]func() (gin.HandlerFunc, error){
+		"audit":    func() (gin.HandlerFunc, error) { return Audit(logger), nil },
+		"jwt":      func() (gin.HandlerFunc, error) { return auth.middleware(AuthJWT) },
+		"api-key":  func() (gin.HandlerFunc, error) { return auth.middleware(AuthAPIKey) },
+		"no-store": func() (gin.HandlerFunc, error) { return NoStore(), nil },
+	}
+
+	result := make(groupStacks, len(stacks))
+	for group, value := range stacks {
+		if !knownGroups[group] {
+			return nil, fmt.Errorf("middleware configured for unknown route group %q (want %s, %s or %s)", group, GroupAPI, GroupAdmin, GroupDebug)
+		}
+		for _, name := range strings.Split(value, "+") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			build, ok := available[name]
+			if !ok {
+				names := make([]string, 0, len(available))
+				for n := range available {
+					names = append(names, n)
+				}
+				sort.Strings(names)
+				return nil, fmt.Errorf("unknown middleware %q for route group %q (want one of %s)", name, group, strings.Join(names, ", "))
+			}
+			handler, err := build()
+			if err != nil {
+				return nil, fmt.Errorf("middleware %q for route group %q: %w", name, group, err)
+			}
+			result[group] = append(result[group], handler)
+		}
+	}
+	return result, nil
+}
+
+// apply adds the stack configured for group to g
+func (s groupStacks) apply(group string, g *gin.RouterGroup) {
+	if stack := s[group]; len(stack) > 0 {
+		g.Use(stack...)
+	}
+}
+
+// NoStore middleware marks responses as not to be stored by any cache
+func NoStore() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/divijg19/Dahlia/internal/config"
+)
+
+func TestGroupMiddlewareAudit(t *testing.T) {
+	logger := &recordingLogger{}
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.AdminToken = "admin"
+		cfg.GroupMiddleware = map[string]string{GroupAdmin: "audit+no-store"}
+	}, Dependencies{Logger: logger})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		token       string
+		wantStatus  int
+		wantAudited bool
+	}{
+		{name: "admin", method: http.MethodPost, path: "/admin/undrain", token: "admin", wantStatus: http.StatusOK, wantAudited: true},
+		{name: "rejected admin", method: http.MethodPost, path: "/admin/undrain", wantStatus: http.StatusUnauthorized, wantAudited: true},
+		{name: "public", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK},
+		{name: "api", method: http.MethodGet, path: "/api/v1/info", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.infos = nil
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			audited := slices.ContainsFunc(logger.infos, func(msg string) bool {
+				return strings.HasPrefix(msg, "Audit: "+tt.method+" "+tt.path)
+			})
+			if audited != tt.wantAudited {
+				t.Errorf("audited = %v, want %v in %q", audited, tt.wantAudited, logger.infos)
+			}
+			if noStore := w.Header().Get("Cache-Control") == "no-store"; noStore != tt.wantAudited {
+				t.Errorf("Cache-Control = %q, want no-store only on the admin group", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestNewGroupStacksRejects(t *testing.T) {
+	for _, stacks := range []map[string]string{
+		{"internal": "audit"},
+		{GroupAdmin: "audit+compress"},
+	} {
+		if _, err := newGroupStacks(stacks, routeAuth{}, nopLogger{}); err == nil {
+			t.Errorf("newGroupStacks(%v) succeeded, want an error", stacks)
+		}
+	}
+}
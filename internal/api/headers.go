@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxHeaderCount middleware rejects requests carrying more than limit header
+// fields with 431. It complements the server's byte limit on headers, which
+// a flood of tiny headers stays under. Repeated headers count once per value.
+func MaxHeaderCount(limit int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count := 0
+		for _, values := range c.Request.Header {
+			count += len(values)
+		}
+		if count <= limit {
+			c.Next()
+			return
+		}
+		abortWithError(c, http.StatusRequestHeaderFieldsTooLarge, APIError{
+			Code:    CodeTooManyHeaders,
+			Message: fmt.Sprintf("request has %d header fields, more than the %d allowed", count, limit),
+			Details: map[string]interface{}{"max_header_count": limit},
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/divijg19/Dahlia/internal/config"
+)
+
+func TestMaxHeaderCount(t *testing.T) {
+	router := newTestRouter(t, func(cfg *config.Config) { cfg.MaxHeaderCount = 3 }, Dependencies{})
+
+	tests := []struct {
+		name    string
+		headers [][2]string
+		want    int
+	}{
+		{name: "at limit", headers: [][2]string{{"X-A", "1"}, {"X-B", "2"}, {"X-C", "3"}}, want: http.StatusOK},
+		{name: "over limit", headers: [][2]string{{"X-A", "1"}, {"X-B", "2"}, {"X-C", "3"}, {"X-D", "4"}}, want: http.StatusRequestHeaderFieldsTooLarge},
+		{name: "repeated values count", headers: [][2]string{{"X-A", "1"}, {"X-A", "2"}, {"X-A", "3"}, {"X-B", "4"}}, want: http.StatusRequestHeaderFieldsTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			for _, h := range tt.headers {
+				req.Header.Add(h[0], h[1])
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusOK {
+				return
+			}
+
+			body := errorBody(t, w)
+			if body.Code != CodeTooManyHeaders {
+				t.Errorf("code = %q, want %q", body.Code, CodeTooManyHeaders)
+			}
+			if got := fmt.Sprint(body.Details["max_header_count"]); got != "3" {
+				t.Errorf("max_header_count = %s, want 3", got)
+			}
+		})
+	}
+}
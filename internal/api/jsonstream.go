@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamFlushEvery is how many array elements are written between flushes
+const streamFlushEvery = 100
+
+// streamingKey marks a response as streamed so the size limit doesn't apply
+const streamingKey = "response_streaming"
+
+// StreamJSONArray writes items as a JSON array, encoding and flushing them
+// incrementally instead of building the whole response in memory. It is
+// intended for list endpoints that may return large datasets; the response
+// size limit does not apply to streamed responses.
+func StreamJSONArray[T any](c *gin.Context, code int, items iter.Seq[T]) error {
+	c.Set(streamingKey, true)
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(code)
+
+	if _, err := c.Writer.WriteString("["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(c.Writer)
+	n := 0
+	for item := range items {
+		if n > 0 {
+			if _, err := c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(item); err != nil {
+			return err
+		}
+		n++
+		if n%streamFlushEvery == 0 {
+			c.Writer.Flush()
+		}
+	}
+
+	_, err := c.Writer.WriteString("]")
+	return err
+}
+
+// errResponseTooLarge is returned to handlers whose response exceeds the limit
+var errResponseTooLarge = errors.New("response exceeds maximum size")
+
+// limitWriter holds back non-streamed responses until the handler returns,
+// so one growing past limit can still be replaced by an error instead of
+// being cut off mid-body. A response that is streamed or flushed is passed
+// through unlimited.
+type limitWriter struct {
+	gin.ResponseWriter
+	c           *gin.Context
+	limit       int
+	buf         bytes.Buffer
+	passthrough bool
+	exceeded    bool
+}
+
+func (w *limitWriter) Write(b []byte) (int, error) {
+	if w.exceeded {
+		return 0, errResponseTooLarge
+	}
+	if w.passthrough || w.c.GetBool(streamingKey) {
+		w.release()
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > w.limit {
+		w.exceeded = true
+		w.buf.Reset()
+		return 0, errResponseTooLarge
+	}
+	return w.buf.Write(b)
+}
+
+func (w *limitWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow only takes effect once the response is passed through;
+// until then the status is sent with the body when the handler returns
+func (w *limitWriter) WriteHeaderNow() {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *limitWriter) Written() bool {
+	return w.exceeded || w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *limitWriter) Flush() {
+	if w.exceeded {
+		return
+	}
+	w.release()
+	w.ResponseWriter.Flush()
+}
+
+// release sends what has been held back and passes later writes through
+func (w *limitWriter) release() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	w.ResponseWriter.WriteHeaderNow()
+	if w.buf.Len() > 0 {
+		w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+// finish sends the held back response, or the error if it grew too large
+func (w *limitWriter) finish() {
+	if !w.exceeded {
+		w.release()
+		return
+	}
+	if !w.ResponseWriter.Written() {
+		w.ResponseWriter.Header().Del("Content-Length")
+		w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+		w.ResponseWriter.WriteString(`{"error":"response exceeds maximum size"}`)
+	}
+}
+
+// MaxResponseSize middleware replaces non-streamed responses larger than
+// limit bytes with a 500 error so that oversized payloads are caught instead
+// of being sent. At most limit bytes are held in memory; endpoints that
+// legitimately return large datasets should use StreamJSONArray.
+func MaxResponseSize(limit int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+		w := &limitWriter{ResponseWriter: c.Writer, c: c, limit: limit}
+		c.Writer = w
+		// Restored even on a panic, so recovery answers through the real
+		// writer and the held back body is dropped
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		w.finish()
+	}
+}
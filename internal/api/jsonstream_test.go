@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "under the limit",
+			handler:    func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"ok":true}`,
+		},
+		{
+			name:       "single write over the limit",
+			handler:    func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"data": strings.Repeat("x", 100)}) },
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"response exceeds maximum size"}`,
+		},
+		{
+			name: "chunked writes crossing the limit",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "application/json")
+				c.Status(http.StatusOK)
+				c.Writer.WriteString(`[`)
+				for i := range 20 {
+					if i > 0 {
+						c.Writer.WriteString(",")
+					}
+					c.Writer.WriteString(`"element"`)
+				}
+				c.Writer.WriteString(`]`)
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":"response exceeds maximum size"}`,
+		},
+		{
+			name:       "status without a body",
+			handler:    func(c *gin.Context) { c.AbortWithStatus(http.StatusNoContent) },
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name: "streamed response is not limited",
+			handler: func(c *gin.Context) {
+				StreamJSONArray(c, http.StatusOK, count(20))
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "[0\n,1\n,2\n,3\n,4\n,5\n,6\n,7\n,8\n,9\n,10\n,11\n,12\n,13\n,14\n,15\n,16\n,17\n,18\n,19\n]",
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(MaxResponseSize(64))
+			router.GET("/list", tt.handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if w.Body.Len() > 0 && !json.Valid(w.Body.Bytes()) {
+				t.Fatalf("body %q is not valid JSON", w.Body.String())
+			}
+		})
+	}
+}
+
+func count(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range n {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestStreamJSONArrayStreamsIncrementally(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.Use(MaxResponseSize(64))
+
+	// Before the last item is produced, the earlier items must already have
+	// been flushed to the client
+	const total = 3 * streamFlushEvery
+	var flushedBeforeEnd bool
+	router.GET("/list", func(c *gin.Context) {
+		items := func(yield func(int) bool) {
+			for i := range total {
+				if i == total-1 {
+					flushedBeforeEnd = w.Flushed && strings.Contains(w.Body.String(), ","+strconv.Itoa(2*streamFlushEvery-1)+"\n")
+				}
+				if !yield(i) {
+					return
+				}
+			}
+		}
+		if err := StreamJSONArray(c, http.StatusOK, iter.Seq[int](items)); err != nil {
+			t.Error(err)
+		}
+	})
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	if !flushedBeforeEnd {
+		t.Fatal("items were buffered until the end instead of being flushed as they were encoded")
+	}
+	var got []int
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("streamed body is not a JSON array: %v", err)
+	}
+	if len(got) != total {
+		t.Fatalf("decoded %d items, want %d", len(got), total)
+	}
+}
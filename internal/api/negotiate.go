@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MIMEProtobuf is the media type for protobuf-encoded API payloads
+const MIMEProtobuf = "application/x-protobuf"
+
+// SchemaVersion is the major version of the messages in proto/dahlia/v1.
+// Clients may declare the version they were built against with a version
+// parameter on the media type, e.g. application/x-protobuf; version=1.
+// Field additions are backward compatible and don't change it; removing or
+// renumbering fields does.
+const SchemaVersion = 1
+
+// protobufEnabledKey is the context key recording whether protobuf payloads are allowed
+const protobufEnabledKey = "protobuf_enabled"
+
+var (
+	jsonMarshal = protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}
+	jsonUnmarshal = protojson.UnmarshalOptions{
+		DiscardUnknown: true,
+	}
+)
+
+// PayloadNegotiation middleware enables protobuf request and response bodies
+// for clients that ask for them. When disabled every client gets JSON.
+func PayloadNegotiation(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(protobufEnabledKey, enabled)
+		c.Next()
+	}
+}
+
+// UnsupportedMediaTypeError reports a request body in a format no
+// serializer accepts
+type UnsupportedMediaTypeError struct {
+	MediaType string
+	Supported []string
+}
+
+func (e *UnsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("unsupported media type %q (supported: %s)", e.MediaType, strings.Join(e.Supported, ", "))
+}
+
+// IncompatibleSchemaError reports a request declaring a schema version the
+// server can't decode
+type IncompatibleSchemaError struct {
+	Version   string
+	Supported int
+}
+
+func (e *IncompatibleSchemaError) Error() string {
+	return fmt.Sprintf("incompatible schema version %q (supported: %d)", e.Version, e.Supported)
+}
+
+// checkSchemaVersion validates the optional version parameter of a media
+// type. A missing parameter is treated as the current version, so clients
+// that predate versioning keep working.
+func checkSchemaVersion(value string) error {
+	_, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	v, ok := params["version"]
+	if !ok {
+		return nil
+	}
+	if n, err := strconv.Atoi(v); err != nil || n != SchemaVersion {
+		return &IncompatibleSchemaError{Version: v, Supported: SchemaVersion}
+	}
+	return nil
+}
+
+// supportedMediaTypes lists the request body formats accepted by Bind
+func supportedMediaTypes(c *gin.Context) []string {
+	if c.GetBool(protobufEnabledKey) {
+		return []string{gin.MIMEJSON, MIMEProtobuf}
+	}
+	return []string{gin.MIMEJSON}
+}
+
+// Bind decodes the request body into msg, using protobuf when the request
+// declares Content-Type: application/x-protobuf and JSON for
+// application/json or a missing Content-Type. Any other media type returns
+// an *UnsupportedMediaTypeError, and a version parameter other than
+// SchemaVersion returns an *IncompatibleSchemaError. Unknown fields from
+// newer clients are ignored. A body buffered by CacheBody is decoded from
+// the cache, leaving it readable for later readers.
+func Bind(c *gin.Context, msg proto.Message) error {
+	contentType := c.GetHeader("Content-Type")
+	mt := mediaType(contentType)
+	supported := supportedMediaTypes(c)
+	if mt != "" && !slices.Contains(supported, mt) {
+		return &UnsupportedMediaTypeError{MediaType: mt, Supported: supported}
+	}
+	if err := checkSchemaVersion(contentType); err != nil {
+		return err
+	}
+
+	body, ok := CachedBody(c)
+	if !ok {
+		var err error
+		if body, err = io.ReadAll(c.Request.Body); err != nil {
+			return fmt.Errorf("read body: %w", err)
+		}
+	}
+
+	if mt == MIMEProtobuf {
+		return proto.Unmarshal(body, msg)
+	}
+	return jsonUnmarshal.Unmarshal(body, msg)
+}
+
+// BindOrAbort binds the request body like Bind and, on failure, responds
+// with a structured error: 415 listing the supported media types for an
+// unsupported Content-Type or the supported schema version for an
+// incompatible one, 400 for a body that doesn't decode. It reports whether
+// binding succeeded.
+func BindOrAbort(c *gin.Context, msg proto.Message) bool {
+	err := Bind(c, msg)
+	if err == nil {
+		return true
+	}
+
+	var unsupported *UnsupportedMediaTypeError
+	if errors.As(err, &unsupported) {
+		abortWithError(c, http.StatusUnsupportedMediaType, APIError{
+			Code:    CodeUnsupportedMediaType,
+			Message: fmt.Sprintf("Content-Type %q is not supported", unsupported.MediaType),
+			Details: map[string]interface{}{"supported": unsupported.Supported},
+		})
+		return false
+	}
+
+	var incompatible *IncompatibleSchemaError
+	if errors.As(err, &incompatible) {
+		abortWithError(c, http.StatusUnsupportedMediaType, APIError{
+			Code:    CodeIncompatibleSchema,
+			Message: fmt.Sprintf("schema version %q is not supported; this server speaks version %d", incompatible.Version, incompatible.Supported),
+			Details: map[string]interface{}{"version": incompatible.Version, "supported": incompatible.Supported},
+		})
+		return false
+	}
+
+	abortWithError(c, http.StatusBadRequest, APIError{
+		Code:    CodeInvalidRequest,
+		Message: fmt.Sprintf("invalid request body: %v", err),
+	})
+	return false
+}
+
+// Respond writes msg with the given status, encoding it as protobuf when the
+// client accepts application/x-protobuf and as JSON otherwise. Handlers build
+// the same message type regardless of the wire format. The Content-Type
+// carries the SchemaVersion the message was encoded with.
+func Respond(c *gin.Context, code int, msg proto.Message) {
+	if c.GetBool(protobufEnabledKey) && acceptsProtobuf(c.GetHeader("Accept")) {
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			c.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+		c.Data(code, versionedMediaType(MIMEProtobuf), data)
+		return
+	}
+
+	data, err := jsonMarshal.Marshal(msg)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Data(code, versionedMediaType(gin.MIMEJSON)+"; charset=utf-8", data)
+}
+
+// versionedMediaType appends the SchemaVersion parameter to a media type
+func versionedMediaType(mt string) string {
+	return mt + "; version=" + strconv.Itoa(SchemaVersion)
+}
+
+// acceptsProtobuf reports whether an Accept header lists the protobuf media type
+func acceptsProtobuf(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		if mediaType(part) == MIMEProtobuf {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaType strips parameters from a media type and lowercases it
+func mediaType(value string) string {
+	mt, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+	return mt
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestEchoPayloadNegotiation(t *testing.T) {
+	want, err := structpb.NewStruct(map[string]interface{}{
+		"name":  "dahlia",
+		"count": 3,
+		"tags":  []interface{}{"go", "rust"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonBody, err := json.Marshal(want.AsMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	protoBody, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		accept      string
+		body        []byte
+		cacheBody   bool
+		wantStatus  int
+		wantType    string
+	}{
+		{name: "json round trip", contentType: "application/json", body: jsonBody, wantStatus: http.StatusOK, wantType: "application/json"},
+		{name: "protobuf round trip", contentType: MIMEProtobuf, accept: MIMEProtobuf, body: protoBody, wantStatus: http.StatusOK, wantType: MIMEProtobuf},
+		{name: "json in, protobuf out", contentType: "application/json", accept: MIMEProtobuf, body: jsonBody, wantStatus: http.StatusOK, wantType: MIMEProtobuf},
+		{name: "protobuf in, json out", contentType: MIMEProtobuf, body: protoBody, wantStatus: http.StatusOK, wantType: "application/json"},
+		{name: "body cached by earlier middleware", contentType: MIMEProtobuf, accept: MIMEProtobuf, body: protoBody, cacheBody: true, wantStatus: http.StatusOK, wantType: MIMEProtobuf},
+		{name: "unsupported media type", contentType: "text/xml", body: []byte("<a/>"), wantStatus: http.StatusUnsupportedMediaType},
+		{name: "incompatible schema", contentType: MIMEProtobuf + "; version=2", body: protoBody, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "malformed json", contentType: "application/json", body: []byte("{"), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.ProtobufPayloads = true
+				cfg.MaxRequestBodySize = 0
+				if tt.cacheBody {
+					cfg.MaxRequestBodySize = 1 << 20
+				}
+			}, Dependencies{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/echo", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Fatalf("Content-Type = %q, want %s", got, tt.wantType)
+			}
+
+			var got structpb.Struct
+			if tt.wantType == MIMEProtobuf {
+				err = proto.Unmarshal(w.Body.Bytes(), &got)
+			} else {
+				err = jsonUnmarshal.Unmarshal(w.Body.Bytes(), &got)
+			}
+			if err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if !proto.Equal(&got, want) {
+				t.Fatalf("echoed %v, want %v", got.AsMap(), want.AsMap())
+			}
+		})
+	}
+}
+
+// errorBody decodes a structured error response
+func errorBody(t *testing.T, w *httptest.ResponseRecorder) APIError {
+	t.Helper()
+	var body struct {
+		Error APIError `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode error body %s: %v", w.Body, err)
+	}
+	return body.Error
+}
+
+func TestUnsupportedMediaTypeListsSupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		protobuf bool
+		want     []any
+	}{
+		{name: "protobuf enabled", protobuf: true, want: []any{"application/json", MIMEProtobuf}},
+		{name: "protobuf disabled", want: []any{"application/json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.ProtobufPayloads = tt.protobuf
+			}, Dependencies{})
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/echo", strings.NewReader("<a/>"))
+			req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnsupportedMediaType {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+			}
+			apiErr := errorBody(t, w)
+			if apiErr.Code != CodeUnsupportedMediaType {
+				t.Errorf("code = %q, want %q", apiErr.Code, CodeUnsupportedMediaType)
+			}
+			if !strings.Contains(apiErr.Message, `"text/xml"`) {
+				t.Errorf("message %q doesn't name the rejected type", apiErr.Message)
+			}
+			if got := apiErr.Details["supported"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("supported = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncompatibleSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		wantStatus  int
+	}{
+		{name: "current version", contentType: "application/json; version=1", wantStatus: http.StatusOK},
+		{name: "no version", contentType: "application/json", wantStatus: http.StatusOK},
+		{name: "newer version", contentType: "application/json; version=2", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "protobuf older version", contentType: MIMEProtobuf + "; version=0", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "non-numeric version", contentType: "application/json; version=beta", wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.ProtobufPayloads = true
+	}, Dependencies{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/echo", strings.NewReader("{}"))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+			apiErr := errorBody(t, w)
+			if apiErr.Code != CodeIncompatibleSchema {
+				t.Errorf("code = %q, want %q", apiErr.Code, CodeIncompatibleSchema)
+			}
+			if got := apiErr.Details["supported"]; got != float64(SchemaVersion) {
+				t.Errorf("supported = %v, want %d", got, SchemaVersion)
+			}
+		})
+	}
+}
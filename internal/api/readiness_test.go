@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/health"
+)
+
+// flakyChecker fails while failing is set, and hangs until its context is
+// done while hanging is set
+type flakyChecker struct {
+	failing atomic.Bool
+	hanging atomic.Bool
+}
+
+func (f *flakyChecker) Name() string { return "database" }
+
+func (f *flakyChecker) Check(ctx context.Context) error {
+	if f.hanging.Load() {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if f.failing.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestReadinessTransientFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		prober    bool
+		transient func(f *flakyChecker)
+		want      int
+		// wantStreak is the failure streak the transient failure leaves
+		wantStreak int
+	}{
+		{
+			name:      "failure checked on demand with a prober",
+			prober:    true,
+			transient: func(f *flakyChecker) { f.failing.Store(true) },
+			want:      http.StatusOK,
+		},
+		{
+			name:       "failure under the failure threshold",
+			transient:  func(f *flakyChecker) { f.failing.Store(true) },
+			want:       http.StatusOK,
+			wantStreak: 1,
+		},
+		{
+			name:      "timed out check",
+			transient: func(f *flakyChecker) { f.hanging.Store(true) },
+			want:      http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &flakyChecker{}
+			agg, err := health.NewAggregator(nil, checker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			agg.SetHysteresis(1, 2)
+			agg.Run(context.Background())
+
+			deps := Dependencies{Readiness: agg}
+			if tt.prober {
+				// Never run, so /ready has no background result to serve
+				deps.Prober = health.NewProber(agg, time.Hour, time.Second)
+			}
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.ReadinessTimeout = 20 * time.Millisecond
+			}, deps)
+			ready := func() int {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+				return w.Code
+			}
+
+			tt.transient(checker)
+			if got := ready(); got != tt.want {
+				t.Fatalf("GET /ready during the transient failure = %d, want %d", got, tt.want)
+			}
+			checker.failing.Store(false)
+			checker.hanging.Store(false)
+
+			report := agg.Probe(context.Background())
+			if !report.Healthy {
+				t.Error("readiness flipped after a single transient failure")
+			}
+			if report.FailureStreak != tt.wantStreak {
+				t.Errorf("failure streak = %d, want %d", report.FailureStreak, tt.wantStreak)
+			}
+		})
+	}
+}
+
+// stuckChecker blocks until release is closed, ignoring its context
+type stuckChecker struct{ release chan struct{} }
+
+func (s *stuckChecker) Name() string { return "database" }
+
+func (s *stuckChecker) Check(context.Context) error {
+	<-s.release
+	return nil
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	checker := &stuckChecker{release: make(chan struct{})}
+	defer close(checker.release)
+	agg, err := health.NewAggregator(nil, checker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.ReadinessTimeout = 20 * time.Millisecond
+	}, Dependencies{Readiness: agg})
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	elapsed := time.Since(start)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	var body struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "not ready" || body.Error != "readiness check timed out" {
+		t.Errorf("body = %s, want the timed out response", w.Body)
+	}
+	if elapsed > time.Second {
+		t.Errorf("GET /ready took %v with a 20ms timeout", elapsed)
+	}
+}
+
+func TestComponentCheck(t *testing.T) {
+	checker := &flakyChecker{}
+	agg, err := health.NewAggregator(nil, checker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newTestRouter(t, nil, Dependencies{Readiness: agg})
+
+	tests := []struct {
+		name       string
+		path       string
+		failing    bool
+		wantStatus int
+		want       string
+	}{
+		{name: "healthy component", path: "/ready/database", wantStatus: http.StatusOK, want: "ready"},
+		{name: "failing component", path: "/ready/database", failing: true, wantStatus: http.StatusServiceUnavailable, want: "not ready"},
+		{name: "unknown component", path: "/ready/cache", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker.failing.Store(tt.failing)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			var body struct {
+				Component string `json:"component"`
+				Status    string `json:"status"`
+				Error     string `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantStatus == http.StatusNotFound {
+				if body.Error != `unknown component "cache"` {
+					t.Errorf("error = %q, want it to name the unknown component", body.Error)
+				}
+				return
+			}
+			if body.Component != "database" || body.Status != tt.want {
+				t.Errorf("body = %s, want component database %s", w.Body, tt.want)
+			}
+			if tt.failing && body.Error != "connection refused" {
+				t.Errorf("error = %q, want the check error", body.Error)
+			}
+		})
+	}
+}
+
+// readyBody is the part of the /ready response the prober tests inspect
+type readyBody struct {
+	Status    string            `json:"status"`
+	CheckedAt time.Time         `json:"checked_at"`
+	Services  map[string]string `json:"services"`
+}
+
+// startProber runs a prober for agg until the test ends and waits for its
+// first result
+func startProber(t *testing.T, agg *health.Aggregator, interval time.Duration) *health.Prober {
+	t.Helper()
+	prober := health.NewProber(agg, interval, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		prober.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, _, ok := prober.Latest(); ok {
+			return prober
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("prober never finished a run")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReadinessServesProberResult(t *testing.T) {
+	checker := &flakyChecker{}
+	agg, err := health.NewAggregator(nil, checker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prober := startProber(t, agg, time.Hour)
+	router := newTestRouter(t, nil, Dependencies{Readiness: agg, Prober: prober})
+
+	// the cached result is served without checking the dependency again
+	checker.failing.Store(true)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /ready = %d, want %d", w.Code, http.StatusOK)
+	}
+	var body readyBody
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if got := body.Services["database"]; got != "connected" {
+		t.Errorf("database = %q, want the prober's cached result", got)
+	}
+	if _, checkedAt, _ := prober.Latest(); !body.CheckedAt.Equal(checkedAt) {
+		t.Errorf("checked_at = %s, want the prober's %s", body.CheckedAt, checkedAt)
+	}
+}
+
+func TestReadinessFollowsProber(t *testing.T) {
+	checker := &flakyChecker{}
+	agg, err := health.NewAggregator(nil, checker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prober := startProber(t, agg, 5*time.Millisecond)
+	router := newTestRouter(t, nil, Dependencies{Readiness: agg, Prober: prober})
+
+	checker.failing.Store(true)
+	deadline := time.Now().Add(time.Second)
+	for {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		var body readyBody
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code == http.StatusServiceUnavailable {
+			if got := body.Services["database"]; got != "connection refused" {
+				t.Errorf("database = %q, want the failing probe's error", got)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("GET /ready = %d %s, want the failing probe reflected", w.Code, body.Status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery middleware recovers from handler panics, logs them with the
+// request ID and stack trace, and responds with 500. API clients get the
+// flat body {"error":"internal server error","request_id":"..."} rather than
+// an APIError, so the request ID is found without unwrapping; clients that
+// prefer text/html get the error page with the request ID.
+// Panics caused by the client disconnecting mid-response are logged at
+// DEBUG and not counted, since there is no one left to answer.
+func Recovery(logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				location := c.FullPath()
+				if location == "" {
+					location = "unmatched"
+				}
+				log := LoggerFromContext(c, logger)
+				if clientDisconnected(err) {
+					log.Debug(fmt.Sprintf("Client disconnected in %s: %v", location, err))
+					c.Abort()
+					return
+				}
+				metrics.PanicsTotal.WithLabelValues(location).Inc()
+				log.Error(fmt.Sprintf("Panic recovered in %s: %v\n%s", location, err, debug.Stack()))
+				if c.Writer.Written() {
+					// Too late to send an error body; just stop the chain
+					c.Abort()
+					return
+				}
+				if prefersHTML(c) {
+					abortWithErrorPage(c, http.StatusInternalServerError, APIError{
+						Code:      CodeInternalError,
+						Message:   "An internal server error occurred",
+						RequestID: RequestIDFromContext(c),
+					})
+					return
+				}
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error":      "internal server error",
+					"request_id": RequestIDFromContext(c),
+				})
+			}
+		}()
+		c.Next()
+	}
+}
+
+// clientDisconnected reports whether a recovered panic came from writing to a
+// connection the client already closed
+func clientDisconnected(recovered any) bool {
+	err, ok := recovered.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, http.ErrAbortHandler)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecovery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		accept   string
+		panicked any
+		body     string
+		counted  bool
+	}{
+		{
+			name:     "json",
+			panicked: "boom",
+			body:     `{"error":"internal server error","request_id":"req-1"}`,
+			counted:  true,
+		},
+		{
+			name:     "html",
+			accept:   "text/html",
+			panicked: "boom",
+			body:     "<code>req-1</code>",
+			counted:  true,
+		},
+		{
+			name:     "client disconnected",
+			panicked: fmt.Errorf("write: %w", syscall.EPIPE),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/panic/" + strings.ReplaceAll(tt.name, " ", "-")
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(requestIDKey, "req-1") })
+			router.Use(Recovery(nopLogger{}))
+			router.GET(path, func(c *gin.Context) { panic(tt.panicked) })
+			before := testutil.ToFloat64(metrics.PanicsTotal.WithLabelValues(path))
+
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if tt.body != "" {
+				if w.Code != http.StatusInternalServerError {
+					t.Errorf("status = %d, want 500", w.Code)
+				}
+				if got := w.Body.String(); tt.accept == "" && got != tt.body || !strings.Contains(got, tt.body) {
+					t.Errorf("body = %s, want %s", w.Body.String(), tt.body)
+				}
+			}
+			counted := testutil.ToFloat64(metrics.PanicsTotal.WithLabelValues(path)) > before
+			if counted != tt.counted {
+				t.Errorf("panic counted = %v, want %v", counted, tt.counted)
+			}
+		})
+	}
+}
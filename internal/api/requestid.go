@@ -0,0 +1,245 @@
+package api
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/divijg19/Dahlia/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the longest inbound request ID accepted verbatim
+const maxRequestIDLength = 128
+
+const (
+	requestIDKey       = "request_id"
+	clientRequestIDKey = "client_request_id"
+	requestLoggerKey   = "request_logger"
+)
+
+// fieldLogger is implemented by loggers that can derive a logger adding
+// fields to every line
+type fieldLogger interface {
+	WithFields(fields map[string]any) *logger.Logger
+}
+
+// Duplicate request ID handling modes
+const (
+	// DuplicateIDsAllow keeps reused client IDs as-is
+	DuplicateIDsAllow = "allow"
+	// DuplicateIDsSuffix appends a server-generated suffix to reused IDs
+	DuplicateIDsSuffix = "suffix"
+	// DuplicateIDsRegenerate replaces reused IDs with a new server-generated ID
+	DuplicateIDsRegenerate = "regenerate"
+)
+
+// Request ID formats
+const (
+	RequestIDUUID4     = "uuid4"
+	RequestIDUUID7     = "uuid7"
+	RequestIDULID      = "ulid"
+	RequestIDRandomHex = "random-hex"
+)
+
+// RequestIDGenerator returns the ID generator for format. UUIDv7 and ULID
+// IDs sort by creation time, which keeps them ordered in log stores.
+func RequestIDGenerator(format string) (func() string, error) {
+	switch format {
+	case "", RequestIDUUID4:
+		return newUUID4, nil
+	case RequestIDUUID7:
+		return newUUID7, nil
+	case RequestIDULID:
+		return newULID, nil
+	case RequestIDRandomHex:
+		return func() string { return randomHex(16) }, nil
+	default:
+		return nil, fmt.Errorf("unknown request ID format %q", format)
+	}
+}
+
+// RequestIDOptions configures the request ID middleware
+type RequestIDOptions struct {
+	// Generate creates new IDs; UUIDv4 is used when nil
+	Generate func() string
+	// Duplicates selects how a client ID seen again within Window is handled
+	Duplicates string
+	Window     time.Duration
+	// MaxIDs caps the client IDs remembered for duplicate detection; beyond
+	// it the least recently seen is forgotten. Zero is unlimited.
+	MaxIDs int
+}
+
+// RequestID middleware assigns every request an ID, taken from the inbound
+// X-Request-ID header when present and generated otherwise, stores it on the
+// context and echoes it in the response. Inbound IDs that are too long are
+// replaced. When a client reuses an ID within the configured window the ID is
+// disambiguated and the client's original value is kept as client_request_id.
+// When logger supports fields, a request-scoped logger tagged with the ID is
+// stored for LoggerFromContext.
+func RequestID(opts RequestIDOptions, logger Logger) gin.HandlerFunc {
+	generate := opts.Generate
+	if generate == nil {
+		generate = newUUID4
+	}
+
+	var seen *seenIDs
+	if opts.Duplicates != "" && opts.Duplicates != DuplicateIDsAllow && opts.Window > 0 {
+		seen = &seenIDs{ids: make(map[string]*list.Element), order: list.New(), window: opts.Window, max: opts.MaxIDs}
+	}
+
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = generate()
+		} else if seen != nil && seen.reused(id, time.Now()) {
+			c.Set(clientRequestIDKey, id)
+			original := id
+			if opts.Duplicates == DuplicateIDsSuffix {
+				id = fmt.Sprintf("%s-%s", original, randomHex(4))
+			} else {
+				id = generate()
+			}
+			logger.Info(fmt.Sprintf("Duplicate request ID %q reassigned request_id=%s client_request_id=%s", original, id, original))
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		if fl, ok := logger.(fieldLogger); ok {
+			c.Set(requestLoggerKey, fl.WithFields(map[string]any{"request_id": id}))
+		}
+		c.Next()
+	}
+}
+
+// RequestIDFromContext returns the ID assigned to the current request
+func RequestIDFromContext(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// LoggerFromContext returns the request-scoped logger, whose lines include
+// the request ID, or fallback when the RequestID middleware didn't set one
+func LoggerFromContext(c *gin.Context, fallback Logger) Logger {
+	if l, ok := c.Get(requestLoggerKey); ok {
+		return l.(*logger.Logger)
+	}
+	return fallback
+}
+
+// ClientRequestIDFromContext returns the client-supplied request ID when it
+// was replaced because of reuse, or an empty string otherwise
+func ClientRequestIDFromContext(c *gin.Context) string {
+	return c.GetString(clientRequestIDKey)
+}
+
+// seenIDs remembers recently used client request IDs, at most max of them,
+// ordered from most to least recently seen
+type seenIDs struct {
+	mu     sync.Mutex
+	ids    map[string]*list.Element
+	order  *list.List
+	window time.Duration
+	max    int
+}
+
+// seenID is a remembered client request ID and when it was last used
+type seenID struct {
+	id   string
+	last time.Time
+}
+
+// reused records id and reports whether it was already used within the window
+func (s *seenIDs) reused(id string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Forget IDs that fell out of the window, walking from the least
+	// recently seen until reaching one still inside it
+	for el := s.order.Back(); el != nil && now.Sub(el.Value.(*seenID).last) > s.window; el = s.order.Back() {
+		s.remove(el)
+	}
+
+	if el, ok := s.ids[id]; ok {
+		el.Value.(*seenID).last = now
+		s.order.MoveToFront(el)
+		return true
+	}
+	if s.max > 0 && len(s.ids) >= s.max {
+		s.remove(s.order.Back())
+	}
+	s.ids[id] = s.order.PushFront(&seenID{id: id, last: now})
+	return false
+}
+
+// remove forgets an ID; the caller holds the lock
+func (s *seenIDs) remove(el *list.Element) {
+	delete(s.ids, el.Value.(*seenID).id)
+	s.order.Remove(el)
+}
+
+// newUUID4 generates a random (version 4) UUID
+func newUUID4() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return formatUUID(b, 4)
+}
+
+// newUUID7 generates a time-ordered (version 7) UUID: a 48-bit millisecond
+// Unix timestamp followed by random bits
+func newUUID7() string {
+	var b [16]byte
+	rand.Read(b[6:])
+	putMillis(b[:6], time.Now())
+	return formatUUID(b, 7)
+}
+
+// formatUUID sets the version and RFC 4122 variant bits and renders b
+func formatUUID(b [16]byte, version byte) string {
+	b[6] = (b[6] & 0x0f) | version<<4
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID generates a ULID: a 48-bit millisecond timestamp and 80 random
+// bits, encoded as 26 Crockford base32 characters
+func newULID() string {
+	var b [16]byte
+	putMillis(b[:6], time.Now())
+	rand.Read(b[6:])
+
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// putMillis writes t as a 48-bit big-endian millisecond Unix timestamp
+func putMillis(dst []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		dst[i] = byte(ms)
+		ms >>= 8
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
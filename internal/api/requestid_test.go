@@ -0,0 +1,170 @@
+package api
+
+import (
+	"cmp"
+	"container/list"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDDuplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		duplicates string
+		maxIDs     int
+		ids        []string
+		// want checks the ID assigned to the last request
+		want func(id string) bool
+		// wantClient is the client_request_id recorded for the last request
+		wantClient string
+	}{
+		{
+			name:       "reused ID is suffixed",
+			duplicates: DuplicateIDsSuffix,
+			ids:        []string{"abc", "abc"},
+			want:       func(id string) bool { return strings.HasPrefix(id, "abc-") && len(id) == len("abc-")+8 },
+			wantClient: "abc",
+		},
+		{
+			name:       "reused ID is regenerated",
+			duplicates: DuplicateIDsRegenerate,
+			ids:        []string{"abc", "abc"},
+			want:       func(id string) bool { return id == "generated" },
+			wantClient: "abc",
+		},
+		{
+			name:       "reused ID is allowed",
+			duplicates: DuplicateIDsAllow,
+			ids:        []string{"abc", "abc"},
+			want:       func(id string) bool { return id == "abc" },
+		},
+		{
+			name:       "distinct IDs are kept",
+			duplicates: DuplicateIDsSuffix,
+			ids:        []string{"abc", "def"},
+			want:       func(id string) bool { return id == "def" },
+		},
+		{
+			name:       "ID forgotten beyond the cap",
+			duplicates: DuplicateIDsSuffix,
+			maxIDs:     2,
+			ids:        []string{"abc", "def", "ghi", "abc"},
+			want:       func(id string) bool { return id == "abc" },
+		},
+		{
+			name:       "recently seen ID kept within the cap",
+			duplicates: DuplicateIDsSuffix,
+			maxIDs:     2,
+			ids:        []string{"abc", "def", "abc", "ghi", "abc"},
+			want:       func(id string) bool { return strings.HasPrefix(id, "abc-") },
+			wantClient: "abc",
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id, client string
+			router := gin.New()
+			router.Use(RequestID(RequestIDOptions{
+				Generate:   func() string { return "generated" },
+				Duplicates: tt.duplicates,
+				Window:     time.Minute,
+				MaxIDs:     tt.maxIDs,
+			}, nopLogger{}))
+			router.GET("/", func(c *gin.Context) {
+				id, client = RequestIDFromContext(c), ClientRequestIDFromContext(c)
+			})
+
+			var w *httptest.ResponseRecorder
+			for _, sent := range tt.ids {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set(RequestIDHeader, sent)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+			}
+
+			if !tt.want(id) {
+				t.Errorf("request_id = %q, unexpected for %q", id, tt.ids)
+			}
+			if got := w.Header().Get(RequestIDHeader); got != id {
+				t.Errorf("%s header = %q, want %q", RequestIDHeader, got, id)
+			}
+			if client != tt.wantClient {
+				t.Errorf("client_request_id = %q, want %q", client, tt.wantClient)
+			}
+		})
+	}
+}
+
+func TestSeenIDsWindow(t *testing.T) {
+	s := &seenIDs{ids: map[string]*list.Element{}, order: list.New(), window: time.Minute}
+	start := time.Now()
+	if s.reused("abc", start) {
+		t.Fatal("first use reported as reused")
+	}
+	if !s.reused("abc", start.Add(30*time.Second)) {
+		t.Fatal("use within the window not reported as reused")
+	}
+	if s.reused("abc", start.Add(2*time.Minute)) {
+		t.Fatal("use after the window reported as reused")
+	}
+	if len(s.ids) != 1 || s.order.Len() != 1 {
+		t.Fatalf("holding %d IDs in a list of %d, want 1", len(s.ids), s.order.Len())
+	}
+}
+
+func TestRequestIDGenerator(t *testing.T) {
+	tests := []struct {
+		format string
+		shape  string
+		// sorted is set for formats whose IDs sort by creation time
+		sorted bool
+	}{
+		{format: "", shape: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{format: RequestIDUUID4, shape: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{format: RequestIDUUID7, shape: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, sorted: true},
+		{format: RequestIDULID, shape: `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`, sorted: true},
+		{format: RequestIDRandomHex, shape: `^[0-9a-f]{32}$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(cmp.Or(tt.format, "default"), func(t *testing.T) {
+			generate, err := RequestIDGenerator(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			shape := regexp.MustCompile(tt.shape)
+			seen := map[string]bool{}
+			var previous string
+			for i := range 5 {
+				if i > 0 && tt.sorted {
+					// A new millisecond, so the timestamp prefix moves on
+					time.Sleep(2 * time.Millisecond)
+				}
+				id := generate()
+				if !shape.MatchString(id) {
+					t.Fatalf("ID %q doesn't match %s", id, tt.shape)
+				}
+				if seen[id] {
+					t.Fatalf("ID %q generated twice", id)
+				}
+				seen[id] = true
+				if tt.sorted && id <= previous {
+					t.Errorf("ID %q sorts before the earlier %q", id, previous)
+				}
+				previous = id
+			}
+		})
+	}
+
+	if _, err := RequestIDGenerator("snowflake"); err == nil {
+		t.Error("RequestIDGenerator accepted an unknown format")
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Access log verbosity levels, chosen per route
+const (
+	// VerbosityNone leaves the route out of the access log
+	VerbosityNone = "none"
+	// VerbosityMinimal logs the method, path, status and duration
+	VerbosityMinimal = "minimal"
+	// VerbosityStandard adds the client IP, request ID and allowlisted
+	// headers; it is the default
+	VerbosityStandard = "standard"
+	// VerbosityVerbose adds the query string, sizes and the start of the
+	// request and response bodies
+	VerbosityVerbose = "verbose"
+)
+
+// verboseBodyLimit caps how much of each body a verbose line includes
+const verboseBodyLimit = 1024
+
+// RequestLogOptions configures the access log
+type RequestLogOptions struct {
+	// Headers are request headers whose values are logged
+	Headers []string
+	// Skip are request paths left out of the access log
+	Skip []string
+	// Verbosity sets the detail logged for Gin route templates (e.g.
+	// "/admin/reload"); other routes use VerbosityStandard
+	Verbosity map[string]string
+}
+
+// ValidateVerbosity checks that every verbosity names a known level and a
+// registered route template
+func ValidateVerbosity(verbosity map[string]string, routes gin.RoutesInfo) error {
+	known := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		known[r.Path] = true
+	}
+	for template, level := range verbosity {
+		switch level {
+		case VerbosityNone, VerbosityMinimal, VerbosityStandard, VerbosityVerbose:
+		default:
+			return fmt.Errorf("access log verbosity %q for route %q is unknown (want %s, %s, %s or %s)",
+				level, template, VerbosityNone, VerbosityMinimal, VerbosityStandard, VerbosityVerbose)
+		}
+		if !known[template] {
+			return fmt.Errorf("access log verbosity for unknown route %q", template)
+		}
+	}
+	return nil
+}
+
+// prefixBuffer keeps the first verboseBodyLimit bytes written to it and
+// counts the rest
+type prefixBuffer struct {
+	buf   bytes.Buffer
+	total int64
+}
+
+func (p *prefixBuffer) Write(b []byte) (int, error) {
+	p.total += int64(len(b))
+	if room := verboseBodyLimit - p.buf.Len(); room > 0 {
+		p.buf.Write(b[:min(room, len(b))])
+	}
+	return len(b), nil
+}
+
+// String renders the kept bytes, noting when the body was longer
+func (p *prefixBuffer) String() string {
+	s := sanitizeBody(p.buf.Bytes())
+	if p.total > int64(p.buf.Len()) {
+		s += fmt.Sprintf("...(%d bytes)", p.total)
+	}
+	return s
+}
+
+// sanitizeBody renders a body for a single log line
+func sanitizeBody(b []byte) string {
+	return strings.ToValidUTF8(string(b), "\uFFFD")
+}
+
+// teeBody copies what the handler reads from the request body
+type teeBody struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyRecorder copies what the handler writes to the response
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body *prefixBuffer
+}
+
+func (w bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w bodyRecorder) WriteString(s string) (int, error) {
+	w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// secretHeaderParts mark header names whose values must never be logged
+var secretHeaderParts = []string{"authorization", "cookie", "token", "secret", "password", "api-key", "apikey"}
+
+// isSecretHeader reports whether a header may carry credentials
+func isSecretHeader(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretHeaderParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// loggableHeaders canonicalizes the allowlisted header names, dropping any
+// that may carry credentials
+func loggableHeaders(names []string, logger Logger) []string {
+	allowed := make([]string, 0, len(names))
+	for _, name := range names {
+		if isSecretHeader(name) {
+			logger.Warn(fmt.Sprintf("Not logging request header %q: it may contain credentials", name))
+			continue
+		}
+		allowed = append(allowed, http.CanonicalHeaderKey(name))
+	}
+	return allowed
+}
+
+// RequestLogger middleware writes an access log line for every request with
+// its method, path, status, duration, client IP and request ID, at ERROR for
+// 5xx responses and INFO otherwise. Requests to the skip paths, such as
+// frequently polled probes, aren't logged. Values of the allowlisted request
+// headers are included; headers that may carry credentials are never
+// logged, even when listed. Routes can log less or more detail through
+// opts.Verbosity; verbose routes include the start of both bodies, so they
+// should not be used for routes carrying secrets.
+func RequestLogger(logger Logger, opts RequestLogOptions) gin.HandlerFunc {
+	headers := loggableHeaders(opts.Headers, logger)
+	skipped := make(map[string]bool, len(opts.Skip))
+	for _, path := range opts.Skip {
+		skipped[path] = true
+	}
+
+	return func(c *gin.Context) {
+		verbosity := opts.Verbosity[c.FullPath()]
+		if skipped[c.Request.URL.Path] || verbosity == VerbosityNone {
+			c.Next()
+			return
+		}
+
+		var reqBody, respBody *prefixBuffer
+		if verbosity == VerbosityVerbose {
+			reqBody, respBody = &prefixBuffer{}, &prefixBuffer{}
+			if c.Request.Body != nil && c.Request.Body != http.NoBody {
+				c.Request.Body = teeBody{io.TeeReader(c.Request.Body, reqBody), c.Request.Body}
+			}
+			c.Writer = bodyRecorder{ResponseWriter: c.Writer, body: respBody}
+		}
+
+		start := time.Now()
+		c.Next()
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Request method=%s path=%s status=%d duration=%s",
+			c.Request.Method, c.Request.URL.Path, c.Writer.Status(), time.Since(start))
+		if verbosity != VerbosityMinimal {
+			fmt.Fprintf(&b, " client_ip=%s request_id=%s", c.ClientIP(), RequestIDFromContext(c))
+			for _, name := range headers {
+				if value := c.Request.Header.Get(name); value != "" {
+					fmt.Fprintf(&b, " header.%s=%q", name, value)
+				}
+			}
+		}
+		if verbosity == VerbosityVerbose {
+			fmt.Fprintf(&b, " query=%q request_bytes=%d response_bytes=%d request_body=%q response_body=%q",
+				c.Request.URL.RawQuery, reqBody.total, respBody.total, reqBody.String(), respBody.String())
+		}
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			logger.Error(b.String())
+			return
+		}
+		logger.Info(b.String())
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLoggerHeaderAllowlist(t *testing.T) {
+	const secret = "s3cr3t-value"
+	logger := &recordingLogger{}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestLogger(logger, RequestLogOptions{
+		Headers: []string{"user-agent", "X-Tenant-ID", "Authorization", "Cookie", "X-Api-Key", "X-Auth-Token", "X-Client-Secret"},
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Listing a credential header is refused up front
+	if len(logger.warns) != 5 {
+		t.Errorf("warnings = %q, want one per secret header", logger.warns)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "probe/1.0")
+	req.Header.Set("X-Tenant-ID", "acme")
+	for _, name := range []string{"Authorization", "Cookie", "X-Api-Key", "X-Auth-Token", "X-Client-Secret"} {
+		req.Header.Set(name, secret)
+	}
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(logger.infos) != 1 {
+		t.Fatalf("access log lines = %q, want 1", logger.infos)
+	}
+	line := logger.infos[0]
+	for _, want := range []string{`header.User-Agent="probe/1.0"`, `header.X-Tenant-Id="acme"`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("access log %q is missing %s", line, want)
+		}
+	}
+	if strings.Contains(line, secret) {
+		t.Errorf("access log %q contains a secret header value", line)
+	}
+}
+
+func TestIsSecretHeader(t *testing.T) {
+	tests := map[string]bool{
+		"Authorization":       true,
+		"proxy-authorization": true,
+		"Set-Cookie":          true,
+		"X-CSRF-Token":        true,
+		"X-API-Key":           true,
+		"X-Apikey":            true,
+		"X-DB-Password":       true,
+		"User-Agent":          false,
+		"X-Request-ID":        false,
+		"Accept":              false,
+	}
+	for name, want := range tests {
+		if got := isSecretHeader(name); got != want {
+			t.Errorf("isSecretHeader(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRequestLoggerVerbosity(t *testing.T) {
+	logger := &recordingLogger{}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestLogger(logger, RequestLogOptions{
+		Headers: []string{"X-Tenant-ID"},
+		Verbosity: map[string]string{
+			"/minimal":     VerbosityMinimal,
+			"/verbose/:id": VerbosityVerbose,
+			"/quiet":       VerbosityNone,
+		},
+	}))
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, "echo:%s", body)
+	}
+	for _, path := range []string{"/minimal", "/standard", "/verbose/:id", "/quiet"} {
+		router.POST(path, echo)
+	}
+
+	large := strings.Repeat("x", 2*verboseBodyLimit)
+	tests := []struct {
+		name    string
+		target  string
+		body    string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "minimal",
+			target:  "/minimal?page=2",
+			body:    "hello",
+			want:    []string{"method=POST path=/minimal status=200"},
+			notWant: []string{"client_ip=", "request_id=", "header.", "query=", "request_body="},
+		},
+		{
+			name:    "standard by default",
+			target:  "/standard?page=2",
+			body:    "hello",
+			want:    []string{"path=/standard status=200", "client_ip=", "request_id=", `header.X-Tenant-Id="acme"`},
+			notWant: []string{"query=", "request_body=", "hello"},
+		},
+		{
+			name:   "verbose",
+			target: "/verbose/7?page=2",
+			body:   "hello",
+			want: []string{"path=/verbose/7 status=200", "client_ip=", `header.X-Tenant-Id="acme"`,
+				`query="page=2"`, "request_bytes=5", "response_bytes=10", `request_body="hello"`, `response_body="echo:hello"`},
+		},
+		{
+			name:   "verbose body truncated",
+			target: "/verbose/8",
+			body:   large,
+			want: []string{fmt.Sprintf("request_bytes=%d", len(large)),
+				fmt.Sprintf("request_body=%q ", fmt.Sprintf("%s...(%d bytes)", large[:verboseBodyLimit], len(large)))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger.infos = nil
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set("X-Tenant-ID", "acme")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if got := w.Body.String(); got != "echo:"+tt.body {
+				t.Errorf("handler saw body %q, want %q", got, "echo:"+tt.body)
+			}
+
+			if len(logger.infos) != 1 {
+				t.Fatalf("access log lines = %q, want 1", logger.infos)
+			}
+			line := logger.infos[0]
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Errorf("access log %q is missing %s", line, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(line, notWant) {
+					t.Errorf("access log %q contains %s", line, notWant)
+				}
+			}
+		})
+	}
+
+	logger.infos = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/quiet", strings.NewReader("hello")))
+	if len(logger.infos) != 0 {
+		t.Errorf("access log lines = %q, want none for a route at %s", logger.infos, VerbosityNone)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Route authentication levels
+const (
+	AuthPublic = "public"
+	AuthAPIKey = "api-key"
+	AuthJWT    = "jwt"
+)
+
+// APIKeyHeader carries the API key for routes at the api-key level
+const APIKeyHeader = "X-API-Key"
+
+// authInvalidKey is the failure reason for an unrecognized API key
+const authInvalidKey = "invalid_key"
+
+// Route declares an endpoint together with the authentication it requires,
+// so auth requirements are visible next to the route definitions
+type Route struct {
+	Method  string
+	Path    string
+	Auth    string
+	Handler gin.HandlerFunc
+}
+
+// APIKeyRequired middleware requires one of keys in the X-API-Key header.
+// Rejections are counted and logged like JWT failures.
+func APIKeyRequired(keys []string, logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(APIKeyHeader)
+		if provided == "" {
+			rejectAuth(c, logger, authMissing)
+			return
+		}
+		for _, key := range keys {
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+				c.Next()
+				return
+			}
+		}
+		rejectAuth(c, logger, authInvalidKey)
+	}
+}
+
+// routeAuth holds the middleware for each authentication level
+type routeAuth struct {
+	jwt    gin.HandlerFunc
+	apiKey gin.HandlerFunc
+}
+
+func newRouteAuth(cfg *config.Config, logger Logger) routeAuth {
+	a := routeAuth{jwt: AuthRequired(JWTOptionsFromConfig(cfg), logger)}
+	if len(cfg.APIKeys) > 0 {
+		a.apiKey = APIKeyRequired(cfg.APIKeys, logger)
+	}
+	return a
+}
+
+// middleware returns the handler enforcing level, or nil for public routes
+func (a routeAuth) middleware(level string) (gin.HandlerFunc, error) {
+	switch level {
+	case "", AuthPublic:
+		return nil, nil
+	case AuthJWT:
+		return a.jwt, nil
+	case AuthAPIKey:
+		if a.apiKey == nil {
+			return nil, fmt.Errorf("route requires %s auth but no API keys are configured", AuthAPIKey)
+		}
+		return a.apiKey, nil
+	default:
+		return nil, fmt.Errorf("unknown auth level %q (want %s, %s or %s)", level, AuthPublic, AuthAPIKey, AuthJWT)
+	}
+}
+
+// registerRoutes adds the enabled routes under prefix to group, each behind
+// the middleware for its auth level and then any webhook deduplication
+func registerRoutes(group gin.IRoutes, prefix string, routes []Route, auth routeAuth, dedup *routeDedup, endpoints *endpointSet) error {
+	for _, r := range routes {
+		if !endpoints.enabled(prefix + r.Path) {
+			continue
+		}
+		mw, err := auth.middleware(r.Auth)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", r.Method, prefix+r.Path, err)
+		}
+		var handlers []gin.HandlerFunc
+		if mw != nil {
+			handlers = append(handlers, mw)
+		}
+		if d := dedup.middleware(r.Method, prefix+r.Path); d != nil {
+			handlers = append(handlers, d)
+		}
+		handlers = append(handlers, r.Handler)
+		group.Handle(r.Method, r.Path, handlers...)
+	}
+	return nil
+}
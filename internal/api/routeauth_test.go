@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRouteAuthLevels(t *testing.T) {
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.JWTSecret = testSecret
+	}, Dependencies{})
+	token, err := GenerateToken(testSecret, jwt.MapClaims{"sub": "alice"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{name: "jwt route without a token", path: "/api/v1/me", want: http.StatusUnauthorized},
+		{name: "jwt route with a bad token", path: "/api/v1/me", header: "Bearer not-a-token", want: http.StatusUnauthorized},
+		{name: "jwt route with a token", path: "/api/v1/me", header: "Bearer " + token, want: http.StatusOK},
+		{name: "public route without a token", path: "/api/v1/info", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestRegisterRoutesAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	routes := []Route{
+		{Method: http.MethodGet, Path: "/keyed", Auth: AuthAPIKey, Handler: ok},
+		{Method: http.MethodGet, Path: "/open", Handler: ok},
+	}
+
+	cfg := &config.Config{APIKeys: []string{"key-1", "key-2"}}
+	router := gin.New()
+	if err := registerRoutes(router, "", routes, newRouteAuth(cfg, nopLogger{}), &routeDedup{}, newEndpointSet(nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		key  string
+		want int
+	}{
+		{path: "/keyed", want: http.StatusUnauthorized},
+		{path: "/keyed", key: "wrong", want: http.StatusUnauthorized},
+		{path: "/keyed", key: "key-2", want: http.StatusOK},
+		{path: "/open", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set(APIKeyHeader, tt.key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("GET %s with key %q = %d, want %d", tt.path, tt.key, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRegisterRoutesRejectsAuthLevel(t *testing.T) {
+	ok := func(c *gin.Context) {}
+	tests := []struct {
+		name    string
+		auth    string
+		wantErr string
+	}{
+		{name: "api-key without keys", auth: AuthAPIKey, wantErr: "no API keys are configured"},
+		{name: "unknown level", auth: "mtls", wantErr: `unknown auth level "mtls"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := []Route{{Method: http.MethodGet, Path: "/x", Auth: tt.auth, Handler: ok}}
+			err := registerRoutes(gin.New(), "/api", routes, newRouteAuth(&config.Config{}, nopLogger{}), &routeDedup{}, newEndpointSet(nil))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), "GET /api/x") {
+				t.Errorf("registerRoutes() = %v, want an error naming the route and %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,76 +1,395 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"slices"
+	"sort"
 	"time"
 
+	"github.com/divijg19/Dahlia/internal/buildinfo"
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/health"
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/divijg19/Dahlia/internal/middleware"
+	"github.com/divijg19/Dahlia/internal/pb/dahliav1"
+	"github.com/divijg19/Dahlia/internal/server"
+	"github.com/divijg19/Dahlia/internal/status"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // Logger interface for dependency injection
 type Logger interface {
 	Info(msg string)
+	Warn(msg string)
 	Error(msg string)
 	Debug(msg string)
 }
 
+// healthPaths are probe endpoints that must keep answering under load; a
+// trailing "*" matches by prefix, covering the per-component checks
+var healthPaths = []string{"/health", "/ready", "/ready/*"}
+
+// Dependencies holds the components shared by route handlers
+type Dependencies struct {
+	Logger    Logger
+	Readiness *health.Aggregator
+	Reloader  *config.Reloader
+	// Drain is the instance's drain state; a fresh one is used when nil
+	Drain *Drain
+	// RateLimiter limits requests per client; nil disables rate limiting
+	RateLimiter *middleware.RateLimiter
+	// Latency holds recent dependency latencies for the diagnostics endpoint,
+	// which is only registered when it is set
+	Latency *metrics.LatencyWindow
+	// Prober, when set, serves /ready from its latest background run
+	// instead of checking dependencies on every probe
+	Prober *health.Prober
+	// Connections tracks the HTTP server's connections for the debug
+	// connections endpoint, which is only registered when it is set
+	Connections *server.ConnTracker
+}
+
 // SetupRoutes configures all API routes
-func SetupRoutes(router *gin.Engine, logger Logger) {
+func SetupRoutes(router *gin.Engine, cfg *config.Config, deps Dependencies) error {
+	logger := deps.Logger
+	drain := deps.Drain
+	if drain == nil {
+		drain = &Drain{}
+	}
+
+	// Global middlewares are timed individually when MIDDLEWARE_TIMING_THRESHOLD
+	// is set; End closes the timed section
+	timer := middleware.NewMiddlewareTimer(cfg.MiddlewareTimingThreshold, logger)
+
+	generate, err := RequestIDGenerator(cfg.RequestIDFormat)
+	if err != nil {
+		return err
+	}
+	router.Use(timer.Wrap("request-id", RequestID(RequestIDOptions{
+		Generate:   generate,
+		Duplicates: cfg.RequestIDDuplicates,
+		Window:     cfg.RequestIDDedupWindow,
+		MaxIDs:     cfg.RequestIDDedupMaxIDs,
+	}, logger)))
+	router.Use(timer.Wrap("request-logger", RequestLogger(logger, RequestLogOptions{
+		Headers:   cfg.LogRequestHeaders,
+		Skip:      cfg.AccessLogSkipPaths,
+		Verbosity: cfg.AccessLogVerbosity,
+	})))
+	if cfg.MaxHeaderCount > 0 {
+		router.Use(timer.Wrap("max-header-count", MaxHeaderCount(cfg.MaxHeaderCount)))
+	}
+	proxies, err := ConfigureTrustedProxies(router, cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	logger.Info(proxies)
+	if cfg.LogClientIPResolution {
+		router.Use(timer.Wrap("client-ip-log", LogClientIP(router, logger)))
+	}
+	if err := middleware.ValidateCORSOrigins(cfg.CORSAllowedOrigins, cfg.Environment); err != nil {
+		return err
+	}
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		router.Use(timer.Wrap("cors", middleware.CORS(cfg.CORSAllowedOrigins, cfg.CORSAllowedMethods)))
+	}
+
+	inflight := &middleware.InFlight{}
+	router.Use(timer.Wrap("inflight", inflight.Track()))
+	router.Use(timer.Wrap("drain", drain.Reject(healthPaths)))
+	if cfg.RetryBudgetHeader && cfg.LoadShedThreshold > 0 {
+		router.Use(timer.Wrap("retry-budget", middleware.RetryBudget(inflight, int64(cfg.LoadShedThreshold))))
+	}
+	// High-priority traffic bypasses shedding and the concurrency limit, so
+	// normal traffic is the only traffic turned away under overload
+	priority := append(slices.Clone(healthPaths), cfg.PriorityPaths...)
+	router.Use(timer.Wrap("load-shed", middleware.LoadShed(inflight, int64(cfg.LoadShedThreshold), priority)))
+	router.Use(timer.Wrap("concurrency-queue", middleware.ConcurrencyQueue(cfg.MaxConcurrentRequests, cfg.RequestQueueSize, cfg.RequestQueueWait, priority)))
+	if cfg.Compression {
+		exempt, err := middleware.ParsePrefixes(cfg.CompressionExemptCIDRs)
+		if err != nil {
+			return fmt.Errorf("compression exempt CIDRs: %w", err)
+		}
+		router.Use(timer.Wrap("gzip", middleware.Gzip(exempt)))
+	}
+	if cfg.MaxRequestBodySize > 0 {
+		router.Use(timer.Wrap("cache-body", CacheBody(int64(cfg.MaxRequestBodySize))))
+	}
+	router.Use(timer.Wrap("cancellation-metrics", middleware.CancellationMetrics()))
+	router.Use(timer.Wrap("client-cert", ClientCert()))
+	if timer != nil {
+		router.Use(timer.End())
+	}
+
+	endpoints := newEndpointSet(cfg.DisabledEndpoints)
+	auth := newRouteAuth(cfg, logger)
+	stacks, err := newGroupStacks(cfg.GroupMiddleware, auth, logger)
+	if err != nil {
+		return err
+	}
+	dedup, err := newRouteDedup(cfg.WebhookDedupWindow, cfg.WebhookDedupKey, int64(cfg.MaxRequestBodySize), cfg.WebhookDedupRoutes)
+	if err != nil {
+		return fmt.Errorf("webhook dedup: %w", err)
+	}
+
 	// Health check endpoints
-	router.GET("/health", healthCheck)
-	router.GET("/ready", readinessCheck)
-	
+	if endpoints.enabled("/health") {
+		router.GET("/health", healthCheck)
+	}
+	if endpoints.enabled("/ready") {
+		router.GET("/ready", readinessCheck(deps.Readiness, deps.Prober, drain, cfg.ReadinessTimeout))
+	}
+	if endpoints.enabled("/ready/:component") {
+		router.GET("/ready/:component", componentCheck(deps.Readiness))
+	}
+
+	// Streaming routes are registered outside the v1 group so the request
+	// timeout and response cache don't apply to long-lived connections
+	if endpoints.enabled("/api/v1/status/stream") {
+		router.GET("/api/v1/status/stream", streamStatus(SSEOptions{
+			BufferSize:   cfg.SSEBufferSize,
+			WriteTimeout: cfg.SSEWriteTimeout,
+		}, logger))
+	}
+
 	// API v1 routes
+	timeouts := middleware.TimeoutPolicy{
+		Default: cfg.RequestTimeout,
+		Methods: cfg.MethodTimeouts,
+		Routes:  cfg.RouteTimeouts,
+	}
 	v1 := router.Group("/api/v1")
-	{
-		v1.GET("/status", getStatus)
-		v1.GET("/info", getInfo)
+	if deps.RateLimiter != nil {
+		v1.Use(deps.RateLimiter.Limit())
+	}
+	stacks.apply(GroupAPI, v1)
+	v1.Use(middleware.TimeoutWithPolicy(timeouts))
+	v1.Use(MaxResponseSize(cfg.MaxResponseSize))
+	v1.Use(PayloadNegotiation(cfg.ProtobufPayloads))
+	cachePolicies, err := middleware.ParseCachePolicies(cfg.CachePolicies)
+	if err != nil {
+		return err
+	}
+	if len(cachePolicies) > 0 {
+		v1.Use(middleware.ResponseCacheWithPolicies(cachePolicies))
+	} else if cfg.ResponseCacheTTL > 0 {
+		v1.Use(middleware.ResponseCache(cfg.ResponseCacheTTL))
+	}
+	v1Routes := []Route{
+		{Method: http.MethodGet, Path: "/status", Auth: AuthPublic, Handler: getStatus},
+		{Method: http.MethodGet, Path: "/info", Auth: AuthPublic, Handler: getInfo},
+		{Method: http.MethodGet, Path: "/me", Auth: AuthJWT, Handler: getMe},
+		{Method: http.MethodPost, Path: "/echo", Auth: AuthPublic, Handler: echo},
+	}
+	if deps.Latency != nil {
+		v1Routes = append(v1Routes, Route{Method: http.MethodGet, Path: "/diagnostics/latency", Auth: AuthJWT, Handler: getLatency(deps.Latency)})
+	}
+	if err := registerRoutes(v1, "/api/v1", v1Routes, auth, dedup, endpoints); err != nil {
+		return err
+	}
+
+	// Admin routes are only available when an admin token is configured
+	if cfg.AdminToken != "" {
+		admin := router.Group("/admin")
+		stacks.apply(GroupAdmin, admin)
+		admin.Use(AdminAuth(cfg.AdminToken))
+		if endpoints.enabled("/admin/reload") {
+			admin.POST("/reload", reloadConfig(deps.Reloader, logger))
+		}
+		if endpoints.enabled("/admin/drain") {
+			admin.POST("/drain", setDrain(drain, true, logger))
+			admin.POST("/undrain", setDrain(drain, false, logger))
+		}
+		// Resetting metrics destroys their history, so it is gated like the
+		// debug endpoints
+		if cfg.DebugEndpoints && endpoints.enabled("/admin/metrics/reset") {
+			admin.POST("/metrics/reset", resetMetrics(logger))
+		}
+
+		// Debug endpoints additionally require DEBUG_ENDPOINTS, which is
+		// rejected in production
+		if cfg.DebugEndpoints {
+			debugGroup := router.Group("/debug")
+			stacks.apply(GroupDebug, debugGroup)
+			debugGroup.Use(AdminAuth(cfg.AdminToken))
+			if endpoints.enabled("/debug/gc") {
+				debugGroup.POST("/gc", forceGC(logger))
+			}
+			if deps.Connections != nil && endpoints.enabled("/debug/connections") {
+				debugGroup.GET("/connections", listConnections(deps.Connections))
+			}
+			if deps.Connections != nil && endpoints.enabled("/debug/connections/open") {
+				debugGroup.GET("/connections/open", listOpenConnections(deps.Connections))
+			}
+		}
+	}
+
+	// Metrics endpoint (Prometheus format). Kept out of the v1 group, whose
+	// response cache and size limit buffer the body, so it stays streamed.
+	if endpoints.enabled("/metrics") {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	for _, path := range endpoints.unknown() {
+		logger.Warn(fmt.Sprintf("Ignoring unknown endpoint %q in disabled endpoints", path))
+	}
+	for _, path := range dedup.unknown() {
+		logger.Warn(fmt.Sprintf("Ignoring %q in webhook dedup routes, which is not a registered POST route", path))
 	}
-	
-	// Metrics endpoint (Prometheus format)
-	router.GET("/metrics", metricsHandler)
+
+	if err := timeouts.Validate(router.Routes()); err != nil {
+		return err
+	}
+	if err := ValidateVerbosity(cfg.AccessLogVerbosity, router.Routes()); err != nil {
+		return err
+	}
+	return cachePolicies.Validate(router.Routes())
 }
 
 // healthCheck returns the health status of the application
 func healthCheck(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
+		"status":    "healthy",
 		"timestamp": time.Now().UTC(),
 	})
 }
 
-// readinessCheck returns the readiness status of the application
-func readinessCheck(c *gin.Context) {
-	// Add database connectivity check, redis check, etc.
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ready",
-		"timestamp": time.Now().UTC(),
-		"services": gin.H{
-			"database": "connected", // TODO: actual check
-			"redis":    "connected", // TODO: actual check
-		},
-	})
+// componentCheck returns the status of a single registered checker
+func componentCheck(readiness *health.Aggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("component")
+		result, ok := readiness.RunOne(c.Request.Context(), name)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": fmt.Sprintf("unknown component %q", name),
+			})
+			return
+		}
+
+		status, code := "ready", http.StatusOK
+		if !result.Healthy {
+			status, code = "not ready", http.StatusServiceUnavailable
+		}
+		body := gin.H{
+			"component":   result.Name,
+			"status":      status,
+			"duration_ms": result.Duration.Milliseconds(),
+			"timestamp":   time.Now().UTC(),
+		}
+		if result.Error != "" {
+			body["error"] = result.Error
+		}
+		c.JSON(code, body)
+	}
+}
+
+// readinessCheck returns the readiness status of the application. The whole
+// probe is bounded by timeout so a stalled checker can't hang it. A draining
+// instance always reports not ready.
+func readinessCheck(readiness *health.Aggregator, prober *health.Prober, drain *Drain, timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if drain.Draining() || drain.ShuttingDown() {
+			drain.probeFailed()
+			status := "draining"
+			if !drain.Draining() {
+				status = "shutting down"
+			}
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status":        status,
+				"draining":      drain.Draining(),
+				"shutting_down": drain.ShuttingDown(),
+				"timestamp":     time.Now().UTC(),
+			})
+			return
+		}
+
+		var (
+			report    health.Report
+			checkedAt time.Time
+			cached    bool
+		)
+		if prober != nil {
+			report, checkedAt, cached = prober.Latest()
+		}
+		// Without a background result yet, check the dependencies now. With
+		// a prober only its runs move the readiness streaks; without one the
+		// run is recorded, but not when the timeout or the client leaving cut
+		// it short, which says nothing about the dependencies.
+		if !cached {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+			defer cancel()
+
+			done := make(chan health.Report, 1)
+			go func() {
+				done <- readiness.Probe(ctx)
+			}()
+
+			select {
+			case report = <-done:
+				if prober == nil && ctx.Err() == nil {
+					report = readiness.Record(report)
+				}
+				checkedAt = time.Now()
+			case <-ctx.Done():
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"status":    "not ready",
+					"error":     "readiness check timed out",
+					"timestamp": time.Now().UTC(),
+				})
+				return
+			}
+		}
+
+		services := gin.H{}
+		for name, result := range report.Results {
+			if result.Healthy {
+				services[name] = "connected"
+			} else {
+				services[name] = result.Error
+			}
+		}
+
+		status, code := "ready", http.StatusOK
+		if !report.Healthy {
+			status, code = "not ready", http.StatusServiceUnavailable
+		}
+
+		c.JSON(code, gin.H{
+			"status":        status,
+			"tier":          report.Tier,
+			"score":         report.Score,
+			"draining":      false,
+			"shutting_down": false,
+			"warmup":        report.Warmup,
+			"timestamp":     time.Now().UTC(),
+			"checked_at":    checkedAt.UTC(),
+			"services":      services,
+			"streaks": gin.H{
+				"success": report.SuccessStreak,
+				"failure": report.FailureStreak,
+			},
+		})
+	}
 }
 
 // getStatus returns basic application status
 func getStatus(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"service": "dahlia",
-		"version": "1.0.0",
-		"uptime":  time.Since(startTime).String(),
-		"status":  "running",
-	})
+	Respond(c, http.StatusOK, status.Current())
 }
 
 // getInfo returns application information
 func getInfo(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"name":        "Dahlia",
-		"description": "Modern multi-language web server template",
-		"version":     "1.0.0",
-		"languages":   []string{"Go", "Rust", "Python"},
-		"features": []string{
+	Respond(c, http.StatusOK, &dahliav1.Info{
+		Name:        "Dahlia",
+		Description: "Modern multi-language web server template",
+		Version:     buildinfo.Version,
+		Commit:      buildinfo.Commit,
+		BuildTime:   buildinfo.BuildTime,
+		Languages:   []string{"Go", "Rust", "Python"},
+		Features: []string{
 			"RESTful API",
 			"Health checks",
 			"Graceful shutdown",
@@ -80,19 +399,85 @@ func getInfo(c *gin.Context) {
 	})
 }
 
-// metricsHandler returns basic metrics in Prometheus format
-func metricsHandler(c *gin.Context) {
-	// Basic metrics - in production, use prometheus client
-	uptime := time.Since(startTime).Seconds()
-	metrics := fmt.Sprintf(`# HELP dahlia_uptime_seconds Uptime in seconds
-# TYPE dahlia_uptime_seconds counter
-dahlia_uptime_seconds %.2f
-# HELP dahlia_requests_total Total requests
-# TYPE dahlia_requests_total counter
-dahlia_requests_total{method="GET"} 0
-`, uptime)
-	c.Header("Content-Type", "text/plain")
-	c.String(http.StatusOK, metrics)
+// knownEndpoints lists every endpoint SetupRoutes can register, including
+// those that depend on configuration, so disabling one that isn't enabled in
+// this deployment isn't reported as unknown. /admin/drain also covers
+// /admin/undrain.
+var knownEndpoints = []string{
+	"/health",
+	"/ready",
+	"/ready/:component",
+	"/api/v1/status",
+	"/api/v1/status/stream",
+	"/api/v1/info",
+	"/api/v1/me",
+	"/api/v1/echo",
+	"/api/v1/diagnostics/latency",
+	"/admin/reload",
+	"/admin/drain",
+	"/admin/metrics/reset",
+	"/debug/gc",
+	"/debug/connections",
+	"/debug/connections/open",
+	"/metrics",
+}
+
+// endpointSet tracks which endpoints are disabled by configuration
+type endpointSet struct {
+	disabled map[string]bool
+}
+
+func newEndpointSet(disabled []string) *endpointSet {
+	e := &endpointSet{disabled: make(map[string]bool, len(disabled))}
+	for _, path := range disabled {
+		e.disabled[path] = true
+	}
+	return e
+}
+
+// enabled reports whether the endpoint at path should be registered
+func (e *endpointSet) enabled(path string) bool {
+	return !e.disabled[path]
+}
+
+// unknown returns disabled entries that don't name a known endpoint
+func (e *endpointSet) unknown() []string {
+	var paths []string
+	for path := range e.disabled {
+		if !slices.Contains(knownEndpoints, path) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// getMe returns the claims of the caller's token, as an example of a
+// JWT-protected route
+func getMe(c *gin.Context) {
+	claims, _ := ClaimsFromContext(c)
+	c.JSON(http.StatusOK, gin.H{
+		"claims": claims,
+	})
+}
+
+// echo returns the object in the request body, as an example of a route
+// binding a message from either JSON or protobuf and answering in the format
+// the client accepts
+func echo(c *gin.Context) {
+	var msg structpb.Struct
+	if !BindOrAbort(c, &msg) {
+		return
+	}
+	Respond(c, http.StatusOK, &msg)
 }
 
-var startTime = time.Now()
\ No newline at end of file
+// getLatency returns recent latency percentiles for each dependency
+func getLatency(window *metrics.LatencyWindow) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"dependencies": window.Summaries(),
+			"timestamp":    time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/divijg19/Dahlia/internal/server"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string)  {}
+func (nopLogger) Warn(string)  {}
+func (nopLogger) Error(string) {}
+func (nopLogger) Debug(string) {}
+
+// newTestRouter sets up the routes with the default configuration, changed
+// by configure when it is set
+func newTestRouter(t *testing.T, configure func(*config.Config), deps Dependencies) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configure != nil {
+		configure(cfg)
+	}
+	if deps.Logger == nil {
+		deps.Logger = nopLogger{}
+	}
+	router := gin.New()
+	if err := SetupRoutes(router, cfg, deps); err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestDisabledEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled []string
+		path     string
+		want     int
+	}{
+		{name: "enabled by default", path: "/api/v1/info", want: http.StatusOK},
+		{name: "disabled api endpoint", disabled: []string{"/api/v1/info"}, path: "/api/v1/info", want: http.StatusNotFound},
+		{name: "disabled metrics", disabled: []string{"/metrics"}, path: "/metrics", want: http.StatusNotFound},
+		{name: "others stay enabled", disabled: []string{"/metrics"}, path: "/health", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) { cfg.DisabledEndpoints = tt.disabled }, Dependencies{})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointSetUnknown(t *testing.T) {
+	tests := []struct {
+		name     string
+		disabled []string
+		want     []string
+	}{
+		{name: "none disabled"},
+		{name: "known endpoints", disabled: []string{"/metrics", "/api/v1/info"}},
+		{name: "feature-gated endpoints", disabled: []string{"/admin/reload", "/debug/connections", "/api/v1/diagnostics/latency"}},
+		{name: "unknown endpoints", disabled: []string{"/metrics", "/nope", "/api/v1/missing"}, want: []string{"/api/v1/missing", "/nope"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newEndpointSet(tt.disabled).unknown(); !slices.Equal(got, tt.want) {
+				t.Fatalf("unknown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestKnownEndpointsCoverEveryRoute registers every optional endpoint and
+// checks each one can be disabled by name
+func TestKnownEndpointsCoverEveryRoute(t *testing.T) {
+	router := newTestRouter(t, func(cfg *config.Config) {
+		cfg.AdminToken = "token"
+		cfg.DebugEndpoints = true
+	}, Dependencies{
+		Latency:     metrics.NewLatencyWindow(10),
+		Connections: server.NewConnTracker(),
+	})
+
+	for _, r := range router.Routes() {
+		path := strings.Replace(r.Path, "/admin/undrain", "/admin/drain", 1)
+		if !slices.Contains(knownEndpoints, path) {
+			t.Errorf("route %s %s is missing from knownEndpoints", r.Method, r.Path)
+		}
+	}
+}
+
+func TestMetricsStreamedAndAPIBuffered(t *testing.T) {
+	srv := httptest.NewServer(newTestRouter(t, nil, Dependencies{}))
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		buffered bool
+	}{
+		{path: "/metrics"},
+		{path: "/api/v1/info", buffered: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			io.Copy(io.Discard, resp.Body)
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+			hasLength := resp.ContentLength >= 0
+			if hasLength != tt.buffered {
+				t.Errorf("Content-Length %d, transfer encoding %q; want a length only for a buffered response", resp.ContentLength, resp.TransferEncoding)
+			}
+		})
+	}
+}
+
+func TestLatencyEndpoint(t *testing.T) {
+	window := metrics.NewLatencyWindow(10)
+	window.Record("database", 5*time.Millisecond)
+	token, err := GenerateToken(testSecret, jwt.MapClaims{"sub": "alice"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		latency *metrics.LatencyWindow
+		header  string
+		want    int
+	}{
+		{name: "authenticated", latency: window, header: "Bearer " + token, want: http.StatusOK},
+		{name: "no token", latency: window, want: http.StatusUnauthorized},
+		{name: "no latency window", header: "Bearer " + token, want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) { cfg.JWTSecret = testSecret }, Dependencies{Latency: tt.latency})
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/diagnostics/latency", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var body struct {
+				Dependencies map[string]metrics.LatencySummary `json:"dependencies"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			want := metrics.LatencySummary{Samples: 1, P50Ms: 5, P95Ms: 5, P99Ms: 5}
+			if got := body.Dependencies["database"]; got != want {
+				t.Errorf("database = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestPriorityPathsBypassOverload(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*config.Config)
+	}{
+		{name: "load shedding", configure: func(cfg *config.Config) { cfg.LoadShedThreshold = 1 }},
+		{name: "concurrency limit", configure: func(cfg *config.Config) {
+			cfg.MaxConcurrentRequests = 1
+			cfg.RequestQueueSize = 0
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newTestRouter(t, func(cfg *config.Config) {
+				cfg.AdminToken = "admin"
+				cfg.PriorityPaths = []string{"/admin/*", "/api/v1/status"}
+				tt.configure(cfg)
+			}, Dependencies{})
+			// /hold keeps one normal request in flight, overloading the server
+			entered := make(chan struct{})
+			release := make(chan struct{})
+			router.GET("/hold", func(c *gin.Context) {
+				close(entered)
+				<-release
+			})
+			held := make(chan struct{})
+			go func() {
+				defer close(held)
+				router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/hold", nil))
+			}()
+			<-entered
+			defer func() {
+				close(release)
+				<-held
+			}()
+
+			requests := []struct {
+				method, path string
+				want         int
+			}{
+				{method: http.MethodGet, path: "/api/v1/info", want: http.StatusServiceUnavailable},
+				{method: http.MethodGet, path: "/api/v1/status", want: http.StatusOK},
+				{method: http.MethodPost, path: "/admin/undrain", want: http.StatusOK},
+				{method: http.MethodGet, path: "/health", want: http.StatusOK},
+			}
+			for _, r := range requests {
+				req := httptest.NewRequest(r.method, r.path, nil)
+				req.Header.Set("Authorization", "Bearer admin")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != r.want {
+					t.Errorf("%s %s under overload = %d, want %d", r.method, r.path, w.Code, r.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/divijg19/Dahlia/internal/status"
+	"github.com/gin-gonic/gin"
+)
+
+// statusStreamInterval is how often /api/v1/status/stream emits an event
+const statusStreamInterval = 5 * time.Second
+
+// SSEEvent is a single server-sent event
+type SSEEvent struct {
+	Event string
+	Data  interface{}
+}
+
+// SSEOptions bounds the resources a single SSE connection may consume
+type SSEOptions struct {
+	// BufferSize is the number of events queued for a client before it is
+	// considered a slow consumer
+	BufferSize int
+	// WriteTimeout is the maximum time a single event write may take
+	WriteTimeout time.Duration
+}
+
+// Stream relays events from source to the client as server-sent events until
+// source is closed or the client goes away. Events wait in a bounded buffer;
+// a client that lets the buffer fill up or takes longer than WriteTimeout to
+// accept a write is disconnected rather than allowed to consume memory.
+func Stream(c *gin.Context, source <-chan SSEEvent, opts SSEOptions, logger Logger) {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	buffer := make(chan SSEEvent, opts.BufferSize)
+	slow := make(chan struct{})
+	go func() {
+		defer close(buffer)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-source:
+				if !ok {
+					return
+				}
+				select {
+				case buffer <- event:
+				default:
+					close(slow)
+					return
+				}
+			}
+		}
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	rc := http.NewResponseController(c.Writer)
+	for {
+		select {
+		case <-slow:
+			dropSlowConsumer(c, logger, "send buffer full")
+			return
+		case event, ok := <-buffer:
+			if !ok {
+				select {
+				case <-slow:
+					dropSlowConsumer(c, logger, "send buffer full")
+				default:
+				}
+				return
+			}
+			if opts.WriteTimeout > 0 {
+				rc.SetWriteDeadline(time.Now().Add(opts.WriteTimeout))
+			}
+			c.SSEvent(event.Event, event.Data)
+			if err := rc.Flush(); err != nil {
+				if ctx.Err() == nil {
+					dropSlowConsumer(c, logger, err.Error())
+				}
+				return
+			}
+		}
+	}
+}
+
+func dropSlowConsumer(c *gin.Context, logger Logger, reason string) {
+	metrics.SSESlowConsumersTotal.Inc()
+	logger.Warn(fmt.Sprintf("Dropping slow SSE consumer %s on %s: %s", c.ClientIP(), c.FullPath(), reason))
+}
+
+// streamStatus emits the application status as server-sent events
+func streamStatus(opts SSEOptions, logger Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events := make(chan SSEEvent)
+		ctx := c.Request.Context()
+		go func() {
+			defer close(events)
+			ticker := time.NewTicker(statusStreamInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case events <- SSEEvent{Event: "status", Data: status.Current()}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		Stream(c, events, opts, logger)
+	}
+}
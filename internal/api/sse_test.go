@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// streamServer serves /stream from source, closing done once Stream returns
+func streamServer(t *testing.T, source <-chan SSEEvent, logger Logger) (srv *httptest.Server, done chan struct{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	done = make(chan struct{})
+	router := gin.New()
+	router.GET("/stream", func(c *gin.Context) {
+		defer close(done)
+		Stream(c, source, SSEOptions{BufferSize: 1, WriteTimeout: 50 * time.Millisecond}, logger)
+	})
+	srv = httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv, done
+}
+
+func TestStreamDisconnectsSlowReader(t *testing.T) {
+	source := make(chan SSEEvent)
+	logger := &recordingLogger{}
+	srv, done := streamServer(t, source, logger)
+	before := testutil.ToFloat64(metrics.SSESlowConsumersTotal)
+
+	// Request the stream and never read the response
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /stream HTTP/1.1\r\nHost: %s\r\n\r\n", srv.Listener.Addr())
+
+	payload := strings.Repeat("x", 1<<20)
+	timeout := time.After(10 * time.Second)
+	for sending := true; sending; {
+		select {
+		case source <- SSEEvent{Event: "chunk", Data: payload}:
+		case <-done:
+			sending = false
+		case <-timeout:
+			t.Fatal("Stream kept writing to a client that never reads")
+		}
+	}
+
+	if got := testutil.ToFloat64(metrics.SSESlowConsumersTotal) - before; got != 1 {
+		t.Errorf("slow consumers dropped = %v, want 1", got)
+	}
+	if len(logger.warns) != 1 || !strings.Contains(logger.warns[0], "Dropping slow SSE consumer") {
+		t.Errorf("warnings = %q, want a slow consumer warning", logger.warns)
+	}
+}
+
+func TestStreamKeepsReadingClient(t *testing.T) {
+	source := make(chan SSEEvent)
+	srv, done := streamServer(t, source, &recordingLogger{})
+	before := testutil.ToFloat64(metrics.SSESlowConsumersTotal)
+
+	// Headers go out with the first event; later ones are sent once the
+	// previous one has been read, so the buffer never fills
+	go func() { source <- SSEEvent{Event: "tick", Data: 0} }()
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/event-stream") {
+		t.Errorf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	for i := range 3 {
+		if i > 0 {
+			source <- SSEEvent{Event: "tick", Data: i}
+		}
+		for _, want := range []string{"event:tick\n", fmt.Sprintf("data:%d\n", i), "\n"} {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line != want {
+				t.Fatalf("line = %q, want %q", line, want)
+			}
+		}
+	}
+	close(source)
+	<-done
+
+	if got := testutil.ToFloat64(metrics.SSESlowConsumersTotal) - before; got != 0 {
+		t.Errorf("slow consumers dropped = %v, want 0 for a reading client", got)
+	}
+}
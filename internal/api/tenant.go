@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/divijg19/Dahlia/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantIdentifier returns a function naming the tenant a request belongs
+// to, for per-tenant rate limiting. A valid Bearer JWT's claim is preferred;
+// otherwise the header is used, but only on requests whose connection comes
+// from one of the gateways (IPs or CIDRs) trusted to set it, so clients
+// can't pick a tenant to escape their limits. Either source is skipped when
+// its name is empty, and "" is returned when neither identifies a tenant.
+func TenantIdentifier(header, claim string, opts JWTOptions, gateways []string) (func(*gin.Context) string, error) {
+	verify := newJWTVerifier(opts)
+	gateways = slices.DeleteFunc(slices.Clone(gateways), func(g string) bool {
+		return strings.EqualFold(g, TrustedProxiesNone)
+	})
+	trusted, err := middleware.ParsePrefixes(gateways)
+	if err != nil {
+		return nil, fmt.Errorf("tenant header gateways: %w", err)
+	}
+
+	return func(c *gin.Context) string {
+		if claim != "" {
+			if raw, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok && raw != "" {
+				if claims, err := verify(raw); err == nil {
+					if tenant, ok := claims[claim].(string); ok && tenant != "" {
+						return tenant
+					}
+				}
+			}
+		}
+		if header != "" && middleware.ContainsIP(trusted, c.RemoteIP()) {
+			return c.GetHeader(header)
+		}
+		return ""
+	}, nil
+}
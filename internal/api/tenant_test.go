@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestTenantIdentifier(t *testing.T) {
+	const secret = "test-secret"
+	opts := JWTOptions{Secret: secret}
+	token, err := GenerateToken(secret, jwt.MapClaims{"tenant": "acme"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := GenerateToken("other-secret", jwt.MapClaims{"tenant": "acme"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identify, err := TenantIdentifier("X-Tenant-ID", "tenant", opts, []string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		auth   string
+		header string
+		want   string
+	}{
+		{name: "verified claim", remote: "203.0.113.7:4000", auth: "Bearer " + token, want: "acme"},
+		{name: "claim wins over header", remote: "10.1.2.3:4000", auth: "Bearer " + token, header: "globex", want: "acme"},
+		{name: "forged token ignored", remote: "203.0.113.7:4000", auth: "Bearer " + forged, want: ""},
+		{name: "header from gateway CIDR", remote: "10.1.2.3:4000", header: "globex", want: "globex"},
+		{name: "header from gateway IP", remote: "192.168.1.1:4000", header: "globex", want: "globex"},
+		{name: "header from client ignored", remote: "203.0.113.7:4000", header: "globex", want: ""},
+		{name: "nothing identifies", remote: "10.1.2.3:4000", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.RemoteAddr = tt.remote
+			if tt.auth != "" {
+				c.Request.Header.Set("Authorization", tt.auth)
+			}
+			if tt.header != "" {
+				c.Request.Header.Set("X-Tenant-ID", tt.header)
+			}
+			if got := identify(c); got != tt.want {
+				t.Errorf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantIdentifierRejectsInvalidGateways(t *testing.T) {
+	if _, err := TenantIdentifier("X-Tenant-ID", "", JWTOptions{}, []string{"not-an-ip"}); err == nil {
+		t.Error("invalid gateway accepted")
+	}
+}
@@ -0,0 +1,11 @@
+// Package buildinfo holds version metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/divijg19/Dahlia/internal/buildinfo.Version=1.2.0"
+package buildinfo
+
+// Overridden with -ldflags -X; "dev" marks an unstamped build
+var (
+	Version   = "dev"
+	Commit    = "dev"
+	BuildTime = "dev"
+)
@@ -1,44 +1,651 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// Config holds all configuration for the application
+// Config holds all configuration for the application. Fields tagged
+// secret:"true" may carry credentials and are redacted when reported.
 type Config struct {
 	Port        int    `json:"port"`
 	Host        string `json:"host"`
 	Environment string `json:"environment"`
 	LogLevel    string `json:"log_level"`
-	DatabaseURL string `json:"database_url"`
-	RedisURL    string `json:"redis_url"`
-	JWTSecret   string `json:"jwt_secret"`
+	DatabaseURL string `json:"database_url" secret:"true"`
+	RedisURL    string `json:"redis_url" secret:"true"`
+	JWTSecret   string `json:"jwt_secret" secret:"true"`
+
+	// JWTClockSkew is the leeway allowed on JWT exp, nbf and iat claims
+	JWTClockSkew time.Duration `json:"jwt_clock_skew"`
+	// JWTIssuers and JWTAudiences, when set, are the accepted iss and aud
+	// claims; unset skips the check
+	JWTIssuers   []string `json:"jwt_issuers"`
+	JWTAudiences []string `json:"jwt_audiences"`
+
+	// APIKeys are accepted in the X-API-Key header by routes at the api-key auth level
+	APIKeys []string `json:"api_keys" secret:"true"`
+
+	// AdminToken enables the /admin endpoints, authenticated as a Bearer token
+	AdminToken string `json:"admin_token" secret:"true"`
+	// DebugEndpoints enables the /debug endpoints, which also require the
+	// admin token; not allowed in production
+	DebugEndpoints bool `json:"debug_endpoints"`
+
+	// CORSAllowedOrigins lists origins allowed to make cross-origin requests;
+	// "*" allows any and is rejected in production
+	CORSAllowedOrigins []string `json:"cors_allowed_origins"`
+	// CORSAllowedMethods lists the methods advertised to preflight requests
+	CORSAllowedMethods []string `json:"cors_allowed_methods"`
+
+	// LogFormat is text or json, defaulting by Environment when empty;
+	// LogColor (auto, always or never) colors level names in text output;
+	// LogFieldKeys renames JSON fields, e.g. level=severity,message=message
+	LogFormat    string            `json:"log_format"`
+	LogColor     string            `json:"log_color"`
+	LogFieldKeys map[string]string `json:"log_field_keys"`
+	// LogFieldOrder orders the fields of JSON log lines by standard name,
+	// e.g. level,timestamp,message; "fields" places the contextual fields
+	LogFieldOrder []string `json:"log_field_order"`
+
+	// LogBufferSize enables asynchronous logging through a buffer of that many
+	// lines; LogOverflowPolicy (block or drop) decides what happens when it fills
+	LogBufferSize     int    `json:"log_buffer_size"`
+	LogOverflowPolicy string `json:"log_overflow_policy"`
+	// LogDrainTimeout bounds flushing the buffer on shutdown; lines still
+	// buffered after it are dropped
+	LogDrainTimeout time.Duration `json:"log_drain_timeout"`
+
+	// LogRateLimits caps lines per second by level, e.g. warn=100,info=1000;
+	// unlisted levels, including error, are never limited
+	LogRateLimits map[string]string `json:"log_rate_limits"`
+
+	// LogOutput is stdout or syslog; syslog uses the local daemon unless
+	// SyslogNetwork (udp or tcp) and SyslogAddress (host:port) are set
+	LogOutput string `json:"log_output"`
+	// LogSeparateErrorStream sends ERROR lines to stderr and the rest to
+	// stdout; false sends everything to stdout
+	LogSeparateErrorStream bool   `json:"log_separate_error_stream"`
+	SyslogNetwork          string `json:"syslog_network"`
+	SyslogAddress          string `json:"syslog_address"`
+	SyslogFacility         string `json:"syslog_facility"`
+
+	// LogRequestHeaders are request headers whose values are included in the
+	// access log; credential headers are never logged
+	LogRequestHeaders []string `json:"log_request_headers"`
+	// AccessLogSkipPaths are request paths left out of the access log
+	AccessLogSkipPaths []string `json:"access_log_skip_paths"`
+	// AccessLogVerbosity sets the access log detail by route template:
+	// none, minimal, standard (the default) or verbose
+	AccessLogVerbosity map[string]string `json:"access_log_verbosity"`
+
+	// IncidentLogLevel replaces LogLevel while readiness is failing, until
+	// it has passed again for IncidentLogRestoreDelay; empty disables
+	IncidentLogLevel        string        `json:"incident_log_level"`
+	IncidentLogRestoreDelay time.Duration `json:"incident_log_restore_delay"`
+
+	// LogStackLevel attaches stack traces to logs at or above this level; empty disables
+	LogStackLevel string `json:"log_stack_level"`
+
+	// ListenBacklog sets the accept queue length of the bound listener on
+	// Linux, capped by net.core.somaxconn; zero keeps the OS default
+	ListenBacklog int `json:"listen_backlog"`
+
+	// GRPCPort serves the gRPC API on a second listener; zero disables it
+	GRPCPort int `json:"grpc_port"`
+
+	// TrustedProxies may set the client IP through X-Forwarded-For or
+	// X-Real-IP; empty or "none" trusts no proxy.
+	// LogClientIPResolution logs each request's resolution at DEBUG.
+	TrustedProxies        []string `json:"trusted_proxies"`
+	LogClientIPResolution bool     `json:"log_client_ip_resolution"`
+
+	// TLS serving; a client CA bundle additionally enables mutual TLS
+	TLSCertFile    string `json:"tls_cert_file"`
+	TLSKeyFile     string `json:"tls_key_file"`
+	ClientCAFile   string `json:"client_ca_file"`
+	ClientAuthMode string `json:"client_auth_mode"`
+
+	// MaxConcurrentStreams bounds the streams a single HTTP/2 connection may
+	// have open at once
+	MaxConcurrentStreams int `json:"max_concurrent_streams"`
+
+	// SSE connections are dropped when SSEBufferSize events are pending or a
+	// write takes longer than SSEWriteTimeout
+	SSEBufferSize   int           `json:"sse_buffer_size"`
+	SSEWriteTimeout time.Duration `json:"sse_write_timeout"`
+
+	// Compression gzips responses for clients that accept it, except those
+	// connecting from CompressionExemptCIDRs (e.g. the cluster network)
+	Compression            bool     `json:"compression"`
+	CompressionExemptCIDRs []string `json:"compression_exempt_cidrs"`
+
+	// MaxResponseSize caps non-streamed API response bodies in bytes; zero disables
+	MaxResponseSize int `json:"max_response_size"`
+
+	// MaxHeaderCount caps the number of request header fields; zero disables
+	MaxHeaderCount int `json:"max_header_count"`
+
+	// MaxRequestBodySize caps request bodies in bytes, which are buffered so
+	// they can be read more than once; zero disables both
+	MaxRequestBodySize int `json:"max_request_body_size"`
+	// MaxMultipartMemory is how many bytes of a multipart form are held in
+	// memory; file parts beyond it are spooled to temporary files. The whole
+	// body is still bounded by MaxRequestBodySize.
+	MaxMultipartMemory int `json:"max_multipart_memory"`
+
+	// RequestIDFormat is the generated request ID format: uuid4, uuid7, ulid
+	// or random-hex
+	RequestIDFormat string `json:"request_id_format"`
+
+	// RequestIDDuplicates handles client request IDs reused within
+	// RequestIDDedupWindow: allow, suffix or regenerate. At most
+	// RequestIDDedupMaxIDs IDs are remembered; 0 is unlimited.
+	RequestIDDuplicates  string        `json:"request_id_duplicates"`
+	RequestIDDedupWindow time.Duration `json:"request_id_dedup_window"`
+	RequestIDDedupMaxIDs int           `json:"request_id_dedup_max_ids"`
+
+	// ProtobufPayloads lets API clients exchange application/x-protobuf bodies
+	ProtobufPayloads bool `json:"protobuf_payloads"`
+
+	// ResponseCacheTTL enables response caching for API reads when non-zero
+	ResponseCacheTTL time.Duration `json:"response_cache_ttl"`
+	// CachePolicies, when set, replaces ResponseCacheTTL with per-route
+	// policies keyed by route template, e.g.
+	// /api/v1/info=30s;vary=Accept;status=200|404. Routes without one are
+	// not cached.
+	CachePolicies map[string]string `json:"cache_policies"`
+
+	// GroupMiddleware adds named middleware to route groups (api, admin,
+	// debug), joined by "+" in the order they run, e.g. admin=audit+no-store
+	GroupMiddleware map[string]string `json:"group_middleware"`
+
+	// WebhookDedupWindow enables deduplication of POST deliveries to the
+	// WebhookDedupRoutes templates when non-zero; WebhookDedupKey is "body"
+	// or "header:<name>"
+	WebhookDedupWindow time.Duration `json:"webhook_dedup_window"`
+	WebhookDedupKey    string        `json:"webhook_dedup_key"`
+	WebhookDedupRoutes []string      `json:"webhook_dedup_routes"`
+
+	// RequestTimeout caps API handlers; MethodTimeouts overrides it by HTTP
+	// method and RouteTimeouts, taking precedence, by route template
+	RequestTimeout time.Duration            `json:"request_timeout"`
+	MethodTimeouts map[string]time.Duration `json:"method_timeouts"`
+	RouteTimeouts  map[string]time.Duration `json:"route_timeouts"`
+
+	// RouteAliases maps old paths to "<mode>:<new path>", where mode is
+	// rewrite, 301 or 308, so renamed endpoints keep answering
+	RouteAliases map[string]string `json:"route_aliases"`
+
+	// LoadShedThreshold is the in-flight request count above which new requests
+	// are rejected with 503; zero disables load shedding
+	LoadShedThreshold int `json:"load_shed_threshold"`
+	// PriorityPaths are high-priority paths that, like the health checks,
+	// bypass load shedding and the concurrency limit; entries ending in "*"
+	// match by prefix
+	PriorityPaths []string `json:"priority_paths"`
+
+	// RateLimitRPS limits each client IP to this many requests per second with
+	// bursts of RateLimitBurst; zero disables. Buckets idle for
+	// RateLimitBucketTTL are evicted, and beyond RateLimitMaxBuckets the least
+	// recently used bucket is.
+	RateLimitRPS        float64       `json:"rate_limit_rps"`
+	RateLimitBurst      int           `json:"rate_limit_burst"`
+	RateLimitBucketTTL  time.Duration `json:"rate_limit_bucket_ttl"`
+	RateLimitMaxBuckets int           `json:"rate_limit_max_buckets"`
+
+	// TenantRateLimitRPS gives each tenant, identified by the TenantClaim JWT
+	// claim or the TenantHeader header, its own bucket of this many requests
+	// per second and bursts of TenantRateLimitBurst; zero disables.
+	// TenantRateLimits overrides the rate per tenant, e.g. acme=50. The
+	// header is only read from TrustedProxies. Requests without a tenant
+	// fall back to the per-IP limit.
+	TenantRateLimitRPS   float64           `json:"tenant_rate_limit_rps"`
+	TenantRateLimitBurst int               `json:"tenant_rate_limit_burst"`
+	TenantRateLimits     map[string]string `json:"tenant_rate_limits"`
+	TenantHeader         string            `json:"tenant_header"`
+	TenantClaim          string            `json:"tenant_claim"`
+
+	// RetryBudgetHeader advertises remaining capacity relative to
+	// LoadShedThreshold in an X-Retry-Budget response header
+	RetryBudgetHeader bool `json:"retry_budget_header"`
+
+	// MaxConcurrentRequests limits concurrently running requests; excess
+	// requests wait in a queue of RequestQueueSize for up to RequestQueueWait
+	MaxConcurrentRequests int           `json:"max_concurrent_requests"`
+	RequestQueueSize      int           `json:"request_queue_size"`
+	RequestQueueWait      time.Duration `json:"request_queue_wait"`
+
+	// On shutdown the instance first reports not ready and waits for
+	// ShutdownReadinessProbes failed /ready probes, for at most
+	// ShutdownReadinessDelay, so the load balancer deregisters it before
+	// listeners stop. With no probe count it simply waits the delay.
+	ShutdownReadinessProbes int           `json:"shutdown_readiness_probes"`
+	ShutdownReadinessDelay  time.Duration `json:"shutdown_readiness_delay"`
+	// ShutdownTimeout bounds the shutdown hooks, including draining
+	// in-flight requests, once deregistration is done
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	// ConnDrainTimeout bounds waiting for HTTP connections to close on
+	// shutdown before the rest are closed forcibly; zero waits until the
+	// ShutdownTimeout deadline
+	ConnDrainTimeout time.Duration `json:"conn_drain_timeout"`
+	// MaxProcessLifetime, when non-zero, shuts the server down gracefully
+	// once it has run this long so an orchestrator restarts it, up to
+	// MaxProcessLifetimeJitter (a fraction, 0-1) earlier at random so
+	// replicas don't all restart together
+	MaxProcessLifetime       time.Duration `json:"max_process_lifetime"`
+	MaxProcessLifetimeJitter float64       `json:"max_process_lifetime_jitter"`
+
+	// SlowShutdownHookThreshold is the shutdown hook duration that triggers a warning
+	SlowShutdownHookThreshold time.Duration `json:"slow_shutdown_hook_threshold"`
+	// MiddlewareTimingThreshold flags global middlewares whose own time on a
+	// request exceeds it; zero disables middleware timing
+	MiddlewareTimingThreshold time.Duration `json:"middleware_timing_threshold"`
+
+	// Metric names are prefixed with MetricsNamespace and optional MetricsSubsystem
+	MetricsNamespace string `json:"metrics_namespace"`
+	MetricsSubsystem string `json:"metrics_subsystem"`
+
+	// MetricsPushURL enables pushing metrics to a Pushgateway every
+	// MetricsPushInterval. On shutdown a final snapshot is pushed and, with
+	// MetricsPushDeleteOnShutdown, the instance's metrics are then deleted.
+	MetricsPushURL              string        `json:"metrics_push_url" secret:"true"`
+	MetricsPushJob              string        `json:"metrics_push_job"`
+	MetricsPushInterval         time.Duration `json:"metrics_push_interval"`
+	MetricsPushDeleteOnShutdown bool          `json:"metrics_push_delete_on_shutdown"`
+
+	// SchedulerProbeInterval is how often scheduler lag is measured (zero
+	// disables); lag above SchedulerLagThreshold is logged at WARN
+	SchedulerProbeInterval time.Duration `json:"scheduler_probe_interval"`
+	SchedulerLagThreshold  time.Duration `json:"scheduler_lag_threshold"`
+
+	// GoroutineSampleInterval is how often the goroutine count is sampled
+	// (zero disables); counts above GoroutineThreshold are logged at WARN
+	GoroutineSampleInterval time.Duration `json:"goroutine_sample_interval"`
+	GoroutineThreshold      int           `json:"goroutine_threshold"`
+
+	// LatencyBuckets are the request duration histogram buckets in seconds;
+	// empty uses the defaults
+	LatencyBuckets []float64 `json:"latency_buckets"`
+
+	// TraceExporter selects the span exporter (stdout or otlp); empty disables tracing
+	TraceExporter string `json:"trace_exporter"`
+	// TraceSampleRate is the fraction of requests traced (0.0-1.0)
+	TraceSampleRate float64 `json:"trace_sample_rate"`
+	// TraceShutdownTimeout bounds flushing queued spans on shutdown
+	TraceShutdownTimeout time.Duration `json:"trace_shutdown_timeout"`
+
+	// UpstreamURLs maps upstream service names (e.g. rust, python) to base
+	// URLs. Calls are bounded by UpstreamTimeouts for that name, falling back
+	// to UpstreamTimeout, and by the deadline of the request making them.
+	UpstreamURLs            map[string]string        `json:"upstream_urls" secret:"true"`
+	UpstreamTimeouts        map[string]time.Duration `json:"upstream_timeouts"`
+	UpstreamTimeout         time.Duration            `json:"upstream_timeout"`
+	UpstreamMaxIdleConns    int                      `json:"upstream_max_idle_conns"`
+	UpstreamMaxConns        int                      `json:"upstream_max_conns"`
+	UpstreamIdleConnTimeout time.Duration            `json:"upstream_idle_conn_timeout"`
+
+	// MetricsAggregationInterval controls how often derived metrics are computed
+	MetricsAggregationInterval time.Duration `json:"metrics_aggregation_interval"`
+	// LatencyWindowSize is how many recent samples per dependency back the
+	// /api/v1/diagnostics/latency percentiles
+	LatencyWindowSize int `json:"latency_window_size"`
+
+	// DisabledEndpoints lists endpoint paths that are not registered
+	DisabledEndpoints []string `json:"disabled_endpoints"`
+
+	// ReadinessChecks lists the checks that gate /ready; empty means all critical checks
+	ReadinessChecks []string `json:"readiness_checks"`
+	// HealthCheckNonCritical lists checks that are not critical, so they only
+	// gate /ready when ReadinessChecks names them
+	HealthCheckNonCritical []string `json:"health_check_non_critical"`
+	// Minimum weighted health scores (0-100) for the healthy and degraded tiers
+	HealthyScore  float64 `json:"healthy_score"`
+	DegradedScore float64 `json:"degraded_score"`
+	// HealthCheckWeights sets how much each check counts towards the score,
+	// by check name (e.g. database=3); unlisted checks weigh 1
+	HealthCheckWeights map[string]string `json:"health_check_weights"`
+	// ReadinessTimeout bounds the whole /ready handler, independent of per-check timeouts
+	ReadinessTimeout time.Duration `json:"readiness_timeout"`
+	// HealthCheckTimeout bounds each individual dependency check
+	HealthCheckTimeout time.Duration `json:"health_check_timeout"`
+	// ReadinessProbeInterval, when non-zero, checks dependencies in the
+	// background this often, each run bounded by ReadinessTimeout, and
+	// answers /ready from the latest result
+	ReadinessProbeInterval time.Duration `json:"readiness_probe_interval"`
+
+	// HealthCommand registers a "command" readiness check that runs this
+	// program (no shell) and passes on exit code 0
+	HealthCommand        string        `json:"health_command"`
+	HealthCommandTimeout time.Duration `json:"health_command_timeout"`
+	// Consecutive passing or failing readiness runs required before /ready
+	// flips state, damping flapping dependencies
+	ReadinessSuccessThreshold int `json:"readiness_success_threshold"`
+	ReadinessFailureThreshold int `json:"readiness_failure_threshold"`
+	// ReadinessWarmup is a grace period after startup during which failing
+	// checks don't make a ready instance report not ready; zero disables
+	ReadinessWarmup time.Duration `json:"readiness_warmup"`
+	// HealthCheckConcurrency caps how many checks run at once; 0 is unlimited
+	HealthCheckConcurrency int `json:"health_check_concurrency"`
+	// ReadinessWebhookURL receives a POST when readiness flips and stays
+	// flipped for ReadinessWebhookDebounce; empty disables it
+	ReadinessWebhookURL      string        `json:"readiness_webhook_url" secret:"true"`
+	ReadinessWebhookDebounce time.Duration `json:"readiness_webhook_debounce"`
+
+	// ConfigFile is the YAML file named by CONFIG_FILE, if any, and
+	// UnknownFileKeys lists its keys that no setting reads
+	ConfigFile      string   `json:"config_file"`
+	UnknownFileKeys []string `json:"-"`
 }
 
-// Load returns configuration from environment variables with defaults
-func Load() *Config {
+// DefaultJWTSecret is the placeholder JWT secret used when JWT_SECRET is
+// unset; Validate rejects it in production
+const DefaultJWTSecret = "your-secret-key-change-in-production"
+
+// Load returns configuration from environment variables with defaults. When
+// CONFIG_FILE names a YAML file its values sit between the two: environment
+// variables override the file, which overrides the defaults. It fails when
+// PORT is set but isn't a number or the file can't be read; call Validate to
+// check the loaded values.
+func Load() (*Config, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return LoadFromFile(path)
+	}
+	return load(&source{})
+}
+
+// load builds the configuration from src
+func load(src *source) (*Config, error) {
 	port := 8080
-	if p := os.Getenv("PORT"); p != "" {
-		if parsed, err := strconv.Atoi(p); err == nil {
-			port = parsed
+	if p := src.lookup("PORT"); p != "" {
+		parsed, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fieldError("PORT", fmt.Errorf("invalid PORT %q: %w", p, err))
 		}
+		port = parsed
+	}
+
+	environment := src.getEnv("ENV", "development")
+
+	// Trace everything while developing but only a sample in production
+	sampleRate := 1.0
+	if environment == "production" {
+		sampleRate = 0.05
+	}
+
+	// Any origin may call a development server; elsewhere origins must be listed
+	var corsOrigins []string
+	if environment == "development" {
+		corsOrigins = []string{"*"}
 	}
 
 	return &Config{
 		Port:        port,
-		Host:        getEnv("HOST", "0.0.0.0"),
-		Environment: getEnv("ENV", "development"),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
-		DatabaseURL: getEnv("DATABASE_URL", "postgres://localhost/dahlia?sslmode=disable"),
-		RedisURL:    getEnv("REDIS_URL", "redis://localhost:6379/0"),
-		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-	}
+		Host:        src.getEnv("HOST", "0.0.0.0"),
+		Environment: environment,
+		LogLevel:    src.getEnv("LOG_LEVEL", "info"),
+		DatabaseURL: src.getEnv("DATABASE_URL", "postgres://localhost/dahlia?sslmode=disable"),
+		RedisURL:    src.getEnv("REDIS_URL", "redis://localhost:6379/0"),
+		JWTSecret:   src.getEnv("JWT_SECRET", DefaultJWTSecret),
+
+		JWTClockSkew: src.getEnvDuration("JWT_CLOCK_SKEW", 30*time.Second),
+		JWTIssuers:   src.getEnvList("JWT_ISSUERS", nil),
+		JWTAudiences: src.getEnvList("JWT_AUDIENCES", nil),
+
+		APIKeys: src.getEnvList("API_KEYS", nil),
+
+		AdminToken:     src.getEnv("ADMIN_TOKEN", ""),
+		DebugEndpoints: src.getEnvBool("DEBUG_ENDPOINTS", false),
+
+		CORSAllowedOrigins: src.getEnvList("CORS_ALLOWED_ORIGINS", corsOrigins),
+		CORSAllowedMethods: src.getEnvList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
+
+		LogFormat:     src.getEnv("LOG_FORMAT", ""),
+		LogColor:      src.getEnv("LOG_COLOR", "auto"),
+		LogFieldKeys:  src.getEnvMap("LOG_FIELD_KEYS"),
+		LogFieldOrder: src.getEnvList("LOG_FIELD_ORDER", nil),
+		LogStackLevel: src.getEnv("LOG_STACK_LEVEL", ""),
+
+		IncidentLogLevel:        src.getEnv("INCIDENT_LOG_LEVEL", ""),
+		IncidentLogRestoreDelay: src.getEnvDuration("INCIDENT_LOG_RESTORE_DELAY", time.Minute),
+
+		LogRequestHeaders:  src.getEnvList("LOG_REQUEST_HEADERS", nil),
+		AccessLogSkipPaths: src.getEnvList("ACCESS_LOG_SKIP_PATHS", []string{"/health", "/metrics"}),
+		AccessLogVerbosity: src.getEnvMap("ACCESS_LOG_VERBOSITY"),
+
+		LogOutput:              src.getEnv("LOG_OUTPUT", "stdout"),
+		LogSeparateErrorStream: src.getEnvBool("LOG_SEPARATE_ERROR_STREAM", true),
+		SyslogNetwork:          src.getEnv("SYSLOG_NETWORK", ""),
+		SyslogAddress:          src.getEnv("SYSLOG_ADDRESS", ""),
+		SyslogFacility:         src.getEnv("SYSLOG_FACILITY", "daemon"),
+
+		LogBufferSize:     src.getEnvInt("LOG_BUFFER_SIZE", 0),
+		LogOverflowPolicy: src.getEnv("LOG_OVERFLOW_POLICY", "block"),
+		LogDrainTimeout:   src.getEnvDuration("LOG_DRAIN_TIMEOUT", 2*time.Second),
+
+		LogRateLimits: src.getEnvMap("LOG_RATE_LIMITS"),
+
+		ListenBacklog: src.getEnvInt("LISTEN_BACKLOG", 0),
+
+		GRPCPort: src.getEnvInt("GRPC_PORT", 0),
+
+		TrustedProxies:        src.getEnvList("TRUSTED_PROXIES", nil),
+		LogClientIPResolution: src.getEnvBool("LOG_CLIENT_IP_RESOLUTION", false),
+
+		TLSCertFile:    src.getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:     src.getEnv("TLS_KEY_FILE", ""),
+		ClientCAFile:   src.getEnv("CLIENT_CA_FILE", ""),
+		ClientAuthMode: src.getEnv("CLIENT_AUTH_MODE", "require"),
+
+		MaxConcurrentStreams: src.getEnvInt("HTTP2_MAX_CONCURRENT_STREAMS", 100),
+
+		SSEBufferSize:   src.getEnvInt("SSE_BUFFER_SIZE", 16),
+		SSEWriteTimeout: src.getEnvDuration("SSE_WRITE_TIMEOUT", 5*time.Second),
+
+		Compression:            src.getEnvBool("COMPRESSION", false),
+		CompressionExemptCIDRs: src.getEnvList("COMPRESSION_EXEMPT_CIDRS", nil),
+
+		MaxResponseSize: src.getEnvInt("MAX_RESPONSE_SIZE", 10<<20),
+
+		MaxRequestBodySize: src.getEnvInt("MAX_REQUEST_BODY_SIZE", 10<<20),
+		MaxHeaderCount:     src.getEnvInt("MAX_HEADER_COUNT", 100),
+		MaxMultipartMemory: src.getEnvInt("MAX_MULTIPART_MEMORY", 8<<20),
+
+		RequestIDFormat:      src.getEnv("REQUEST_ID_FORMAT", "uuid4"),
+		RequestIDDuplicates:  src.getEnv("REQUEST_ID_DUPLICATES", "allow"),
+		RequestIDDedupWindow: src.getEnvDuration("REQUEST_ID_DEDUP_WINDOW", time.Minute),
+		RequestIDDedupMaxIDs: src.getEnvInt("REQUEST_ID_DEDUP_MAX_IDS", 100000),
+
+		ProtobufPayloads: src.getEnvBool("PROTOBUF_PAYLOADS", true),
+
+		ResponseCacheTTL:          src.getEnvDuration("RESPONSE_CACHE_TTL", 0),
+		CachePolicies:             src.getEnvMap("CACHE_POLICIES"),
+		GroupMiddleware:           src.getEnvMap("GROUP_MIDDLEWARE"),
+		WebhookDedupWindow:        src.getEnvDuration("WEBHOOK_DEDUP_WINDOW", 0),
+		WebhookDedupKey:           src.getEnv("WEBHOOK_DEDUP_KEY", "header:X-Delivery-ID"),
+		WebhookDedupRoutes:        src.getEnvList("WEBHOOK_DEDUP_ROUTES", nil),
+		ReadinessChecks:           src.getEnvList("READINESS_CHECKS", nil),
+		HealthCheckNonCritical:    src.getEnvList("HEALTH_CHECK_NON_CRITICAL", nil),
+		ReadinessTimeout:          src.getEnvDuration("READINESS_TIMEOUT", 5*time.Second),
+		HealthCheckTimeout:        src.getEnvDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
+		ReadinessProbeInterval:    src.getEnvDuration("READINESS_PROBE_INTERVAL", 0),
+		HealthCommand:             src.getEnv("HEALTH_COMMAND", ""),
+		HealthCommandTimeout:      src.getEnvDuration("HEALTH_COMMAND_TIMEOUT", 2*time.Second),
+		HealthCheckConcurrency:    src.getEnvInt("HEALTH_CHECK_CONCURRENCY", 0),
+		HealthyScore:              src.getEnvFloat("HEALTHY_SCORE", 100),
+		HealthCheckWeights:        src.getEnvMap("HEALTH_CHECK_WEIGHTS"),
+		ReadinessSuccessThreshold: src.getEnvInt("READINESS_SUCCESS_THRESHOLD", 1),
+		ReadinessFailureThreshold: src.getEnvInt("READINESS_FAILURE_THRESHOLD", 1),
+		ReadinessWarmup:           src.getEnvDuration("READINESS_WARMUP", 0),
+		ReadinessWebhookURL:       src.getEnv("READINESS_WEBHOOK_URL", ""),
+		ReadinessWebhookDebounce:  src.getEnvDuration("READINESS_WEBHOOK_DEBOUNCE", 30*time.Second),
+		DegradedScore:             src.getEnvFloat("DEGRADED_SCORE", 100),
+		RequestTimeout:            src.getEnvDuration("REQUEST_TIMEOUT", 30*time.Second),
+		MethodTimeouts:            src.getEnvDurationMap("METHOD_TIMEOUTS"),
+		RouteTimeouts:             src.getEnvDurationMap("ROUTE_TIMEOUTS"),
+		RouteAliases:              src.getEnvMap("ROUTE_ALIASES"),
+
+		DisabledEndpoints: src.getEnvList("DISABLED_ENDPOINTS", nil),
+
+		UpstreamURLs:            src.getEnvMap("UPSTREAM_URLS"),
+		UpstreamTimeouts:        src.getEnvDurationMap("UPSTREAM_TIMEOUTS"),
+		UpstreamTimeout:         src.getEnvDuration("UPSTREAM_TIMEOUT", 5*time.Second),
+		UpstreamMaxIdleConns:    src.getEnvInt("UPSTREAM_MAX_IDLE_CONNS", 10),
+		UpstreamMaxConns:        src.getEnvInt("UPSTREAM_MAX_CONNS", 0),
+		UpstreamIdleConnTimeout: src.getEnvDuration("UPSTREAM_IDLE_CONN_TIMEOUT", 90*time.Second),
+
+		LoadShedThreshold:   src.getEnvInt("LOAD_SHED_THRESHOLD", 0),
+		PriorityPaths:       src.getEnvList("PRIORITY_PATHS", []string{"/admin/*"}),
+		RateLimitRPS:        src.getEnvFloat("RATE_LIMIT_RPS", 0),
+		RateLimitBurst:      src.getEnvInt("RATE_LIMIT_BURST", 20),
+		RateLimitBucketTTL:  src.getEnvDuration("RATE_LIMIT_BUCKET_TTL", 10*time.Minute),
+		RateLimitMaxBuckets: src.getEnvInt("RATE_LIMIT_MAX_BUCKETS", 100000),
+
+		TenantRateLimitRPS:   src.getEnvFloat("TENANT_RATE_LIMIT_RPS", 0),
+		TenantRateLimitBurst: src.getEnvInt("TENANT_RATE_LIMIT_BURST", 20),
+		TenantRateLimits:     src.getEnvMap("TENANT_RATE_LIMITS"),
+		TenantHeader:         src.getEnv("TENANT_HEADER", ""),
+		TenantClaim:          src.getEnv("TENANT_CLAIM", ""),
+
+		RetryBudgetHeader: src.getEnvBool("RETRY_BUDGET_HEADER", false),
+
+		MaxConcurrentRequests: src.getEnvInt("MAX_CONCURRENT_REQUESTS", 0),
+		RequestQueueSize:      src.getEnvInt("REQUEST_QUEUE_SIZE", 100),
+		RequestQueueWait:      src.getEnvDuration("REQUEST_QUEUE_WAIT", time.Second),
+
+		ShutdownReadinessProbes:  src.getEnvInt("SHUTDOWN_READINESS_PROBES", 0),
+		ShutdownTimeout:          src.getEnvDuration("SHUTDOWN_TIMEOUT", 5*time.Second),
+		ConnDrainTimeout:         src.getEnvDuration("CONN_DRAIN_TIMEOUT", 4*time.Second),
+		MaxProcessLifetime:       src.getEnvDuration("MAX_PROCESS_LIFETIME", 0),
+		MaxProcessLifetimeJitter: src.getEnvFloat("MAX_PROCESS_LIFETIME_JITTER", 0.1),
+		ShutdownReadinessDelay:   src.getEnvDuration("SHUTDOWN_READINESS_DELAY", 0),
+
+		SlowShutdownHookThreshold: src.getEnvDuration("SLOW_SHUTDOWN_HOOK_THRESHOLD", 2*time.Second),
+		MiddlewareTimingThreshold: src.getEnvDuration("MIDDLEWARE_TIMING_THRESHOLD", 0),
+
+		MetricsNamespace: src.getEnv("METRICS_NAMESPACE", "dahlia"),
+		MetricsSubsystem: src.getEnv("METRICS_SUBSYSTEM", ""),
+		LatencyBuckets:   src.getEnvFloatList("LATENCY_BUCKETS"),
+
+		MetricsPushURL:              src.getEnv("METRICS_PUSH_URL", ""),
+		MetricsPushJob:              src.getEnv("METRICS_PUSH_JOB", "dahlia"),
+		MetricsPushInterval:         src.getEnvDuration("METRICS_PUSH_INTERVAL", 15*time.Second),
+		MetricsPushDeleteOnShutdown: src.getEnvBool("METRICS_PUSH_DELETE_ON_SHUTDOWN", true),
+
+		SchedulerProbeInterval: src.getEnvDuration("SCHEDULER_PROBE_INTERVAL", time.Second),
+		SchedulerLagThreshold:  src.getEnvDuration("SCHEDULER_LAG_THRESHOLD", 100*time.Millisecond),
+
+		GoroutineSampleInterval: src.getEnvDuration("GOROUTINE_SAMPLE_INTERVAL", 30*time.Second),
+		GoroutineThreshold:      src.getEnvInt("GOROUTINE_THRESHOLD", 10000),
+
+		TraceExporter:        src.getEnv("TRACE_EXPORTER", ""),
+		TraceSampleRate:      src.getEnvFloat("TRACE_SAMPLE_RATE", sampleRate),
+		TraceShutdownTimeout: src.getEnvDuration("TRACE_SHUTDOWN_TIMEOUT", 5*time.Second),
+
+		MetricsAggregationInterval: src.getEnvDuration("METRICS_AGGREGATION_INTERVAL", 15*time.Second),
+		LatencyWindowSize:          src.getEnvInt("LATENCY_WINDOW_SIZE", 1000),
+	}, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+func (s *source) getEnv(key, defaultValue string) string {
+	if value := s.lookup(key); value != "" {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func (s *source) getEnvBool(key string, defaultValue bool) bool {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvInt(key string, defaultValue int) int {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvFloat(key string, defaultValue float64) float64 {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := s.lookup(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+func (s *source) getEnvList(key string, defaultValue []string) []string {
+	value := s.lookup(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvFloatList parses a comma-separated list of numbers, skipping
+// malformed entries
+func (s *source) getEnvFloatList(key string) []float64 {
+	var result []float64
+	for _, item := range s.getEnvList(key, nil) {
+		if parsed, err := strconv.ParseFloat(item, 64); err == nil {
+			result = append(result, parsed)
+		}
+	}
+	return result
+}
+
+// getEnvDurationMap parses "key=duration" pairs separated by commas, skipping
+// malformed entries
+func (s *source) getEnvDurationMap(key string) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, item := range s.getEnvList(key, nil) {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if parsed, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			result[strings.TrimSpace(name)] = parsed
+		}
+	}
+	return result
+}
+
+// getEnvMap parses "key=value" pairs separated by commas, skipping malformed
+// entries
+func (s *source) getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range s.getEnvList(key, nil) {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return result
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// source resolves configuration keys, named like environment variables,
+// from the environment and then from an optional config file
+type source struct {
+	// file holds the config file values keyed by upper-cased name
+	file map[string]string
+	// used records the file keys that some setting asked for
+	used map[string]bool
+}
+
+// lookup returns the value for key, preferring the environment over the file
+func (s *source) lookup(key string) string {
+	value, inFile := s.file[key]
+	if inFile {
+		s.used[key] = true
+	}
+	if env := os.Getenv(key); env != "" {
+		return env
+	}
+	return value
+}
+
+// LoadFromFile loads configuration like Load, with the values in the YAML
+// file at path filling in for unset environment variables. The file is a
+// flat mapping keyed by environment variable name, in any case:
+//
+//	log_level: debug
+//	rate_limit_rps: 5
+//	api_keys: [key-one, key-two]
+//	route_timeouts: {/api/v1/info: 5s}
+//
+// Lists become comma-separated values and mappings become key=value pairs.
+// Keys that no setting reads are reported in Config.UnknownFileKeys.
+//
+// A read that fails, such as on a network mount or projected volume that
+// isn't ready yet, is retried with backoff for CONFIG_FILE_READ_TIMEOUT
+// (default 5s; 0 tries once) before giving up.
+func LoadFromFile(path string) (*Config, error) {
+	timeout := defaultFileReadTimeout
+	if v := os.Getenv("CONFIG_FILE_READ_TIMEOUT"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			return nil, fieldError("CONFIG_FILE_READ_TIMEOUT", fmt.Errorf("invalid CONFIG_FILE_READ_TIMEOUT %q", v))
+		}
+		timeout = parsed
+	}
+	data, err := readFileWithRetry(path, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	src := &source{file: make(map[string]string, len(raw)), used: make(map[string]bool)}
+	for key, value := range raw {
+		src.file[strings.ToUpper(key)] = flattenValue(value)
+	}
+
+	cfg, err := load(src)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConfigFile = path
+	for key := range src.file {
+		if !src.used[key] {
+			cfg.UnknownFileKeys = append(cfg.UnknownFileKeys, key)
+		}
+	}
+	sort.Strings(cfg.UnknownFileKeys)
+	return cfg, nil
+}
+
+// defaultFileReadTimeout is how long a failing config file read is retried
+// unless CONFIG_FILE_READ_TIMEOUT says otherwise
+const defaultFileReadTimeout = 5 * time.Second
+
+// Backoff between config file read attempts, doubling up to the maximum
+const (
+	fileReadBackoff    = 100 * time.Millisecond
+	fileReadMaxBackoff = time.Second
+)
+
+// readFileWithRetry reads path, retrying failed reads with backoff until
+// timeout has passed
+func readFileWithRetry(path string, timeout time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(timeout)
+	backoff := fileReadBackoff
+	for attempt := 1; ; attempt++ {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return data, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			if attempt == 1 {
+				return nil, fmt.Errorf("read config file: %w", err)
+			}
+			return nil, fmt.Errorf("read config file: gave up after %d attempts over %s: %w", attempt, timeout, err)
+		}
+		time.Sleep(min(backoff, remaining))
+		backoff = min(backoff*2, fileReadMaxBackoff)
+	}
+}
+
+// flattenValue renders a YAML value in the format of the equivalent
+// environment variable
+func flattenValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, flattenValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(v))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+flattenValue(v[k]))
+		}
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
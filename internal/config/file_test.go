@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFromFileRetriesUntilFileAppears(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		delay   time.Duration
+		wantErr string
+	}{
+		{name: "appears within the timeout", timeout: "2s", delay: 150 * time.Millisecond},
+		{name: "never appears", timeout: "200ms", wantErr: "gave up after"},
+		{name: "single attempt", timeout: "0", wantErr: "read config file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE_READ_TIMEOUT", tt.timeout)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.delay > 0 {
+				// Written elsewhere and renamed into place, as a projected
+				// volume does, so the read never sees a partial file
+				go func() {
+					time.Sleep(tt.delay)
+					tmp := path + ".tmp"
+					if err := os.WriteFile(tmp, []byte("port: 9191\n"), 0o600); err == nil {
+						os.Rename(tmp, path)
+					}
+				}()
+			}
+
+			cfg, err := LoadFromFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadFromFile() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Port != 9191 {
+				t.Errorf("port = %d, want 9191 from the file", cfg.Port)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// hotReloadable lists the fields (by JSON name) that take effect without a restart
+var hotReloadable = map[string]bool{
+	"log_level":       true,
+	"log_stack_level": true,
+}
+
+// Change describes a configuration field whose value differs after a reload
+type Change struct {
+	Field           string `json:"field"`
+	Old             string `json:"old"`
+	New             string `json:"new"`
+	RestartRequired bool   `json:"restart_required"`
+}
+
+// Diff returns the fields that differ between two configurations. Values of
+// fields tagged secret are redacted.
+func Diff(old, new *Config) []Change {
+	var changes []Change
+
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			continue
+		}
+
+		name := fieldName(t.Field(i))
+		if name == "-" {
+			continue
+		}
+		change := Change{
+			Field:           name,
+			Old:             fmt.Sprint(ov.Field(i).Interface()),
+			New:             fmt.Sprint(nv.Field(i).Interface()),
+			RestartRequired: !hotReloadable[name],
+		}
+		if t.Field(i).Tag.Get("secret") == "true" {
+			change.Old, change.New = "[redacted]", "[redacted]"
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Reloader reloads configuration and applies the hot-reloadable changes
+type Reloader struct {
+	mu      sync.Mutex
+	current *Config
+	load    func() (*Config, error)
+	apply   func(*Config)
+}
+
+// NewReloader creates a reloader starting from cfg. load produces the new
+// configuration and apply is called with the effective configuration after
+// hot-reloadable changes have been merged in.
+func NewReloader(cfg *Config, load func() (*Config, error), apply func(*Config)) *Reloader {
+	current := *cfg
+	return &Reloader{
+		current: &current,
+		load:    load,
+		apply:   apply,
+	}
+}
+
+// Reload loads the configuration again and applies hot-reloadable changes.
+// Fields that require a restart are reported but keep their current value.
+func (r *Reloader) Reload() ([]Change, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := Diff(r.current, loaded)
+	effective := *r.current
+	ev, lv := reflect.ValueOf(&effective).Elem(), reflect.ValueOf(loaded).Elem()
+	t := ev.Type()
+	applied := false
+	for i := 0; i < t.NumField(); i++ {
+		if hotReloadable[fieldName(t.Field(i))] {
+			ev.Field(i).Set(lv.Field(i))
+			applied = true
+		}
+	}
+
+	r.current = &effective
+	if applied {
+		r.apply(&effective)
+	}
+	return changes, nil
+}
+
+// fieldName returns the JSON name of a Config field
+func fieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
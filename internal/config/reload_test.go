@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffRedactsSecrets(t *testing.T) {
+	const password = "hunter2"
+
+	tests := []struct {
+		name     string
+		field    string
+		change   func(c *Config)
+		redacted bool
+	}{
+		{
+			name:     "database url",
+			field:    "database_url",
+			change:   func(c *Config) { c.DatabaseURL = "postgres://app:" + password + "@db/dahlia" },
+			redacted: true,
+		},
+		{
+			name:     "redis url",
+			field:    "redis_url",
+			change:   func(c *Config) { c.RedisURL = "redis://:" + password + "@cache:6379/0" },
+			redacted: true,
+		},
+		{
+			name:     "upstream urls",
+			field:    "upstream_urls",
+			change:   func(c *Config) { c.UpstreamURLs = map[string]string{"rust": "http://svc:" + password + "@rust"} },
+			redacted: true,
+		},
+		{
+			name:     "jwt secret",
+			field:    "jwt_secret",
+			change:   func(c *Config) { c.JWTSecret = password },
+			redacted: true,
+		},
+		{
+			name:   "log level",
+			field:  "log_level",
+			change: func(c *Config) { c.LogLevel = "debug" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := &Config{LogLevel: "info"}
+			updated := *old
+			tt.change(&updated)
+
+			changes := Diff(old, &updated)
+			if len(changes) != 1 || changes[0].Field != tt.field {
+				t.Fatalf("changes = %+v, want one change to %s", changes, tt.field)
+			}
+
+			out, err := json.Marshal(changes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			leaked := strings.Contains(string(out), password)
+			if tt.redacted && (leaked || changes[0].New != "[redacted]") {
+				t.Errorf("secret not redacted: %s", out)
+			}
+			if !tt.redacted && changes[0].New != "debug" {
+				t.Errorf("new value = %q, want debug", changes[0].New)
+			}
+		})
+	}
+}
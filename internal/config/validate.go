@@ -0,0 +1,163 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Validate checks the configuration for values the server can't run with,
+// returning every problem found joined into a single error
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fieldError("PORT", fmt.Errorf("PORT %d is outside 1-65535", c.Port)))
+	}
+	if c.GRPCPort < 0 || c.GRPCPort > 65535 {
+		errs = append(errs, fieldError("GRPC_PORT", fmt.Errorf("GRPC_PORT %d is outside 0-65535", c.GRPCPort)))
+	} else if c.GRPCPort == c.Port {
+		errs = append(errs, fieldError("GRPC_PORT", fmt.Errorf("GRPC_PORT %d is already used by PORT", c.GRPCPort)))
+	}
+	if c.MaxHeaderCount < 0 {
+		errs = append(errs, fieldError("MAX_HEADER_COUNT", fmt.Errorf("MAX_HEADER_COUNT %d must not be negative", c.MaxHeaderCount)))
+	}
+	if c.MaxMultipartMemory < 0 {
+		errs = append(errs, fieldError("MAX_MULTIPART_MEMORY", fmt.Errorf("MAX_MULTIPART_MEMORY %d must not be negative", c.MaxMultipartMemory)))
+	}
+	if c.ReadinessWarmup < 0 {
+		errs = append(errs, fieldError("READINESS_WARMUP", fmt.Errorf("READINESS_WARMUP %s must not be negative", c.ReadinessWarmup)))
+	}
+	if c.LatencyWindowSize < 1 {
+		errs = append(errs, fieldError("LATENCY_WINDOW_SIZE", fmt.Errorf("LATENCY_WINDOW_SIZE %d must be at least 1", c.LatencyWindowSize)))
+	}
+	if c.MaxConcurrentStreams < 1 {
+		errs = append(errs, fieldError("HTTP2_MAX_CONCURRENT_STREAMS", fmt.Errorf("HTTP2_MAX_CONCURRENT_STREAMS %d must be at least 1", c.MaxConcurrentStreams)))
+	}
+	if c.TenantRateLimitRPS > 0 && c.TenantHeader == "" && c.TenantClaim == "" {
+		errs = append(errs, fieldError("TENANT_RATE_LIMIT_RPS", errors.New("TENANT_RATE_LIMIT_RPS requires TENANT_HEADER or TENANT_CLAIM to identify tenants")))
+	}
+	if c.TenantRateLimitRPS > 0 && c.TenantHeader != "" && !trustsProxies(c.TrustedProxies) {
+		errs = append(errs, fieldError("TENANT_HEADER", errors.New("TENANT_HEADER is only read from TRUSTED_PROXIES; list the gateways that set it")))
+	}
+	if c.Environment == "production" && c.DebugEndpoints {
+		errs = append(errs, fieldError("DEBUG_ENDPOINTS", errors.New("DEBUG_ENDPOINTS must not be enabled in production")))
+	}
+	for _, path := range c.PriorityPaths {
+		if !strings.HasPrefix(path, "/") {
+			errs = append(errs, fieldError("PRIORITY_PATHS", fmt.Errorf("PRIORITY_PATHS entry %q must start with /", path)))
+		}
+	}
+	if c.ReadinessProbeInterval < 0 {
+		errs = append(errs, fieldError("READINESS_PROBE_INTERVAL", fmt.Errorf("READINESS_PROBE_INTERVAL %s must not be negative", c.ReadinessProbeInterval)))
+	}
+	if c.MaxProcessLifetime < 0 {
+		errs = append(errs, fieldError("MAX_PROCESS_LIFETIME", fmt.Errorf("MAX_PROCESS_LIFETIME %s must not be negative", c.MaxProcessLifetime)))
+	}
+	if c.MaxProcessLifetimeJitter < 0 || c.MaxProcessLifetimeJitter > 1 {
+		errs = append(errs, fieldError("MAX_PROCESS_LIFETIME_JITTER", fmt.Errorf("MAX_PROCESS_LIFETIME_JITTER %g must be between 0 and 1", c.MaxProcessLifetimeJitter)))
+	}
+	if c.ConnDrainTimeout < 0 {
+		errs = append(errs, fieldError("CONN_DRAIN_TIMEOUT", fmt.Errorf("CONN_DRAIN_TIMEOUT %s must not be negative", c.ConnDrainTimeout)))
+	}
+	if c.TraceExporter != "" && c.TraceShutdownTimeout <= 0 {
+		errs = append(errs, fieldError("TRACE_SHUTDOWN_TIMEOUT", fmt.Errorf("TRACE_SHUTDOWN_TIMEOUT %s must be positive", c.TraceShutdownTimeout)))
+	}
+	if c.JWTClockSkew < 0 {
+		errs = append(errs, fieldError("JWT_CLOCK_SKEW", fmt.Errorf("JWT_CLOCK_SKEW %s must not be negative", c.JWTClockSkew)))
+	}
+	if c.Environment == "production" && c.JWTSecret == DefaultJWTSecret {
+		errs = append(errs, fieldError("JWT_SECRET", errors.New("JWT_SECRET must be changed from the default in production")))
+	}
+	if err := validateURL(c.DatabaseURL); err != nil {
+		errs = append(errs, fieldError("DATABASE_URL", fmt.Errorf("DATABASE_URL: %w", err)))
+	}
+	if err := validateURL(c.RedisURL); err != nil {
+		errs = append(errs, fieldError("REDIS_URL", fmt.Errorf("REDIS_URL: %w", err)))
+	}
+	return errors.Join(errs...)
+}
+
+// validateURL requires an absolute URL with a scheme and host. Its errors
+// never include the URL or the parse error quoting it, since either may
+// carry a password.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	if u.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+	if u.Host == "" {
+		return errors.New("missing host")
+	}
+	return nil
+}
+
+// FieldError is a configuration error attributed to the setting it concerns.
+// Its message names the setting but never includes the value of a secret one,
+// so it is safe to log or return to an operator.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+func fieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
+
+// ErrorFields returns the settings named by the FieldErrors in err, which
+// may be joined, in order
+func ErrorFields(err error) []string {
+	var fields []string
+	for _, fe := range fieldErrors(err) {
+		if !slices.Contains(fields, fe.Field) {
+			fields = append(fields, fe.Field)
+		}
+	}
+	return fields
+}
+
+// RedactError describes err using only its FieldErrors, whose messages are
+// credential-free; any other error, such as a config file parse error that
+// quotes the file, is reduced to a generic description
+func RedactError(err error) string {
+	fes := fieldErrors(err)
+	if len(fes) == 0 {
+		return "configuration could not be loaded"
+	}
+	msgs := make([]string, len(fes))
+	for i, fe := range fes {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// fieldErrors flattens the FieldErrors out of err and the errors it wraps
+// or joins
+func fieldErrors(err error) []*FieldError {
+	switch e := err.(type) {
+	case *FieldError:
+		return []*FieldError{e}
+	case interface{ Unwrap() []error }:
+		var fes []*FieldError
+		for _, inner := range e.Unwrap() {
+			fes = append(fes, fieldErrors(inner)...)
+		}
+		return fes
+	case interface{ Unwrap() error }:
+		return fieldErrors(e.Unwrap())
+	}
+	return nil
+}
+
+// trustsProxies reports whether a TRUSTED_PROXIES list names any proxy
+func trustsProxies(proxies []string) bool {
+	return len(proxies) > 0 && !(len(proxies) == 1 && strings.EqualFold(proxies[0], "none"))
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateURLOmitsCredentials(t *testing.T) {
+	const password = "hunter2"
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{
+			name:   "unparseable database url",
+			change: func(c *Config) { c.DatabaseURL = "postgres://app:" + password + "@db:port/dahlia" },
+			want:   "DATABASE_URL: invalid URL",
+		},
+		{
+			name:   "database url without scheme",
+			change: func(c *Config) { c.DatabaseURL = "//app:" + password + "@db/dahlia" },
+			want:   "DATABASE_URL: missing scheme",
+		},
+		{
+			name:   "redis url without host",
+			change: func(c *Config) { c.RedisURL = "redis:" + password + "@cache" },
+			want:   "REDIS_URL: missing host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.change(cfg)
+
+			err = cfg.Validate()
+			if err == nil {
+				t.Fatal("Validate() = nil, want an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %q, want it to contain %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), password) {
+				t.Errorf("Validate() = %q leaks the password", err)
+			}
+		})
+	}
+}
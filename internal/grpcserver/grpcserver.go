@@ -0,0 +1,33 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/divijg19/Dahlia/internal/pb/dahliav1"
+	"github.com/divijg19/Dahlia/internal/status"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Logger interface for dependency injection
+type Logger interface {
+	Debug(msg string)
+}
+
+// service implements DahliaService, the gRPC API served alongside HTTP for
+// the Rust and Python clients
+type service struct {
+	dahliav1.UnimplementedDahliaServiceServer
+	logger Logger
+}
+
+// Register adds DahliaService to s
+func Register(s *grpc.Server, logger Logger) {
+	dahliav1.RegisterDahliaServiceServer(s, &service{logger: logger})
+}
+
+// GetStatus mirrors GET /api/v1/status, built by the same helper
+func (s *service) GetStatus(context.Context, *emptypb.Empty) (*dahliav1.Status, error) {
+	s.logger.Debug("gRPC GetStatus")
+	return status.Current(), nil
+}
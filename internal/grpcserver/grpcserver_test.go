@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/status"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string) {}
+
+func TestGetStatusUptimeFromProcessStart(t *testing.T) {
+	const wait = 20 * time.Millisecond
+	time.Sleep(wait)
+
+	// A service created now must still report the time since the process
+	// started, as GET /api/v1/status does
+	s := &service{logger: nopLogger{}}
+	got, err := s.GetStatus(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uptime, err := time.ParseDuration(got.Uptime)
+	if err != nil {
+		t.Fatalf("uptime %q: %v", got.Uptime, err)
+	}
+	if uptime < wait {
+		t.Errorf("uptime = %s, want at least %s since the process started", uptime, wait)
+	}
+
+	want := status.Current()
+	if got.Service != want.Service || got.Version != want.Version || got.Status != want.Status || got.GoVersion != want.GoVersion {
+		t.Errorf("GetStatus() = %v, want the fields of %v", got, want)
+	}
+}
@@ -0,0 +1,109 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultCommandTimeout bounds a command check when no timeout is configured
+const defaultCommandTimeout = 2 * time.Second
+
+// maxCommandOutput caps how much combined stdout/stderr is kept for logging
+const maxCommandOutput = 4 << 10
+
+// shellChars are rejected in command checks; commands are executed directly,
+// never through a shell, so these could only be a sign of a mistaken config
+const shellChars = ";|&$<>`\\\"'*?(){}\n"
+
+// Logger interface for dependency injection
+type Logger interface {
+	Info(msg string)
+	Warn(msg string)
+}
+
+// CommandChecker runs an external command and treats exit code 0 as healthy,
+// letting operators plug in black-box probes without code changes
+type CommandChecker struct {
+	settings
+	name    string
+	path    string
+	args    []string
+	timeout time.Duration
+	logger  Logger
+}
+
+// NewCommandChecker creates a checker running command, a program followed by
+// space-separated arguments. The command is executed without a shell, so
+// shell syntax such as pipes, quoting or variable expansion is rejected. The
+// program must be found on PATH or given as a path. Output of failed runs is
+// logged, truncated to 4KB.
+func NewCommandChecker(name, command string, timeout time.Duration, logger Logger) (*CommandChecker, error) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s: empty command", name)
+	}
+	if strings.ContainsAny(command, shellChars) {
+		return nil, fmt.Errorf("%s: command must not contain shell syntax (%q); it is run without a shell", name, shellChars)
+	}
+
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+
+	return &CommandChecker{
+		settings: defaultSettings(),
+		name:     name,
+		path:     path,
+		args:     fields[1:],
+		timeout:  timeout,
+		logger:   logger,
+	}, nil
+}
+
+// Name returns the checker name
+func (c *CommandChecker) Name() string {
+	return c.name
+}
+
+// Check runs the command, failing on a non-zero exit or timeout
+func (c *CommandChecker) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var output limitedBuffer
+	cmd := exec.CommandContext(ctx, c.path, c.args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("command timed out after %s", c.timeout)
+	}
+	c.logger.Warn(fmt.Sprintf("Health check %s failed: %v; output: %q", c.name, err, output.String()))
+	return err
+}
+
+// limitedBuffer keeps the first maxCommandOutput bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := maxCommandOutput - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,82 @@
+package health
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// warnLogger records warnings
+type warnLogger struct {
+	warns []string
+}
+
+func (l *warnLogger) Info(string)     {}
+func (l *warnLogger) Warn(msg string) { l.warns = append(l.warns, msg) }
+
+func TestCommandChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		wantErr string
+		// wantOutput is expected in the logged warning of a failed run
+		wantOutput string
+	}{
+		{name: "exit 0", command: "true"},
+		{name: "non-zero exit", command: "false", wantErr: "exit status 1"},
+		{name: "output of a failed run is logged", command: "ls /nonexistent-dahlia-path", wantErr: "exit status", wantOutput: "nonexistent-dahlia-path"},
+		{name: "timeout", command: "sleep 5", wantErr: "command timed out after 50ms"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exec.LookPath(strings.Fields(tt.command)[0]); err != nil {
+				t.Skipf("%s not available: %v", tt.command, err)
+			}
+			logger := &warnLogger{}
+			checker, err := NewCommandChecker("probe", tt.command, 50*time.Millisecond, logger)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			err = checker.Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("Check took %v with a 50ms timeout", elapsed)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				if len(logger.warns) != 0 {
+					t.Errorf("warnings = %q for a passing command", logger.warns)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Check() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if len(logger.warns) != 1 || !strings.Contains(logger.warns[0], "Health check probe failed") {
+				t.Fatalf("warnings = %q, want one for the failure", logger.warns)
+			}
+			if !strings.Contains(logger.warns[0], tt.wantOutput) {
+				t.Errorf("warning %q doesn't include the output %q", logger.warns[0], tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestNewCommandCheckerRejects(t *testing.T) {
+	for _, command := range []string{
+		"",
+		"curl localhost | grep ok",
+		"check $HOST",
+		"no-such-program-dahlia",
+	} {
+		if _, err := NewCommandChecker("probe", command, time.Second, &warnLogger{}); err == nil {
+			t.Errorf("NewCommandChecker(%q) succeeded, want an error", command)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCheckTimeout bounds how long a single checker may run
+const defaultCheckTimeout = 2 * time.Second
+
+// Checker reports the health of a single dependency
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// Critical is implemented by checkers that can be configured to opt out of
+// gating readiness by default. Checkers without it are treated as critical.
+type Critical interface {
+	Critical() bool
+}
+
+// Weighted is implemented by checkers with a configured contribution to the
+// health score; checkers without it weigh 1
+type Weighted interface {
+	Weight() float64
+}
+
+// Health tiers derived from the aggregate score
+const (
+	TierHealthy   = "healthy"
+	TierDegraded  = "degraded"
+	TierUnhealthy = "unhealthy"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Name     string        `json:"name"`
+	Healthy  bool          `json:"healthy"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report is the aggregated outcome of all checks. Score is the weighted
+// percentage of passing checks and Tier classifies it against the
+// aggregator's thresholds. Healthy only changes once the unhealthy tier has
+// been entered or left for enough consecutive runs, tracked by the streaks.
+type Report struct {
+	Healthy bool              `json:"healthy"`
+	Score   float64           `json:"score"`
+	Tier    string            `json:"tier"`
+	Results map[string]Result `json:"results"`
+
+	// SuccessStreak and FailureStreak count the consecutive runs that
+	// were, respectively, outside and inside the unhealthy tier
+	SuccessStreak int `json:"success_streak"`
+	FailureStreak int `json:"failure_streak"`
+
+	// Warmup is set while the warmup grace period is running
+	Warmup bool `json:"warmup,omitempty"`
+}
+
+// Aggregator runs the set of checkers that gate readiness
+type Aggregator struct {
+	checkers []Checker
+	// registered holds every checker by name, gating or not
+	registered map[string]Checker
+	timeout    time.Duration
+	// concurrency caps how many checks run at once; 0 means unlimited
+	concurrency int
+
+	// scores at or above healthyScore are healthy, at or above degradedScore
+	// degraded, and unhealthy below that
+	healthyScore  float64
+	degradedScore float64
+
+	// successThreshold consecutive passing runs flip an unhealthy aggregator
+	// to healthy and failureThreshold consecutive failing runs flip it back
+	successThreshold int
+	failureThreshold int
+
+	// failing runs before warmupUntil don't make a healthy aggregator
+	// unhealthy; warmupTolerated counts them
+	warmupUntil     time.Time
+	warmupTolerated int
+
+	mu            sync.Mutex
+	evaluated     bool
+	healthy       bool
+	successStreak int
+	failureStreak int
+	hooks         []func(Report)
+	checkHooks    []func(Result)
+}
+
+// NewAggregator creates an aggregator gating on the named checkers. An empty
+// list selects every registered critical checker. Names that don't match a
+// registered checker are rejected.
+func NewAggregator(names []string, checkers ...Checker) (*Aggregator, error) {
+	registered := make(map[string]Checker, len(checkers))
+	for _, c := range checkers {
+		registered[c.Name()] = c
+	}
+
+	agg := &Aggregator{
+		registered:       registered,
+		timeout:          defaultCheckTimeout,
+		healthyScore:     100,
+		degradedScore:    100,
+		successThreshold: 1,
+		failureThreshold: 1,
+	}
+	if len(names) == 0 {
+		for _, c := range checkers {
+			if isCritical(c) {
+				agg.checkers = append(agg.checkers, c)
+			}
+		}
+		return agg, nil
+	}
+
+	var unknown []string
+	for _, name := range names {
+		c, ok := registered[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		agg.checkers = append(agg.checkers, c)
+	}
+	if len(unknown) > 0 {
+		known := make([]string, 0, len(registered))
+		for name := range registered {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		return nil, fmt.Errorf("unknown readiness checks %s (registered: %s)",
+			strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return agg, nil
+}
+
+// SetScoreThresholds sets the minimum scores (0-100) for the healthy and
+// degraded tiers. The default of 100 for both makes any failing check
+// unhealthy.
+func (a *Aggregator) SetScoreThresholds(healthy, degraded float64) {
+	a.healthyScore = healthy
+	a.degradedScore = degraded
+}
+
+// SetHysteresis sets how many consecutive runs must pass before an unhealthy
+// aggregator reports healthy again, and how many must fail before a healthy
+// one reports unhealthy, so a flapping dependency doesn't make readiness
+// oscillate. Values below 1 are treated as 1, the default.
+func (a *Aggregator) SetHysteresis(successes, failures int) {
+	a.successThreshold = max(successes, 1)
+	a.failureThreshold = max(failures, 1)
+}
+
+// SetWarmup starts a grace period of d from now during which failing runs
+// don't flip a healthy aggregator to unhealthy, so cold dependency pools and
+// caches can't make readiness flap right after startup. Until the first
+// passing run the aggregator still reports unhealthy.
+func (a *Aggregator) SetWarmup(d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if d > 0 {
+		a.warmupUntil = time.Now().Add(d)
+	}
+}
+
+// Warmup returns when the warmup grace period ends, zero if none was set,
+// and how many failing runs it has absorbed so far
+func (a *Aggregator) Warmup() (until time.Time, tolerated int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.warmupUntil, a.warmupTolerated
+}
+
+// SetCheckTimeout sets how long a single checker may run before it is
+// reported as failed; zero or less keeps the default of 2s
+func (a *Aggregator) SetCheckTimeout(d time.Duration) {
+	if d > 0 {
+		a.timeout = d
+	}
+}
+
+// OnChange registers fn to be called whenever the aggregate health changes,
+// including the first run. Hooks run synchronously from Run and must not
+// call back into the aggregator.
+func (a *Aggregator) OnChange(fn func(healthy bool)) {
+	a.OnTransition(func(r Report) { fn(r.Healthy) })
+}
+
+// OnTransition is like OnChange but passes the full report of the run that
+// changed the aggregate health
+func (a *Aggregator) OnTransition(fn func(Report)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, fn)
+}
+
+// OnCheck registers fn to be called with the result of every check, from
+// both Run and RunOne, on the goroutine that ran it
+func (a *Aggregator) OnCheck(fn func(Result)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.checkHooks = append(a.checkHooks, fn)
+}
+
+// SetConcurrency caps how many checks Run executes at once so readiness
+// probes don't hit every dependency simultaneously. Zero or less is unlimited.
+func (a *Aggregator) SetConcurrency(n int) {
+	a.concurrency = n
+}
+
+// Names returns the names of the checkers gating readiness
+func (a *Aggregator) Names() []string {
+	names := make([]string, 0, len(a.checkers))
+	for _, c := range a.checkers {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Run executes all checks concurrently, at most the configured concurrency
+// at a time, aggregates their results and records the outcome in the
+// readiness streaks
+func (a *Aggregator) Run(ctx context.Context) Report {
+	return a.Record(a.Probe(ctx))
+}
+
+// Probe executes all checks like Run but leaves the readiness streaks alone,
+// for on-demand checks that mustn't move readiness. Healthy is the recorded
+// health, or this run's outcome while nothing has been recorded yet.
+func (a *Aggregator) Probe(ctx context.Context) Report {
+	report := Report{
+		Results: make(map[string]Result, len(a.checkers)),
+	}
+
+	var sem chan struct{}
+	if a.concurrency > 0 {
+		sem = make(chan struct{}, a.concurrency)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var total, passing float64
+	for _, c := range a.checkers {
+		wg.Add(1)
+		go func(c Checker) {
+			defer wg.Done()
+			if sem != nil {
+				sem <- struct{}{}
+				defer func() { <-sem }()
+			}
+			result := a.check(ctx, c)
+			weight := weightOf(c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Results[result.Name] = result
+			total += weight
+			if result.Healthy {
+				passing += weight
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	report.Score = 100
+	if total > 0 {
+		report.Score = passing / total * 100
+	}
+	report.Tier = a.tier(report.Score)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	report.Healthy = a.healthy
+	if !a.evaluated {
+		report.Healthy = report.Tier != TierUnhealthy
+	}
+	report.SuccessStreak, report.FailureStreak = a.successStreak, a.failureStreak
+	report.Warmup = time.Now().Before(a.warmupUntil)
+	return report
+}
+
+// Record applies a report from Probe to the readiness streaks, as Run does,
+// notifying the transition hooks if the health changes, and returns the
+// report with the resulting health
+func (a *Aggregator) Record(report Report) Report {
+	var hooks []func(Report)
+	report.Healthy, report.SuccessStreak, report.FailureStreak, report.Warmup, hooks = a.observe(report.Tier != TierUnhealthy)
+	for _, fn := range hooks {
+		fn(report)
+	}
+	return report
+}
+
+// observe records the outcome of a run and returns the resulting health,
+// streak counts and whether warmup is running, plus the hooks to notify when
+// the health changed. The first run sets the state directly; afterwards it
+// only flips once the opposing streak reaches its threshold, and never to
+// unhealthy during warmup.
+func (a *Aggregator) observe(passed bool) (healthy bool, successes, failures int, warmup bool, notify []func(Report)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if passed {
+		a.successStreak++
+		a.failureStreak = 0
+	} else {
+		a.failureStreak++
+		a.successStreak = 0
+	}
+
+	warmup = time.Now().Before(a.warmupUntil)
+	changed := true
+	switch {
+	case warmup && !passed && (a.healthy || !a.evaluated):
+		a.warmupTolerated++
+		changed = false
+	case !a.evaluated:
+		a.healthy = passed
+		a.evaluated = true
+	case a.healthy && a.failureStreak >= a.failureThreshold:
+		a.healthy = false
+	case !a.healthy && a.successStreak >= a.successThreshold:
+		a.healthy = true
+	default:
+		changed = false
+	}
+	if changed {
+		notify = a.hooks
+	}
+	return a.healthy, a.successStreak, a.failureStreak, warmup, notify
+}
+
+// RunOne executes the registered checker called name, whether or not it
+// gates readiness, reporting false if there is no such checker. It doesn't
+// affect the readiness streaks.
+func (a *Aggregator) RunOne(ctx context.Context, name string) (Result, bool) {
+	c, ok := a.registered[name]
+	if !ok {
+		return Result{}, false
+	}
+	return a.check(ctx, c), true
+}
+
+func (a *Aggregator) tier(score float64) string {
+	switch {
+	case score >= a.healthyScore:
+		return TierHealthy
+	case score >= a.degradedScore:
+		return TierDegraded
+	default:
+		return TierUnhealthy
+	}
+}
+
+func (a *Aggregator) check(ctx context.Context, c Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Check(ctx)
+	result := Result{
+		Name:     c.Name(),
+		Healthy:  err == nil,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	a.mu.Lock()
+	hooks := a.checkHooks
+	a.mu.Unlock()
+	for _, fn := range hooks {
+		fn(result)
+	}
+	return result
+}
+
+func isCritical(c Checker) bool {
+	if cc, ok := c.(Critical); ok {
+		return cc.Critical()
+	}
+	return true
+}
+
+func weightOf(c Checker) float64 {
+	if w, ok := c.(Weighted); ok && w.Weight() >= 0 {
+		return w.Weight()
+	}
+	return 1
+}
@@ -0,0 +1,152 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// overlapChecker records how many checks sharing it run at the same time.
+// Each check waits until want checks are running or a short delay passes.
+type overlapChecker struct {
+	settings
+	name    string
+	tracker *overlapTracker
+}
+
+type overlapTracker struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+	want    int
+	full    chan struct{}
+}
+
+func (o *overlapChecker) Name() string { return o.name }
+
+func (o *overlapChecker) Check(ctx context.Context) error {
+	t := o.tracker
+	t.mu.Lock()
+	t.running++
+	t.peak = max(t.peak, t.running)
+	if t.running == t.want {
+		close(t.full)
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.full:
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	t.mu.Lock()
+	t.running--
+	t.mu.Unlock()
+	return nil
+}
+
+func TestSetConcurrency(t *testing.T) {
+	const checks = 4
+	tests := []struct {
+		name        string
+		concurrency int
+		wantPeak    int
+	}{
+		{name: "sequential", concurrency: 1, wantPeak: 1},
+		{name: "capped", concurrency: 2, wantPeak: 2},
+		{name: "unlimited", concurrency: 0, wantPeak: checks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &overlapTracker{want: checks, full: make(chan struct{})}
+			var checkers []Checker
+			for i := range checks {
+				checkers = append(checkers, &overlapChecker{settings: defaultSettings(), name: fmt.Sprintf("dep%d", i), tracker: tracker})
+			}
+			agg, err := NewAggregator(nil, checkers...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			agg.SetConcurrency(tt.concurrency)
+
+			report := agg.Run(context.Background())
+			if len(report.Results) != checks {
+				t.Fatalf("results = %d, want %d", len(report.Results), checks)
+			}
+			if tracker.peak != tt.wantPeak {
+				t.Errorf("peak concurrent checks = %d, want %d", tracker.peak, tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestWarmupToleratesFailures(t *testing.T) {
+	agg, err := NewAggregator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.SetWarmup(time.Hour)
+
+	// until the first passing run the aggregator stays unhealthy, but a
+	// healthy one isn't flipped by failures during warmup
+	steps := []struct {
+		passed        bool
+		wantHealthy   bool
+		wantTolerated int
+	}{
+		{passed: false, wantHealthy: false, wantTolerated: 1},
+		{passed: true, wantHealthy: true, wantTolerated: 1},
+		{passed: false, wantHealthy: true, wantTolerated: 2},
+		{passed: false, wantHealthy: true, wantTolerated: 3},
+	}
+	for i, step := range steps {
+		healthy, _, _, warmup, _ := agg.observe(step.passed)
+		if !warmup {
+			t.Fatalf("step %d: warmup = false, want true", i)
+		}
+		if healthy != step.wantHealthy {
+			t.Errorf("step %d: healthy = %v, want %v", i, healthy, step.wantHealthy)
+		}
+		if _, tolerated := agg.Warmup(); tolerated != step.wantTolerated {
+			t.Errorf("step %d: tolerated = %d, want %d", i, tolerated, step.wantTolerated)
+		}
+	}
+}
+
+func TestWarmupExpires(t *testing.T) {
+	agg, err := NewAggregator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.SetWarmup(10 * time.Millisecond)
+	if healthy, _, _, _, _ := agg.observe(true); !healthy {
+		t.Fatal("healthy = false after a passing run")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	healthy, _, _, warmup, _ := agg.observe(false)
+	if warmup || healthy {
+		t.Errorf("after warmup: healthy = %v, warmup = %v, want a failing run to flip health", healthy, warmup)
+	}
+	if _, tolerated := agg.Warmup(); tolerated != 0 {
+		t.Errorf("tolerated = %d, want 0", tolerated)
+	}
+}
+
+func TestNoWarmup(t *testing.T) {
+	agg, err := NewAggregator(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.SetWarmup(0)
+	if until, _ := agg.Warmup(); !until.IsZero() {
+		t.Errorf("warmup until = %s, want none", until)
+	}
+	agg.observe(true)
+	if healthy, _, _, warmup, _ := agg.observe(false); healthy || warmup {
+		t.Errorf("healthy = %v, warmup = %v, want the failing run to flip health", healthy, warmup)
+	}
+}
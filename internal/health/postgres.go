@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresChecker verifies that Postgres accepts a session and answers a
+// ping. It connects with pgx, so authentication and the sslmode parameter
+// behave as they do for any other Postgres client.
+type PostgresChecker struct {
+	settings
+	name   string
+	config *pgx.ConnConfig
+}
+
+// NewPostgresChecker creates a checker for the Postgres server at rawURL.
+// Errors name the checker but never quote the URL, which may carry
+// credentials.
+func NewPostgresChecker(name, rawURL string) (*PostgresChecker, error) {
+	config, err := pgx.ParseConfig(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid url", name)
+	}
+	return &PostgresChecker{
+		settings: defaultSettings(),
+		name:     name,
+		config:   config,
+	}, nil
+}
+
+// Name returns the checker name
+func (p *PostgresChecker) Name() string {
+	return p.name
+}
+
+// Check opens a session, pings the server and closes the session
+func (p *PostgresChecker) Check(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, p.config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	if err := conn.Ping(ctx); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	return nil
+}
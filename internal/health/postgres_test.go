@@ -0,0 +1,72 @@
+package health
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPostgresCheckerErrorOmitsURL(t *testing.T) {
+	const password = "hunter2"
+
+	_, err := NewPostgresChecker("database", "postgres://app:"+password+"@db/dahlia?sslmode=bogus")
+	if err == nil {
+		t.Fatal("NewPostgresChecker() = nil error, want one for an unknown sslmode")
+	}
+	if want := "database: invalid url"; err.Error() != want {
+		t.Errorf("NewPostgresChecker() error = %q, want %q", err, want)
+	}
+	if strings.Contains(err.Error(), password) {
+		t.Errorf("NewPostgresChecker() error %q leaks the password", err)
+	}
+}
+
+func TestPostgresCheckerFailsWithoutServer(t *testing.T) {
+	tests := []struct {
+		name  string
+		serve func(net.Listener)
+	}{
+		{
+			name:  "connection refused",
+			serve: func(ln net.Listener) { ln.Close() },
+		},
+		{
+			name: "connection dropped",
+			serve: func(ln net.Listener) {
+				go func() {
+					for {
+						conn, err := ln.Accept()
+						if err != nil {
+							return
+						}
+						conn.Close()
+					}
+				}()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { ln.Close() })
+			addr := ln.Addr().String()
+			tt.serve(ln)
+
+			checker, err := NewPostgresChecker("database", "postgres://app@"+addr+"/dahlia?sslmode=disable")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := checker.Check(ctx); err == nil {
+				t.Fatal("Check() = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Prober runs an aggregator's checks in the background on a fixed interval
+// and caches the latest report, so readiness probes are answered instantly
+// and dependency load doesn't scale with how often the load balancer probes
+type Prober struct {
+	agg      *Aggregator
+	interval time.Duration
+	timeout  time.Duration
+
+	mu        sync.RWMutex
+	latest    Report
+	checkedAt time.Time
+}
+
+// NewProber creates a prober running agg every interval, giving up on a run
+// after timeout
+func NewProber(agg *Aggregator, interval, timeout time.Duration) *Prober {
+	return &Prober{
+		agg:      agg,
+		interval: interval,
+		timeout:  timeout,
+	}
+}
+
+// Run probes immediately and then every interval until ctx is cancelled
+func (p *Prober) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Prober) probe(ctx context.Context) {
+	runCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	report := p.agg.Run(runCtx)
+	if ctx.Err() != nil {
+		// Shutting down; a run cut short says nothing about the dependencies
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.latest = report
+	p.checkedAt = time.Now()
+}
+
+// Latest returns the most recent report and when it was taken, reporting
+// false until the first run has finished
+func (p *Prober) Latest() (Report, time.Time, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.latest, p.checkedAt, !p.checkedAt.IsZero()
+}
@@ -0,0 +1,81 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// RedisChecker verifies that Redis answers PING, authenticating with the
+// credentials in its URL. A rediss:// URL connects over TLS.
+type RedisChecker struct {
+	*TCPChecker
+}
+
+// NewRedisChecker creates a checker for the Redis server at rawURL
+func NewRedisChecker(name, rawURL string) (*RedisChecker, error) {
+	t, err := NewTCPChecker(name, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisChecker{TCPChecker: t}, nil
+}
+
+// Check sends AUTH when the URL has credentials, then PING, expecting PONG
+func (r *RedisChecker) Check(ctx context.Context) error {
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if r.url.Scheme == "rediss" {
+		conn = tls.Client(conn, &tls.Config{ServerName: r.url.Hostname()})
+	}
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if password, ok := r.url.User.Password(); ok {
+		args := []string{"AUTH", password}
+		if user := r.url.User.Username(); user != "" {
+			args = []string{"AUTH", user, password}
+		}
+		if _, err := redisCommand(rw, args...); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	reply, err := redisCommand(rw, "PING")
+	if err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("ping: unexpected reply %q", reply)
+	}
+	return nil
+}
+
+// redisCommand sends a command and returns its simple string reply, or the
+// server's error reply as an error
+func redisCommand(rw *bufio.ReadWriter, args ...string) (string, error) {
+	fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	switch {
+	case strings.HasPrefix(line, "+"):
+		return line[1:], nil
+	case strings.HasPrefix(line, "-"):
+		return "", fmt.Errorf("redis: %s", line[1:])
+	default:
+		return "", fmt.Errorf("unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRedis serves one connection, answering each command with reply
+func fakeRedis(t *testing.T, reply func(args []string) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			args, err := readRESPArray(r)
+			if err != nil {
+				return
+			}
+			fmt.Fprint(conn, reply(args))
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func readRESPArray(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, n)
+	for range n {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSpace(arg))
+	}
+	return args, nil
+}
+
+func TestRedisChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		userinfo string
+		reply    func(args []string) string
+		wantErr  string
+	}{
+		{
+			name:  "pong",
+			reply: func([]string) string { return "+PONG\r\n" },
+		},
+		{
+			name:     "auth then pong",
+			userinfo: ":secret@",
+			reply: func(args []string) string {
+				if args[0] == "AUTH" && (len(args) != 2 || args[1] != "secret") {
+					return "-ERR bad auth\r\n"
+				}
+				return map[string]string{"AUTH": "+OK\r\n", "PING": "+PONG\r\n"}[args[0]]
+			},
+		},
+		{
+			name:     "auth with user",
+			userinfo: "app:secret@",
+			reply: func(args []string) string {
+				if args[0] == "AUTH" {
+					if len(args) != 3 || args[1] != "app" || args[2] != "secret" {
+						return "-ERR bad auth\r\n"
+					}
+					return "+OK\r\n"
+				}
+				return "+PONG\r\n"
+			},
+		},
+		{
+			name:     "auth rejected",
+			userinfo: ":wrong@",
+			reply:    func([]string) string { return "-WRONGPASS invalid password\r\n" },
+			wantErr:  "auth: redis: WRONGPASS invalid password",
+		},
+		{
+			name:    "loading",
+			reply:   func([]string) string { return "-LOADING Redis is loading the dataset in memory\r\n" },
+			wantErr: "ping: redis: LOADING",
+		},
+		{
+			name:    "not redis",
+			reply:   func([]string) string { return "HTTP/1.1 400 Bad Request\r\n" },
+			wantErr: "ping:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := fakeRedis(t, tt.reply)
+			checker, err := NewRedisChecker("redis", "redis://"+tt.userinfo+addr+"/0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err = checker.Check(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Check() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedisCheckerHungServer(t *testing.T) {
+	block := make(chan struct{})
+	addr := fakeRedis(t, func([]string) string { <-block; return "" })
+	t.Cleanup(func() { close(block) })
+	checker, err := NewRedisChecker("redis", "redis://"+addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := checker.Check(ctx); err == nil {
+		t.Fatal("Check() = nil against a server that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Check() took %v, want it bounded by the context", elapsed)
+	}
+}
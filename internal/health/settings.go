@@ -0,0 +1,99 @@
+package health
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// settings holds the per-check configuration shared by the built-in
+// checkers, which embed it to implement Weighted and Critical
+type settings struct {
+	weight   float64
+	critical bool
+}
+
+// defaultSettings are the settings of a checker nothing was configured for
+func defaultSettings() settings {
+	return settings{weight: 1, critical: true}
+}
+
+// Critical reports whether the check gates readiness when READINESS_CHECKS
+// doesn't list the checks explicitly
+func (s *settings) Critical() bool {
+	return s.critical
+}
+
+// SetCritical sets whether the check gates readiness by default
+func (s *settings) SetCritical(critical bool) {
+	s.critical = critical
+}
+
+// Weight returns the check's contribution to the health score
+func (s *settings) Weight() float64 {
+	return s.weight
+}
+
+// SetWeight sets the check's contribution to the health score; zero keeps a
+// gating check from affecting the score at all
+func (s *settings) SetWeight(w float64) {
+	s.weight = w
+}
+
+// ParseWeights converts a mapping from check name to weight into weights,
+// rejecting negative or non-numeric values
+func ParseWeights(mapping map[string]string) (map[string]float64, error) {
+	weights := make(map[string]float64, len(mapping))
+	for name, value := range mapping {
+		w, err := strconv.ParseFloat(value, 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight for health check %q must be a non-negative number, got %q", name, value)
+		}
+		weights[name] = w
+	}
+	return weights, nil
+}
+
+// SetWeights applies weights to the checkers by name. Names matching no
+// checker, or a checker whose weight can't be set, are rejected.
+func SetWeights(checkers []Checker, weights map[string]float64) error {
+	for name, w := range weights {
+		c := find(checkers, name)
+		if c == nil {
+			return fmt.Errorf("weight for unknown health check %q", name)
+		}
+		s, ok := c.(interface{ SetWeight(float64) })
+		if !ok {
+			return fmt.Errorf("health check %q has a fixed weight", name)
+		}
+		s.SetWeight(w)
+	}
+	return nil
+}
+
+// SetNonCritical keeps the named checkers from gating readiness by default.
+// Names matching no checker, or a checker that is always critical, are
+// rejected.
+func SetNonCritical(checkers []Checker, names []string) error {
+	for _, name := range names {
+		c := find(checkers, name)
+		if c == nil {
+			return fmt.Errorf("unknown non-critical health check %q", name)
+		}
+		s, ok := c.(interface{ SetCritical(bool) })
+		if !ok {
+			return fmt.Errorf("health check %q is always critical", name)
+		}
+		s.SetCritical(false)
+	}
+	return nil
+}
+
+// find returns the checker with the given name, or nil
+func find(checkers []Checker, name string) Checker {
+	for _, c := range checkers {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
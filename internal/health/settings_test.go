@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// fakeChecker reports a fixed outcome
+type fakeChecker struct {
+	settings
+	name string
+	err  error
+}
+
+func newFakeChecker(name string, err error) *fakeChecker {
+	return &fakeChecker{settings: defaultSettings(), name: name, err: err}
+}
+
+func (f *fakeChecker) Name() string                    { return f.name }
+func (f *fakeChecker) Check(ctx context.Context) error { return f.err }
+
+func TestWeightedScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]string
+		score   float64
+		wantErr bool
+	}{
+		{name: "default weights", score: 50},
+		{name: "passing check weighs more", weights: map[string]string{"database": "3"}, score: 75},
+		{name: "failing check excluded", weights: map[string]string{"redis": "0"}, score: 100},
+		{name: "negative weight", weights: map[string]string{"redis": "-1"}, wantErr: true},
+		{name: "unknown check", weights: map[string]string{"cache": "2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkers := []Checker{
+				newFakeChecker("database", nil),
+				newFakeChecker("redis", errors.New("connection refused")),
+			}
+			weights, err := ParseWeights(tt.weights)
+			if err == nil {
+				err = SetWeights(checkers, weights)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			agg, err := NewAggregator(nil, checkers...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			agg.SetScoreThresholds(0, 0)
+			if report := agg.Run(context.Background()); report.Score != tt.score {
+				t.Errorf("score = %g, want %g", report.Score, tt.score)
+			}
+		})
+	}
+}
+
+func TestNonCriticalChecks(t *testing.T) {
+	tests := []struct {
+		name        string
+		nonCritical []string
+		gating      []string
+		wantErr     bool
+	}{
+		{name: "all critical by default", gating: []string{"database", "redis"}},
+		{name: "non-critical check skipped", nonCritical: []string{"redis"}, gating: []string{"database"}},
+		{name: "unknown check", nonCritical: []string{"cache"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkers := []Checker{
+				newFakeChecker("database", nil),
+				newFakeChecker("redis", nil),
+			}
+			err := SetNonCritical(checkers, tt.nonCritical)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			agg, err := NewAggregator(nil, checkers...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := agg.Names(); !slices.Equal(got, tt.gating) {
+				t.Errorf("gating checks = %v, want %v", got, tt.gating)
+			}
+		})
+	}
+}
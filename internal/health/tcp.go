@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// defaultPorts maps URL schemes to the port used when the URL omits one
+var defaultPorts = map[string]string{
+	"postgres":   "5432",
+	"postgresql": "5432",
+	"redis":      "6379",
+	"rediss":     "6379",
+	"http":       "80",
+	"https":      "443",
+}
+
+// TCPChecker verifies that a dependency accepts TCP connections. The
+// protocol checkers build on it to dial their server.
+type TCPChecker struct {
+	settings
+	name    string
+	address string
+	url     *url.URL
+}
+
+// NewTCPChecker creates a checker dialing the host of rawURL. Errors name the
+// checker but never quote the URL, which may carry credentials.
+func NewTCPChecker(name, rawURL string) (*TCPChecker, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid url", name)
+	}
+
+	host, port := u.Hostname(), u.Port()
+	if host == "" {
+		return nil, fmt.Errorf("%s: url has no host", name)
+	}
+	if port == "" {
+		port = defaultPorts[u.Scheme]
+	}
+	if port == "" {
+		return nil, fmt.Errorf("%s: url has no port and scheme %q has no default", name, u.Scheme)
+	}
+
+	return &TCPChecker{
+		settings: defaultSettings(),
+		name:     name,
+		address:  net.JoinHostPort(host, port),
+		url:      u,
+	}, nil
+}
+
+// Name returns the checker name
+func (t *TCPChecker) Name() string {
+	return t.name
+}
+
+// Check dials the dependency and closes the connection immediately
+func (t *TCPChecker) Check(ctx context.Context) error {
+	conn, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// dial connects to the dependency. The connection is closed when ctx is
+// done, so a server that stops answering can't outlast the check.
+func (t *TCPChecker) dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", t.address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	return &ctxConn{Conn: conn, stop: stop}, nil
+}
+
+// ctxConn is a connection closed when its context is done
+type ctxConn struct {
+	net.Conn
+	stop func() bool
+}
+
+func (c *ctxConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
@@ -0,0 +1,34 @@
+package health
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTCPCheckerErrorOmitsURL(t *testing.T) {
+	const password = "hunter2"
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "unparseable", url: "postgres://app:" + password + "@db:port/dahlia", want: "database: invalid url"},
+		{name: "no host", url: "postgres://app:" + password + "@/dahlia", want: "database: url has no host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTCPChecker("database", tt.url)
+			if err == nil {
+				t.Fatal("NewTCPChecker() = nil error, want one")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("NewTCPChecker() error = %q, want %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), password) {
+				t.Errorf("NewTCPChecker() error %q leaks the password", err)
+			}
+		})
+	}
+}
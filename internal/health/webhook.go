@@ -0,0 +1,130 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// notifyTimeout bounds a single webhook delivery
+const notifyTimeout = 5 * time.Second
+
+// Notification is the JSON body POSTed to the webhook on a transition
+type Notification struct {
+	Healthy   bool              `json:"healthy"`
+	Score     float64           `json:"score"`
+	Tier      string            `json:"tier"`
+	Results   map[string]Result `json:"results"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// WebhookNotifier POSTs readiness transitions to a webhook URL. A transition
+// is only delivered once health has stayed in the new state for the debounce
+// window, so flapping produces at most one call per settled change.
+// Deliveries run on their own goroutine and never block Run.
+type WebhookNotifier struct {
+	url      string
+	debounce time.Duration
+	client   *http.Client
+	logger   Logger
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	pending Report
+	// sent is the health last delivered; the service is assumed healthy
+	// until told otherwise, so starting unhealthy is reported
+	sent    bool
+	stopped bool
+}
+
+// NewWebhookNotifier creates a notifier POSTing to url after debounce
+func NewWebhookNotifier(url string, debounce time.Duration, logger Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:      url,
+		debounce: debounce,
+		client:   &http.Client{Timeout: notifyTimeout},
+		logger:   logger,
+		sent:     true,
+	}
+}
+
+// Notify schedules delivery of report, replacing any transition still
+// waiting out the debounce window. Register it with Aggregator.OnTransition.
+func (n *WebhookNotifier) Notify(report Report) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stopped {
+		return
+	}
+	n.pending = report
+	if n.timer == nil {
+		n.timer = time.AfterFunc(n.debounce, n.flush)
+		return
+	}
+	n.timer.Reset(n.debounce)
+}
+
+// flush delivers the pending report unless health flapped back to the state
+// last delivered
+func (n *WebhookNotifier) flush() {
+	n.mu.Lock()
+	report := n.pending
+	if n.stopped || report.Healthy == n.sent {
+		n.mu.Unlock()
+		return
+	}
+	n.sent = report.Healthy
+	n.mu.Unlock()
+
+	if err := n.post(report); err != nil {
+		n.logger.Warn(fmt.Sprintf("Readiness webhook failed: %v", err))
+		return
+	}
+	n.logger.Info(fmt.Sprintf("Readiness webhook notified: healthy=%t tier=%s", report.Healthy, report.Tier))
+}
+
+func (n *WebhookNotifier) post(report Report) error {
+	body, err := json.Marshal(Notification{
+		Healthy:   report.Healthy,
+		Score:     report.Score,
+		Tier:      report.Tier,
+		Results:   report.Results,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// Stop cancels any pending delivery and ignores later transitions
+func (n *WebhookNotifier) Stop(context.Context) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stopped = true
+	if n.timer != nil {
+		n.timer.Stop()
+	}
+	return nil
+}
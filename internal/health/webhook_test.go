@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// webhookServer collects the notifications POSTed to it
+func webhookServer(t *testing.T) (*httptest.Server, chan Notification) {
+	t.Helper()
+	received := make(chan Notification, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Errorf("decode notification: %v", err)
+		}
+		received <- n
+	}))
+	t.Cleanup(srv.Close)
+	return srv, received
+}
+
+// delivered waits out the debounce window and returns what was received
+func delivered(received chan Notification, debounce time.Duration) []Notification {
+	time.Sleep(5 * debounce)
+	var got []Notification
+	for {
+		select {
+		case n := <-received:
+			got = append(got, n)
+		default:
+			return got
+		}
+	}
+}
+
+func TestWebhookNotifierDebounce(t *testing.T) {
+	const debounce = 30 * time.Millisecond
+	unhealthy := Report{Healthy: false, Tier: TierUnhealthy}
+	healthy := Report{Healthy: true, Tier: TierHealthy}
+
+	tests := []struct {
+		name        string
+		transitions []Report
+		want        []bool
+	}{
+		{name: "settled failure", transitions: []Report{unhealthy}, want: []bool{false}},
+		{name: "repeated reports of one transition", transitions: []Report{unhealthy, unhealthy, unhealthy}, want: []bool{false}},
+		{name: "flap back within the window", transitions: []Report{unhealthy, healthy}, want: nil},
+		{name: "flapping that settles unhealthy", transitions: []Report{unhealthy, healthy, unhealthy}, want: []bool{false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, received := webhookServer(t)
+			n := NewWebhookNotifier(srv.URL, debounce, &warnLogger{})
+			defer n.Stop(context.Background())
+
+			for _, report := range tt.transitions {
+				n.Notify(report)
+			}
+			got := delivered(received, debounce)
+			if len(got) != len(tt.want) {
+				t.Fatalf("webhook called %d times, want %d", len(got), len(tt.want))
+			}
+			for i, notification := range got {
+				if notification.Healthy != tt.want[i] {
+					t.Errorf("call %d healthy = %v, want %v", i, notification.Healthy, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierOneCallPerTransition(t *testing.T) {
+	const debounce = 20 * time.Millisecond
+	srv, received := webhookServer(t)
+	n := NewWebhookNotifier(srv.URL, debounce, &warnLogger{})
+	defer n.Stop(context.Background())
+
+	for _, healthy := range []bool{false, true, false} {
+		n.Notify(Report{Healthy: healthy})
+		got := delivered(received, debounce)
+		if len(got) != 1 || got[0].Healthy != healthy {
+			t.Fatalf("transition to healthy=%v delivered %v, want one call", healthy, got)
+		}
+	}
+}
+
+func TestWebhookNotifierStop(t *testing.T) {
+	const debounce = 20 * time.Millisecond
+	srv, received := webhookServer(t)
+	n := NewWebhookNotifier(srv.URL, debounce, &warnLogger{})
+
+	n.Notify(Report{Healthy: false})
+	n.Stop(context.Background())
+	n.Notify(Report{Healthy: false})
+	if got := delivered(received, debounce); len(got) != 0 {
+		t.Errorf("webhook called %d times after Stop, want 0", len(got))
+	}
+}
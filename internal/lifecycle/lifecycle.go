@@ -0,0 +1,174 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Logger interface for dependency injection
+type Logger interface {
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+	Debug(msg string)
+}
+
+// Priority controls when a shutdown hook runs relative to other hooks.
+// Hooks with a lower priority run first, so components that accept work
+// (listeners) are stopped before the resources they depend on are closed.
+// Hooks sharing a priority run in reverse registration order.
+type Priority int
+
+const (
+	// PriorityListeners is for servers that accept external traffic
+	PriorityListeners Priority = 100
+	// PriorityWorkers is for background goroutines that produce or consume work
+	PriorityWorkers Priority = 200
+	// PriorityResources is for shared resources such as database and cache clients
+	PriorityResources Priority = 300
+)
+
+// HookFunc is a shutdown callback. It should return once cleanup is done or
+// the context is cancelled, whichever comes first.
+type HookFunc func(ctx context.Context) error
+
+type hook struct {
+	name     string
+	priority Priority
+	seq      int
+	fn       HookFunc
+}
+
+// defaultSlowHookThreshold is the hook duration above which a warning is logged
+const defaultSlowHookThreshold = 2 * time.Second
+
+// Manager coordinates the ordered shutdown of application components
+type Manager struct {
+	mu     sync.Mutex
+	hooks  []hook
+	logger Logger
+
+	slowHookThreshold time.Duration
+}
+
+// Option configures optional Manager behavior
+type Option func(*Manager)
+
+// WithSlowHookThreshold sets the duration after which a shutdown hook is
+// reported as slow
+func WithSlowHookThreshold(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.slowHookThreshold = d
+		}
+	}
+}
+
+// New creates a new lifecycle manager
+func New(logger Logger, opts ...Option) *Manager {
+	m := &Manager{
+		logger:            logger,
+		slowHookThreshold: defaultSlowHookThreshold,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// OnShutdown registers a named hook to run during Shutdown at the given priority
+func (m *Manager) OnShutdown(name string, priority Priority, fn HookFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook{
+		name:     name,
+		priority: priority,
+		seq:      len(m.hooks),
+		fn:       fn,
+	})
+}
+
+// Go runs fn in a background goroutine until shutdown. The context passed to
+// fn is cancelled by a PriorityWorkers shutdown hook, which then waits for fn
+// to return or for the shutdown deadline, whichever comes first.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	SafeGo(m.logger, name, func() {
+		defer close(done)
+		fn(ctx)
+	})
+
+	m.OnShutdown(name, PriorityWorkers, func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	})
+}
+
+// Shutdown runs all registered hooks in priority order. A failing hook does
+// not prevent later hooks from running; all errors are returned joined.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := make([]hook, len(m.hooks))
+	copy(hooks, m.hooks)
+	m.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		if hooks[i].priority != hooks[j].priority {
+			return hooks[i].priority < hooks[j].priority
+		}
+		return hooks[i].seq > hooks[j].seq
+	})
+
+	var errs []error
+	for _, h := range hooks {
+		if err := m.runHook(ctx, h); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// runHook runs a single hook, logging its duration. A hook still running when
+// the shutdown deadline passes is abandoned so the remaining hooks get a
+// chance to run.
+func (m *Manager) runHook(ctx context.Context, h hook) error {
+	m.logger.Debug(fmt.Sprintf("Running shutdown hook %q", h.name))
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		m.logger.Error(fmt.Sprintf("Shutdown hook %q abandoned after %s: grace period exceeded", h.name, time.Since(start)))
+		return ctx.Err()
+	}
+
+	elapsed := time.Since(start)
+	switch {
+	case err != nil:
+		m.logger.Error(fmt.Sprintf("Shutdown hook %q failed after %s: %v", h.name, elapsed, err))
+	case elapsed > m.slowHookThreshold:
+		m.logger.Warn(fmt.Sprintf("Shutdown hook %q was slow: completed in %s (threshold %s)", h.name, elapsed, m.slowHookThreshold))
+	default:
+		m.logger.Info(fmt.Sprintf("Shutdown hook %q completed in %s", h.name, elapsed))
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string)  {}
+func (nopLogger) Warn(string)  {}
+func (nopLogger) Error(string) {}
+func (nopLogger) Debug(string) {}
+
+// recorder collects the names of the hooks or steps that ran, in order
+type recorder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (r *recorder) hook(name string, err error) HookFunc {
+	return func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.names = append(r.names, name)
+		return err
+	}
+}
+
+func TestShutdownOrder(t *testing.T) {
+	type registration struct {
+		name     string
+		priority Priority
+	}
+	tests := []struct {
+		name  string
+		hooks []registration
+		want  []string
+	}{
+		{
+			name: "priority wins over registration order",
+			hooks: []registration{
+				{"redis", PriorityResources},
+				{"workers", PriorityWorkers},
+				{"http", PriorityListeners},
+			},
+			want: []string{"http", "workers", "redis"},
+		},
+		{
+			name: "same priority runs in reverse registration order",
+			hooks: []registration{
+				{"database", PriorityResources},
+				{"redis", PriorityResources},
+				{"grpc", PriorityListeners},
+				{"http", PriorityListeners},
+			},
+			want: []string{"http", "grpc", "redis", "database"},
+		},
+		{
+			name: "custom priorities interleave",
+			hooks: []registration{
+				{"tracing", PriorityResources + 10},
+				{"database", PriorityResources},
+				{"flush-queue", PriorityWorkers + 50},
+				{"http", PriorityListeners},
+			},
+			want: []string{"http", "flush-queue", "database", "tracing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(nopLogger{})
+			var r recorder
+			for _, h := range tt.hooks {
+				m.OnShutdown(h.name, h.priority, r.hook(h.name, nil))
+			}
+			if err := m.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(r.names, tt.want) {
+				t.Fatalf("hooks ran in order %v, want %v", r.names, tt.want)
+			}
+		})
+	}
+}
+
+func TestShutdownContinuesAfterFailure(t *testing.T) {
+	m := New(nopLogger{})
+	var r recorder
+	failure := errors.New("close failed")
+	m.OnShutdown("http", PriorityListeners, r.hook("http", failure))
+	m.OnShutdown("database", PriorityResources, r.hook("database", nil))
+
+	err := m.Shutdown(context.Background())
+	if !errors.Is(err, failure) {
+		t.Fatalf("Shutdown() = %v, want it to report %v", err, failure)
+	}
+	if !slices.Equal(r.names, []string{"http", "database"}) {
+		t.Fatalf("hooks ran %v, want both despite the failure", r.names)
+	}
+}
+
+func TestShutdownAbandonsHungHook(t *testing.T) {
+	m := New(nopLogger{})
+	var r recorder
+	release := make(chan struct{})
+	defer close(release)
+	m.OnShutdown("hung", PriorityListeners, func(context.Context) error {
+		<-release
+		return nil
+	})
+	m.OnShutdown("database", PriorityResources, r.hook("database", nil))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// warnLogger records warnings and info messages
+type warnLogger struct {
+	nopLogger
+	mu    sync.Mutex
+	warns []string
+	infos []string
+}
+
+func (l *warnLogger) Warn(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, msg)
+}
+
+func (l *warnLogger) Info(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.infos = append(l.infos, msg)
+}
+
+func TestSlowHookWarning(t *testing.T) {
+	sleep := func(d time.Duration) HookFunc {
+		return func(context.Context) error {
+			time.Sleep(d)
+			return nil
+		}
+	}
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		duration  time.Duration
+		wantWarn  bool
+	}{
+		{name: "over the threshold", threshold: 10 * time.Millisecond, duration: 30 * time.Millisecond, wantWarn: true},
+		{name: "under the threshold", threshold: time.Second, duration: 0},
+		{name: "non-positive threshold keeps the default", threshold: -1, duration: 30 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &warnLogger{}
+			m := New(logger, WithSlowHookThreshold(tt.threshold))
+			m.OnShutdown("cache", PriorityResources, sleep(tt.duration))
+			if err := m.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(logger.warns) == 1; got != tt.wantWarn {
+				t.Fatalf("warnings = %q, want a slow hook warning: %v", logger.warns, tt.wantWarn)
+			}
+			if tt.wantWarn {
+				if !strings.Contains(logger.warns[0], `"cache" was slow`) || !strings.Contains(logger.warns[0], "threshold 10ms") {
+					t.Errorf("warning %q doesn't name the hook and threshold", logger.warns[0])
+				}
+				return
+			}
+			if len(logger.infos) != 1 || !strings.Contains(logger.infos[0], `"cache" completed`) {
+				t.Errorf("info = %q, want the hook completion", logger.infos)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package lifecycle
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+)
+
+// SafeGo runs fn in a new goroutine, recovering and logging any panic so a
+// crash in background work doesn't take down the whole process. Recovered
+// panics are counted under the goroutine's name.
+func SafeGo(logger Logger, name string, fn func()) {
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				metrics.PanicsTotal.WithLabelValues(name).Inc()
+				logger.Error(fmt.Sprintf("Panic recovered in goroutine %q: %v\n%s", name, err, debug.Stack()))
+			}
+		}()
+		fn()
+	}()
+}
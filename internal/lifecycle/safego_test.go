@@ -0,0 +1,52 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSafeGoCountsPanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		fn        func()
+		wantPanic bool
+	}{
+		{name: "panicking goroutine", fn: func() { panic("boom") }, wantPanic: true},
+		{name: "returning goroutine", fn: func() {}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := "test-" + tt.name
+			before := testutil.ToFloat64(metrics.PanicsTotal.WithLabelValues(location))
+
+			done := make(chan struct{})
+			SafeGo(nopLogger{}, location, func() {
+				defer close(done)
+				tt.fn()
+			})
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("goroutine did not finish")
+			}
+			// The panic is recovered after fn's deferred close, so wait for it
+			want := 0.0
+			if tt.wantPanic {
+				want = 1
+			}
+			deadline := time.Now().Add(time.Second)
+			delta := testutil.ToFloat64(metrics.PanicsTotal.WithLabelValues(location)) - before
+			for delta != want && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+				delta = testutil.ToFloat64(metrics.PanicsTotal.WithLabelValues(location)) - before
+			}
+			if delta != want {
+				t.Fatalf("dahlia_panics_total{location=%q} grew by %v, want %v", location, delta, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StartFunc initializes a subsystem. The returned stop function, which may be
+// nil, undoes the initialization.
+type StartFunc func(ctx context.Context) (stop HookFunc, err error)
+
+// Step is a named subsystem init step. Its stop function becomes a shutdown
+// hook at Priority once the whole startup sequence succeeds.
+type Step struct {
+	Name     string
+	Priority Priority
+	Start    StartFunc
+}
+
+type started struct {
+	step Step
+	stop HookFunc
+}
+
+// Start runs the steps in order, logging the duration of each. If a step
+// fails, the steps that already started are stopped in reverse order and the
+// failure is returned, so the process never runs half-initialized. On success
+// every stop function is registered as a shutdown hook.
+func (m *Manager) Start(ctx context.Context, steps ...Step) error {
+	begin := time.Now()
+	done := make([]started, 0, len(steps))
+
+	for _, step := range steps {
+		m.logger.Debug(fmt.Sprintf("Starting %s", step.Name))
+		stepStart := time.Now()
+
+		stop, err := step.Start(ctx)
+		if err != nil {
+			m.logger.Error(fmt.Sprintf("Startup step %q failed after %s: %v", step.Name, time.Since(stepStart), err))
+			return errors.Join(fmt.Errorf("%s: %w", step.Name, err), m.rollback(ctx, done))
+		}
+		m.logger.Info(fmt.Sprintf("Started %s in %s", step.Name, time.Since(stepStart).Round(time.Microsecond)))
+		done = append(done, started{step, stop})
+	}
+
+	for _, s := range done {
+		if s.stop != nil {
+			m.OnShutdown(s.step.Name, s.step.Priority, s.stop)
+		}
+	}
+	m.logger.Debug(fmt.Sprintf("Startup sequence of %d steps completed in %s", len(steps), time.Since(begin)))
+	return nil
+}
+
+// rollback stops already started steps, most recent first
+func (m *Manager) rollback(ctx context.Context, done []started) error {
+	var errs []error
+	for i := len(done) - 1; i >= 0; i-- {
+		s := done[i]
+		if s.stop == nil {
+			continue
+		}
+		m.logger.Warn(fmt.Sprintf("Rolling back %s", s.step.Name))
+		if err := s.stop(ctx); err != nil {
+			m.logger.Error(fmt.Sprintf("Rollback of %s failed: %v", s.step.Name, err))
+			errs = append(errs, fmt.Errorf("rollback %s: %w", s.step.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
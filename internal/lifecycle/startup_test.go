@@ -0,0 +1,83 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	failure := errors.New("connection refused")
+	rollbackFailure := errors.New("close failed")
+
+	tests := []struct {
+		name         string
+		fail         string
+		failRollback string
+		wantStopped  []string
+		wantErr      []error
+	}{
+		{
+			name:        "all steps start",
+			wantStopped: []string{"server", "cache", "database"},
+		},
+		{
+			name:        "failing step rolls back earlier steps in reverse",
+			fail:        "server",
+			wantStopped: []string{"cache", "database"},
+			wantErr:     []error{failure},
+		},
+		{
+			name:    "first step failing rolls back nothing",
+			fail:    "database",
+			wantErr: []error{failure},
+		},
+		{
+			name:         "rollback failure is reported too",
+			fail:         "server",
+			failRollback: "cache",
+			wantStopped:  []string{"cache", "database"},
+			wantErr:      []error{failure, rollbackFailure},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(nopLogger{})
+			var r recorder
+			step := func(name string) Step {
+				return Step{Name: name, Priority: PriorityResources, Start: func(context.Context) (HookFunc, error) {
+					if name == tt.fail {
+						return nil, failure
+					}
+					var err error
+					if name == tt.failRollback {
+						err = rollbackFailure
+					}
+					return r.hook(name, err), nil
+				}}
+			}
+
+			err := m.Start(context.Background(), step("database"), step("cache"), step("server"))
+			for _, want := range tt.wantErr {
+				if !errors.Is(err, want) {
+					t.Fatalf("Start() = %v, want it to report %v", err, want)
+				}
+			}
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Start() = %v", err)
+				}
+				// Started steps stop at shutdown instead, as hooks
+				if len(r.names) != 0 {
+					t.Fatalf("steps %v were stopped during a successful start", r.names)
+				}
+				m.Shutdown(context.Background())
+			}
+			if !slices.Equal(r.names, tt.wantStopped) {
+				t.Fatalf("stopped %v, want %v", r.names, tt.wantStopped)
+			}
+		})
+	}
+}
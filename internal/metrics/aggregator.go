@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Aggregator collects raw request observations and periodically folds them
+// into summary gauges, keeping the computation off the scrape path
+type Aggregator struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	interval  time.Duration
+}
+
+// defaultAggregationInterval is used when no positive interval is configured
+const defaultAggregationInterval = 15 * time.Second
+
+// NewAggregator creates an aggregator that summarizes every interval
+func NewAggregator(interval time.Duration) *Aggregator {
+	if interval <= 0 {
+		interval = defaultAggregationInterval
+	}
+	return &Aggregator{
+		interval: interval,
+	}
+}
+
+// Observe records a completed request
+func (a *Aggregator) Observe(status int, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.latencies = append(a.latencies, latency)
+	if status >= 500 {
+		a.errors++
+	}
+}
+
+// Run aggregates observations every interval until ctx is cancelled
+func (a *Aggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.aggregate()
+		}
+	}
+}
+
+// aggregate updates the summary gauges from the current window and starts a
+// new one. An empty window leaves the gauges at zero.
+func (a *Aggregator) aggregate() {
+	a.mu.Lock()
+	latencies, errors := a.latencies, a.errors
+	a.latencies, a.errors = nil, 0
+	a.mu.Unlock()
+
+	if len(latencies) == 0 {
+		ErrorRate.Set(0)
+		LatencyP99.Set(0)
+		return
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	idx := (len(latencies)*99 + 99) / 100
+	if idx > len(latencies) {
+		idx = len(latencies)
+	}
+
+	ErrorRate.Set(float64(errors) / float64(len(latencies)))
+	LatencyP99.Set(latencies[idx-1].Seconds())
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name     string
+		observe  func(a *Aggregator)
+		wantRate float64
+		wantP99  float64
+	}{
+		{
+			name: "hundred requests",
+			observe: func(a *Aggregator) {
+				for i := 1; i <= 100; i++ {
+					status := 200
+					if i%20 == 0 {
+						status = 503
+					}
+					a.Observe(status, time.Duration(i)*time.Millisecond)
+				}
+			},
+			wantRate: 0.05,
+			wantP99:  0.099,
+		},
+		{
+			name: "unsorted input",
+			observe: func(a *Aggregator) {
+				for _, ms := range []int{30, 10, 20} {
+					a.Observe(200, time.Duration(ms)*time.Millisecond)
+				}
+			},
+			wantP99: 0.03,
+		},
+		{
+			name: "single failing request",
+			observe: func(a *Aggregator) {
+				a.Observe(500, 250*time.Millisecond)
+			},
+			wantRate: 1,
+			wantP99:  0.25,
+		},
+		{
+			name:    "client errors are not failures",
+			observe: func(a *Aggregator) { a.Observe(404, time.Second) },
+			wantP99: 1,
+		},
+		{
+			name:    "empty window",
+			observe: func(a *Aggregator) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ErrorRate.Set(-1)
+			LatencyP99.Set(-1)
+
+			a := NewAggregator(time.Minute)
+			tt.observe(a)
+			a.aggregate()
+
+			if got := testutil.ToFloat64(ErrorRate); got != tt.wantRate {
+				t.Errorf("error rate = %v, want %v", got, tt.wantRate)
+			}
+			if got := testutil.ToFloat64(LatencyP99); got != tt.wantP99 {
+				t.Errorf("p99 latency = %v, want %v", got, tt.wantP99)
+			}
+		})
+	}
+}
+
+func TestAggregateStartsNewWindow(t *testing.T) {
+	a := NewAggregator(time.Minute)
+	a.Observe(500, time.Second)
+	a.aggregate()
+	a.aggregate()
+
+	if got := testutil.ToFloat64(ErrorRate); got != 0 {
+		t.Errorf("error rate after empty window = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(LatencyP99); got != 0 {
+		t.Errorf("p99 latency after empty window = %v, want 0", got)
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// GoroutineSampler periodically records the number of goroutines so a slow
+// leak shows up as a steadily climbing gauge long before it exhausts memory
+type GoroutineSampler struct {
+	interval  time.Duration
+	threshold int
+	logger    Logger
+
+	// count returns the current goroutine count; runtime.NumGoroutine by default
+	count func() int
+}
+
+// NewGoroutineSampler creates a sampler recording the goroutine count every
+// interval, logging it at DEBUG and escalating to WARN above threshold. A
+// threshold of zero never warns.
+func NewGoroutineSampler(interval time.Duration, threshold int, logger Logger) *GoroutineSampler {
+	return &GoroutineSampler{
+		interval:  interval,
+		threshold: threshold,
+		logger:    logger,
+		count:     runtime.NumGoroutine,
+	}
+}
+
+// Run samples the goroutine count every interval until ctx is cancelled
+func (s *GoroutineSampler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sample()
+		}
+	}
+}
+
+func (s *GoroutineSampler) sample() {
+	n := s.count()
+	Goroutines.Set(float64(n))
+	if s.threshold > 0 && n > s.threshold {
+		s.logger.Warn(fmt.Sprintf("Goroutine count %d exceeds threshold %d", n, s.threshold))
+		return
+	}
+	s.logger.Debug(fmt.Sprintf("Goroutine count %d", n))
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGoroutineSamplerSample(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold int
+		count     int
+		wantWarn  bool
+	}{
+		{name: "under the threshold", threshold: 100, count: 40},
+		{name: "at the threshold", threshold: 100, count: 100},
+		{name: "over the threshold", threshold: 100, count: 250, wantWarn: true},
+		{name: "no threshold", count: 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			s := NewGoroutineSampler(time.Second, tt.threshold, logger)
+			s.count = func() int { return tt.count }
+			s.sample()
+
+			if got := testutil.ToFloat64(Goroutines); got != float64(tt.count) {
+				t.Errorf("goroutines gauge = %v, want %d", got, tt.count)
+			}
+			if tt.wantWarn {
+				if len(logger.warns) != 1 || !strings.Contains(logger.warns[0], "Goroutine count 250 exceeds threshold 100") {
+					t.Errorf("warnings = %q, want one naming the count and threshold", logger.warns)
+				}
+				if len(logger.debug) != 0 {
+					t.Errorf("debug = %q, want the count only at WARN", logger.debug)
+				}
+				return
+			}
+			if len(logger.warns) != 0 {
+				t.Errorf("warnings = %q, want none", logger.warns)
+			}
+			if len(logger.debug) != 1 {
+				t.Errorf("debug = %q, want the count logged once", logger.debug)
+			}
+		})
+	}
+}
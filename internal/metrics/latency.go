@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyWindow keeps the most recent latencies of each dependency, such as
+// health checks and upstream calls, for an on-demand percentile snapshot
+type LatencyWindow struct {
+	size int
+
+	mu      sync.Mutex
+	samples map[string]*latencyRing
+}
+
+// latencyRing holds up to size samples, overwriting the oldest once full
+type latencyRing struct {
+	values []time.Duration
+	next   int
+}
+
+// LatencySummary reports percentiles over a dependency's recent samples
+type LatencySummary struct {
+	Samples int     `json:"samples"`
+	P50Ms   float64 `json:"p50_ms"`
+	P95Ms   float64 `json:"p95_ms"`
+	P99Ms   float64 `json:"p99_ms"`
+}
+
+// defaultLatencyWindowSize is used when no positive size is configured
+const defaultLatencyWindowSize = 1000
+
+// NewLatencyWindow keeps the last size samples per dependency
+func NewLatencyWindow(size int) *LatencyWindow {
+	if size <= 0 {
+		size = defaultLatencyWindowSize
+	}
+	return &LatencyWindow{
+		size:    size,
+		samples: make(map[string]*latencyRing),
+	}
+}
+
+// Record adds a latency sample for the named dependency
+func (w *LatencyWindow) Record(name string, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r, ok := w.samples[name]
+	if !ok {
+		r = &latencyRing{values: make([]time.Duration, 0, w.size)}
+		w.samples[name] = r
+	}
+	if len(r.values) < w.size {
+		r.values = append(r.values, d)
+		return
+	}
+	r.values[r.next] = d
+	r.next = (r.next + 1) % w.size
+}
+
+// Summaries returns p50, p95 and p99 for every dependency with samples
+func (w *LatencyWindow) Summaries() map[string]LatencySummary {
+	w.mu.Lock()
+	snapshot := make(map[string][]time.Duration, len(w.samples))
+	for name, r := range w.samples {
+		snapshot[name] = append([]time.Duration(nil), r.values...)
+	}
+	w.mu.Unlock()
+
+	summaries := make(map[string]LatencySummary, len(snapshot))
+	for name, values := range snapshot {
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		summaries[name] = LatencySummary{
+			Samples: len(values),
+			P50Ms:   percentile(values, 50),
+			P95Ms:   percentile(values, 95),
+			P99Ms:   percentile(values, 99),
+		}
+	}
+	return summaries
+}
+
+// percentile returns the nearest-rank pth percentile of sorted values in
+// milliseconds
+func percentile(sorted []time.Duration, p int) float64 {
+	idx := (len(sorted)*p + 99) / 100
+	idx = min(max(idx, 1), len(sorted))
+	return float64(sorted[idx-1]) / float64(time.Millisecond)
+}
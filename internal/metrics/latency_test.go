@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyWindowPercentiles(t *testing.T) {
+	w := NewLatencyWindow(0)
+	for i := 100; i >= 1; i-- {
+		w.Record("database", time.Duration(i)*time.Millisecond)
+	}
+	w.Record("cache", 3*time.Millisecond)
+
+	got := w.Summaries()
+	want := map[string]LatencySummary{
+		"database": {Samples: 100, P50Ms: 50, P95Ms: 95, P99Ms: 99},
+		"cache":    {Samples: 1, P50Ms: 3, P95Ms: 3, P99Ms: 3},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("summaries = %+v, want %+v", got, want)
+	}
+	for name, s := range want {
+		if got[name] != s {
+			t.Errorf("%s = %+v, want %+v", name, got[name], s)
+		}
+	}
+}
+
+func TestLatencyWindowKeepsRecentSamples(t *testing.T) {
+	w := NewLatencyWindow(4)
+	// the slow samples are overwritten by the later fast ones
+	for _, ms := range []int{900, 800, 1, 2, 3, 4} {
+		w.Record("upstream", time.Duration(ms)*time.Millisecond)
+	}
+
+	got := w.Summaries()["upstream"]
+	if want := (LatencySummary{Samples: 4, P50Ms: 2, P95Ms: 4, P99Ms: 4}); got != want {
+		t.Errorf("summary = %+v, want %+v", got, want)
+	}
+}
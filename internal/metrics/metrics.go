@@ -0,0 +1,323 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DefaultNamespace prefixes metric names unless configured otherwise
+const DefaultNamespace = "dahlia"
+
+// Registry holds all metrics exposed on /metrics
+var Registry *prometheus.Registry
+
+var startTime = time.Now()
+
+// resettable holds the counters and histograms created by Init, which Reset
+// zeroes in place
+var resettable []interface{ Reset() }
+
+// DefaultLatencyBuckets are the request duration histogram buckets in seconds
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// nameRe matches valid Prometheus metric name components
+var nameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var (
+	// Uptime reports how long the process has been running
+	Uptime prometheus.CounterFunc
+
+	// PanicsTotal counts recovered panics by the handler route or goroutine
+	// name they occurred in
+	PanicsTotal *prometheus.CounterVec
+
+	// HandlerCancelledTotal counts handlers whose request context was
+	// cancelled before they completed, by route and cancellation reason
+	HandlerCancelledTotal *prometheus.CounterVec
+
+	// InFlightRequests is the number of requests currently being served
+	InFlightRequests prometheus.Gauge
+
+	// LoadShedTotal counts requests rejected by the load shedder
+	LoadShedTotal prometheus.Counter
+
+	// RequestQueueDepth is the number of requests waiting for a concurrency slot
+	RequestQueueDepth prometheus.Gauge
+
+	// SSESlowConsumersTotal counts SSE clients dropped for not keeping up
+	SSESlowConsumersTotal prometheus.Counter
+
+	// StartupDuration records how long the process took to become ready to serve
+	StartupDuration prometheus.Gauge
+
+	// ErrorRate is the fraction of requests answered with a 5xx status during
+	// the last aggregation window
+	ErrorRate prometheus.Gauge
+
+	// LatencyP99 is the 99th percentile request latency during the last
+	// aggregation window
+	LatencyP99 prometheus.Gauge
+
+	// LogsDroppedTotal counts log lines discarded by a full log buffer
+	LogsDroppedTotal prometheus.Counter
+
+	// LogsSuppressedTotal counts log lines dropped by per-level rate limits
+	LogsSuppressedTotal *prometheus.CounterVec
+
+	// SchedulerLag is the delay between a scheduled wakeup and the probe
+	// goroutine actually running
+	SchedulerLag prometheus.Gauge
+
+	// Goroutines is the goroutine count at the last sample
+	Goroutines prometheus.Gauge
+
+	// RequestsTotal counts handled requests by method, route and status
+	RequestsTotal *prometheus.CounterVec
+
+	// RequestDuration is a histogram of request latency by method, route and status
+	RequestDuration *prometheus.HistogramVec
+
+	// UpstreamRequestDuration is a histogram of outbound call latency by
+	// upstream and outcome (status code, timeout, cancelled or error)
+	UpstreamRequestDuration *prometheus.HistogramVec
+
+	// RateLimitBuckets is the number of per-client rate limit buckets held in memory
+	RateLimitBuckets prometheus.Gauge
+
+	// RateLimitedTotal counts requests rejected by the rate limiter
+	RateLimitedTotal prometheus.Counter
+
+	// AuthFailuresTotal counts rejected authentication attempts by reason
+	AuthFailuresTotal *prometheus.CounterVec
+
+	// SlowMiddlewareTotal counts requests in which a middleware's own time
+	// exceeded the middleware timing threshold, by middleware
+	SlowMiddlewareTotal *prometheus.CounterVec
+
+	// ForcedConnectionClosesTotal counts connections still open when the
+	// shutdown drain timed out, which were closed forcibly
+	ForcedConnectionClosesTotal prometheus.Counter
+)
+
+func init() {
+	if err := Init(DefaultNamespace, "", nil); err != nil {
+		panic(err)
+	}
+}
+
+// Init creates every metric under the given namespace and optional subsystem
+// in a fresh Registry, so names become namespace_subsystem_name. Request
+// latency uses the given histogram buckets, or DefaultLatencyBuckets when
+// none are given. It must be called before the server starts handling traffic.
+func Init(namespace, subsystem string, latencyBuckets []float64) error {
+	if !nameRe.MatchString(namespace) {
+		return fmt.Errorf("invalid metrics namespace %q", namespace)
+	}
+	if subsystem != "" && !nameRe.MatchString(subsystem) {
+		return fmt.Errorf("invalid metrics subsystem %q", subsystem)
+	}
+
+	if len(latencyBuckets) == 0 {
+		latencyBuckets = DefaultLatencyBuckets
+	}
+	for i, b := range latencyBuckets {
+		if b <= 0 {
+			return fmt.Errorf("latency bucket %g must be positive", b)
+		}
+		if i > 0 && b <= latencyBuckets[i-1] {
+			return fmt.Errorf("latency buckets must be ascending, got %g after %g", b, latencyBuckets[i-1])
+		}
+	}
+
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		}
+	}
+
+	Uptime = prometheus.NewCounterFunc(prometheus.CounterOpts(opts(
+		"uptime_seconds", "Uptime in seconds",
+	)), func() float64 {
+		return time.Since(startTime).Seconds()
+	})
+	InFlightRequests = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"http_in_flight_requests", "Number of requests currently being served",
+	)))
+	RequestQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"request_queue_depth", "Number of requests waiting for a concurrency slot",
+	)))
+	StartupDuration = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"startup_duration_seconds", "Time taken from process start to serving traffic",
+	)))
+	ErrorRate = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"error_rate", "Fraction of 5xx responses in the last aggregation window",
+	)))
+	LatencyP99 = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"latency_p99_seconds", "99th percentile request latency in the last aggregation window",
+	)))
+	SchedulerLag = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"scheduler_lag_seconds", "Delay between a scheduled goroutine wakeup and it running",
+	)))
+	Goroutines = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"goroutines", "Number of goroutines at the last sample",
+	)))
+	RateLimitBuckets = prometheus.NewGauge(prometheus.GaugeOpts(opts(
+		"rate_limit_buckets", "Number of per-client rate limit buckets in memory",
+	)))
+	newResettable(opts, latencyBuckets)
+	Registry = newRegistry()
+	return nil
+}
+
+// newResettable creates the counters and histograms, the metrics that Reset
+// zeroes
+func newResettable(opts func(name, help string) prometheus.Opts, latencyBuckets []float64) {
+	var counters []interface{ Reset() }
+	counter := func(name, help string) prometheus.Counter {
+		c := newResettableCounter(prometheus.CounterOpts(opts(name, help)))
+		counters = append(counters, c)
+		return c
+	}
+	histogramOpts := func(name, help string) prometheus.HistogramOpts {
+		o := opts(name, help)
+		return prometheus.HistogramOpts{
+			Namespace: o.Namespace,
+			Subsystem: o.Subsystem,
+			Name:      o.Name,
+			Help:      o.Help,
+			Buckets:   latencyBuckets,
+		}
+	}
+
+	PanicsTotal = prometheus.NewCounterVec(prometheus.CounterOpts(opts(
+		"panics_total", "Total recovered panics",
+	)), []string{"location"})
+	HandlerCancelledTotal = prometheus.NewCounterVec(prometheus.CounterOpts(opts(
+		"handler_cancelled_total", "Total handlers cancelled before completion",
+	)), []string{"route", "reason"})
+	LoadShedTotal = counter("load_shed_total", "Total requests rejected due to overload")
+	SSESlowConsumersTotal = counter("sse_slow_consumers_dropped_total", "Total SSE connections dropped because the client could not keep up")
+	LogsDroppedTotal = counter("logs_dropped_total", "Total log lines dropped because the log buffer was full")
+	LogsSuppressedTotal = prometheus.NewCounterVec(prometheus.CounterOpts(opts(
+		"logs_suppressed_total", "Total log lines suppressed by per-level rate limits",
+	)), []string{"level"})
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts(opts(
+		"http_requests_total", "Total HTTP requests handled",
+	)), []string{"method", "route", "status"})
+	RequestDuration = prometheus.NewHistogramVec(histogramOpts(
+		"http_request_duration_seconds", "HTTP request latency in seconds",
+	), []string{"method", "route", "status"})
+	UpstreamRequestDuration = prometheus.NewHistogramVec(histogramOpts(
+		"upstream_request_duration_seconds", "Outbound upstream call latency in seconds",
+	), []string{"upstream", "outcome"})
+	RateLimitedTotal = counter("rate_limited_total", "Total requests rejected by the rate limiter")
+	AuthFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts(opts(
+		"auth_failures_total", "Total rejected authentication attempts",
+	)), []string{"reason"})
+	SlowMiddlewareTotal = prometheus.NewCounterVec(prometheus.CounterOpts(opts(
+		"slow_middleware_total", "Total requests in which a middleware exceeded the timing threshold",
+	)), []string{"middleware"})
+	ForcedConnectionClosesTotal = counter("forced_connection_closes_total", "Total connections closed forcibly when the shutdown drain timed out")
+
+	resettable = append(counters,
+		PanicsTotal,
+		HandlerCancelledTotal,
+		LogsSuppressedTotal,
+		RequestsTotal,
+		RequestDuration,
+		UpstreamRequestDuration,
+		AuthFailuresTotal,
+		SlowMiddlewareTotal,
+	)
+}
+
+// newRegistry registers every metric in a fresh registry
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		Uptime,
+		PanicsTotal,
+		HandlerCancelledTotal,
+		InFlightRequests,
+		LoadShedTotal,
+		RequestQueueDepth,
+		SSESlowConsumersTotal,
+		StartupDuration,
+		ErrorRate,
+		LatencyP99,
+		LogsDroppedTotal,
+		LogsSuppressedTotal,
+		SchedulerLag,
+		Goroutines,
+		RequestsTotal,
+		RequestDuration,
+		UpstreamRequestDuration,
+		RateLimitBuckets,
+		RateLimitedTotal,
+		AuthFailuresTotal,
+		SlowMiddlewareTotal,
+		ForcedConnectionClosesTotal,
+	)
+	return registry
+}
+
+// Reset zeroes every counter and histogram in place, so the Registry and the
+// collectors callers hold stay valid and it is safe while traffic is served.
+// Gauges, which describe current state rather than history, and uptime carry
+// over. This destroys the recorded history, so it is meant for tests and
+// controlled measurement windows only; scrapers see the counters restart as
+// after a process restart.
+func Reset() {
+	for _, m := range resettable {
+		m.Reset()
+	}
+}
+
+// resettableCounter is a counter that Reset can zero; the counter it
+// delegates to is swapped atomically, so increments never race the reset
+type resettableCounter struct {
+	opts    prometheus.CounterOpts
+	current atomic.Pointer[prometheus.Counter]
+}
+
+func newResettableCounter(opts prometheus.CounterOpts) *resettableCounter {
+	c := &resettableCounter{opts: opts}
+	c.Reset()
+	return c
+}
+
+func (c *resettableCounter) counter() prometheus.Counter { return *c.current.Load() }
+
+// Reset replaces the counter with a fresh one at zero
+func (c *resettableCounter) Reset() {
+	counter := prometheus.NewCounter(c.opts)
+	c.current.Store(&counter)
+}
+
+func (c *resettableCounter) Desc() *prometheus.Desc              { return c.counter().Desc() }
+func (c *resettableCounter) Write(m *dto.Metric) error           { return c.counter().Write(m) }
+func (c *resettableCounter) Describe(ch chan<- *prometheus.Desc) { c.counter().Describe(ch) }
+func (c *resettableCounter) Collect(ch chan<- prometheus.Metric) { c.counter().Collect(ch) }
+func (c *resettableCounter) Inc()                                { c.counter().Inc() }
+func (c *resettableCounter) Add(v float64)                       { c.counter().Add(v) }
+
+// Handler returns an HTTP handler serving the registry in Prometheus format.
+// It encodes each metric family straight to the response as it is gathered,
+// gzipped when the scraper accepts it, and never sets Content-Length, so the
+// exposition goes out chunked rather than being built in memory first.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,258 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestReset(t *testing.T) {
+	LoadShedTotal.Inc()
+	RequestsTotal.WithLabelValues("GET", "/reset", "200").Inc()
+	RequestDuration.WithLabelValues("GET", "/reset", "200").Observe(0.1)
+	InFlightRequests.Set(3)
+
+	Reset()
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"plain counter", testutil.ToFloat64(LoadShedTotal), 0},
+		{"counter vector", float64(testutil.CollectAndCount(RequestsTotal)), 0},
+		{"histogram vector", float64(testutil.CollectAndCount(RequestDuration)), 0},
+		{"gauge carries over", testutil.ToFloat64(InFlightRequests), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %g, want %g", tt.got, tt.want)
+			}
+		})
+	}
+	InFlightRequests.Set(0)
+}
+
+func TestResetKeepsRegistryCollecting(t *testing.T) {
+	registry := Registry
+	Reset()
+	if Registry != registry {
+		t.Fatal("Reset replaced the Registry")
+	}
+
+	LoadShedTotal.Inc()
+	if got := testutil.ToFloat64(LoadShedTotal); got != 1 {
+		t.Errorf("load shed total after reset = %g, want 1", got)
+	}
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() == DefaultNamespace+"_load_shed_total" {
+			if got := f.GetMetric()[0].GetCounter().GetValue(); got != 1 {
+				t.Errorf("gathered load shed total = %g, want 1", got)
+			}
+			return
+		}
+	}
+	t.Error("load shed total not gathered after reset")
+}
+
+func TestResetConcurrentWithUpdates(t *testing.T) {
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					LoadShedTotal.Inc()
+					RequestsTotal.WithLabelValues("GET", "/concurrent", "200").Inc()
+				}
+			}
+		}()
+	}
+	for range 20 {
+		Reset()
+		if _, err := Registry.Gather(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// countingWriter records how many writes a handler makes
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	writes int
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	w.writes++
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestHandlerStreams(t *testing.T) {
+	w := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	// One write per metric family rather than one for the whole exposition
+	if w.writes < 2 {
+		t.Errorf("handler made %d writes, want the exposition written incrementally", w.writes)
+	}
+	if cl := w.Header().Get("Content-Length"); cl != "" {
+		t.Errorf("Content-Length = %q, want none on a streamed exposition", cl)
+	}
+}
+
+// gatheredNames returns the metric family names in Registry
+func gatheredNames(t *testing.T) map[string]bool {
+	t.Helper()
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	return names
+}
+
+func TestInitNames(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Init(DefaultNamespace, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		namespace string
+		subsystem string
+		prefix    string
+	}{
+		{name: "default namespace", namespace: DefaultNamespace, prefix: "dahlia_"},
+		{name: "custom namespace", namespace: "shop", prefix: "shop_"},
+		{name: "namespace and subsystem", namespace: "shop", subsystem: "api", prefix: "shop_api_"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Init(tt.namespace, tt.subsystem, nil); err != nil {
+				t.Fatal(err)
+			}
+			RequestsTotal.WithLabelValues("GET", "/", "200").Inc()
+			LoadShedTotal.Inc()
+
+			names := gatheredNames(t)
+			for _, name := range []string{"uptime_seconds", "http_in_flight_requests", "load_shed_total", "http_requests_total"} {
+				if !names[tt.prefix+name] {
+					t.Errorf("%s%s not exposed", tt.prefix, name)
+				}
+			}
+			if tt.prefix != "dahlia_" && names["dahlia_uptime_seconds"] {
+				t.Error("default-namespace metric still exposed")
+			}
+		})
+	}
+}
+
+func TestInitRejectsInvalidNames(t *testing.T) {
+	registry := Registry
+	for _, tc := range []struct{ namespace, subsystem string }{
+		{"", ""},
+		{"my-app", ""},
+		{"1app", ""},
+		{"app", "api v1"},
+	} {
+		if err := Init(tc.namespace, tc.subsystem, nil); err == nil {
+			t.Errorf("Init(%q, %q) succeeded, want an error", tc.namespace, tc.subsystem)
+		}
+	}
+	if Registry != registry {
+		t.Error("a rejected Init replaced the Registry")
+	}
+}
+
+// histogramBounds returns the bucket upper bounds of the named histogram in
+// Registry
+func histogramBounds(t *testing.T, name string) []float64 {
+	t.Helper()
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		var bounds []float64
+		for _, b := range f.GetMetric()[0].GetHistogram().GetBucket() {
+			bounds = append(bounds, b.GetUpperBound())
+		}
+		return bounds
+	}
+	t.Fatalf("%s not gathered", name)
+	return nil
+}
+
+func TestInitLatencyBuckets(t *testing.T) {
+	t.Cleanup(func() {
+		if err := Init(DefaultNamespace, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		buckets []float64
+		want    []float64
+	}{
+		{name: "default", want: DefaultLatencyBuckets},
+		{name: "custom", buckets: []float64{0.1, 0.5, 2}, want: []float64{0.1, 0.5, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Init(DefaultNamespace, "", tt.buckets); err != nil {
+				t.Fatal(err)
+			}
+			RequestDuration.WithLabelValues("GET", "/", "200").Observe(0.2)
+			UpstreamRequestDuration.WithLabelValues("billing", "200").Observe(0.2)
+
+			for _, name := range []string{"dahlia_http_request_duration_seconds", "dahlia_upstream_request_duration_seconds"} {
+				if got := histogramBounds(t, name); !slices.Equal(got, tt.want) {
+					t.Errorf("%s buckets = %v, want %v", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestInitRejectsInvalidBuckets(t *testing.T) {
+	for _, buckets := range [][]float64{
+		{0, 1},
+		{-0.5, 1},
+		{1, 0.5},
+		{0.5, 0.5},
+	} {
+		if err := Init(DefaultNamespace, "", buckets); err == nil {
+			t.Errorf("Init with buckets %v succeeded, want an error", buckets)
+		}
+	}
+}
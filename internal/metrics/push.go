@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+// Pusher periodically pushes the registry to a Prometheus Pushgateway,
+// grouped by job and instance hostname, for deployments that can't be
+// scraped
+type Pusher struct {
+	pusher   *push.Pusher
+	interval time.Duration
+	// deleteOnShutdown removes this instance's group from the gateway after
+	// the final push, so it doesn't linger as stale series
+	deleteOnShutdown bool
+	logger           Logger
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+// NewPusher creates a pusher sending the registry to the gateway at url
+// every interval
+func NewPusher(url, job string, interval time.Duration, deleteOnShutdown bool, logger Logger) *Pusher {
+	p := push.New(url, job).Gatherer(Registry)
+	if host, err := os.Hostname(); err == nil {
+		p = p.Grouping("instance", host)
+	}
+	return &Pusher{
+		pusher:           p,
+		interval:         interval,
+		deleteOnShutdown: deleteOnShutdown,
+		logger:           logger,
+	}
+}
+
+// Run pushes every interval until ctx is cancelled or Shutdown is called
+func (p *Pusher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.push(ctx)
+		}
+	}
+}
+
+func (p *Pusher) push(ctx context.Context) {
+	// Holding the lock keeps a periodic push from racing with, and
+	// recreating the group after, the shutdown deletion
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	if err := p.pusher.PushContext(ctx); err != nil {
+		p.logger.Warn(fmt.Sprintf("Metrics push failed: %v", err))
+	}
+}
+
+// Shutdown stops periodic pushes, pushes a final snapshot and, when
+// configured, deletes the instance's metrics from the gateway
+func (p *Pusher) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+
+	if err := p.pusher.PushContext(ctx); err != nil {
+		return fmt.Errorf("final metrics push: %w", err)
+	}
+	p.logger.Info("Final metrics push succeeded")
+
+	if !p.deleteOnShutdown {
+		return nil
+	}
+	if err := p.pusher.Delete(); err != nil {
+		return fmt.Errorf("delete pushed metrics: %w", err)
+	}
+	p.logger.Info("Deleted pushed metrics from the gateway")
+	return nil
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeGateway records the method and path of every Pushgateway request
+type fakeGateway struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (g *fakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.requests = append(g.requests, r.Method+" "+r.URL.Path)
+	// The Pushgateway answers deletions with 202
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *fakeGateway) seen() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.requests)
+}
+
+func TestPusherShutdown(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skipf("no hostname: %v", err)
+	}
+	group := "/metrics/job/dahlia/instance/" + host
+
+	tests := []struct {
+		name             string
+		deleteOnShutdown bool
+		want             []string
+	}{
+		{name: "final push", want: []string{"PUT " + group}},
+		{name: "final push and delete", deleteOnShutdown: true, want: []string{"PUT " + group, "DELETE " + group}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &fakeGateway{}
+			srv := httptest.NewServer(gateway)
+			defer srv.Close()
+
+			p := NewPusher(srv.URL, "dahlia", time.Hour, tt.deleteOnShutdown, &recordingLogger{})
+			if err := p.Shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			// Periodic pushes stop once shut down
+			p.push(context.Background())
+
+			if got := gateway.seen(); !slices.Equal(got, tt.want) {
+				t.Errorf("gateway requests = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPusherShutdownReportsFailedPush(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := NewPusher(srv.URL, "dahlia", time.Hour, true, &recordingLogger{})
+	if err := p.Shutdown(context.Background()); err == nil {
+		t.Error("Shutdown succeeded with the gateway failing")
+	}
+}
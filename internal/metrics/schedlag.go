@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Logger interface for dependency injection
+type Logger interface {
+	Info(msg string)
+	Warn(msg string)
+	Debug(msg string)
+}
+
+// SchedulerProbe measures Go scheduler latency: how much later than
+// requested a sleeping goroutine actually runs. Sustained lag points to CPU
+// starvation, long GC pauses or goroutine overload, all of which add latency
+// to every request.
+type SchedulerProbe struct {
+	interval  time.Duration
+	threshold time.Duration
+	logger    Logger
+}
+
+// NewSchedulerProbe creates a probe waking every interval and warning when
+// the lag exceeds threshold
+func NewSchedulerProbe(interval, threshold time.Duration, logger Logger) *SchedulerProbe {
+	return &SchedulerProbe{
+		interval:  interval,
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+// Run measures scheduler lag every interval until ctx is cancelled
+func (p *SchedulerProbe) Run(ctx context.Context) {
+	timer := time.NewTimer(p.interval)
+	defer timer.Stop()
+
+	for {
+		expected := time.Now().Add(p.interval)
+		timer.Reset(p.interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case woke := <-timer.C:
+			p.record(woke.Sub(expected))
+		}
+	}
+}
+
+func (p *SchedulerProbe) record(lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	SchedulerLag.Set(lag.Seconds())
+	if p.threshold > 0 && lag > p.threshold {
+		p.logger.Warn(fmt.Sprintf("Scheduler lag %s exceeds threshold %s", lag.Round(time.Microsecond), p.threshold))
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// recordingLogger keeps warnings and debug messages
+type recordingLogger struct {
+	mu    sync.Mutex
+	warns []string
+	debug []string
+}
+
+func (l *recordingLogger) Info(string) {}
+
+func (l *recordingLogger) Warn(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, msg)
+}
+
+func (l *recordingLogger) Debug(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debug = append(l.debug, msg)
+}
+
+func TestSchedulerProbeRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		lag      time.Duration
+		want     float64
+		wantWarn bool
+	}{
+		{name: "under the threshold", lag: 5 * time.Millisecond, want: 0.005},
+		{name: "over the threshold", lag: 150 * time.Millisecond, want: 0.15, wantWarn: true},
+		{name: "early wakeup", lag: -time.Millisecond, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &recordingLogger{}
+			p := NewSchedulerProbe(time.Second, 100*time.Millisecond, logger)
+			p.record(tt.lag)
+
+			if got := testutil.ToFloat64(SchedulerLag); got != tt.want {
+				t.Errorf("scheduler lag = %v, want %v", got, tt.want)
+			}
+			if got := len(logger.warns) == 1; got != tt.wantWarn {
+				t.Errorf("warnings = %q, want a warning: %v", logger.warns, tt.wantWarn)
+			}
+			if tt.wantWarn && !strings.Contains(logger.warns[0], "exceeds threshold 100ms") {
+				t.Errorf("warning %q doesn't name the threshold", logger.warns[0])
+			}
+		})
+	}
+}
+
+func TestSchedulerProbeRun(t *testing.T) {
+	SchedulerLag.Set(-1)
+	p := NewSchedulerProbe(5*time.Millisecond, 0, &recordingLogger{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(SchedulerLag) < 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Run didn't record the scheduler lag")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
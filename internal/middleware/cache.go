@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// cachedResponse is a captured handler response that can be replayed
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// responseStore is a TTL-bounded in-memory response cache
+type responseStore struct {
+	mu      sync.RWMutex
+	entries map[string]*cachedResponse
+}
+
+func (s *responseStore) get(key string, now time.Time) (*cachedResponse, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[key]
+	if !ok || now.After(entry.expires) {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *responseStore) set(key string, entry *cachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop expired entries opportunistically so the map doesn't grow forever
+	now := time.Now()
+	for k, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = entry
+}
+
+// captureWriter records the response while still writing it to the client
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// CachePolicy decides how a route's responses are cached
+type CachePolicy struct {
+	// TTL is how long a response is served from the cache
+	TTL time.Duration
+	// Vary names request headers whose values select separate entries
+	Vary []string
+	// Statuses are the response codes that are cached; empty means 200 only
+	Statuses []int
+}
+
+// cacheable reports whether a response with status is stored
+func (p CachePolicy) cacheable(status int) bool {
+	if len(p.Statuses) == 0 {
+		return status == http.StatusOK
+	}
+	for _, s := range p.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// CachePolicies maps Gin route templates (e.g. "/api/v1/info") to their
+// cache policy; routes without one are not cached
+type CachePolicies map[string]CachePolicy
+
+// ParseCachePolicies converts a mapping from route template to a policy
+// written as "<ttl>[;vary=<header>|...][;status=<code>|...]", e.g.
+// "30s;vary=Accept|Accept-Language;status=200|404"
+func ParseCachePolicies(mapping map[string]string) (CachePolicies, error) {
+	policies := make(CachePolicies, len(mapping))
+	for route, value := range mapping {
+		fields := strings.Split(value, ";")
+		ttl, err := time.ParseDuration(strings.TrimSpace(fields[0]))
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("cache policy for route %q must start with a positive TTL, got %q", route, value)
+		}
+
+		policy := CachePolicy{TTL: ttl}
+		for _, field := range fields[1:] {
+			name, list, ok := strings.Cut(strings.TrimSpace(field), "=")
+			if !ok {
+				return nil, fmt.Errorf("cache policy for route %q has malformed field %q", route, field)
+			}
+			items := strings.Split(list, "|")
+			switch strings.TrimSpace(name) {
+			case "vary":
+				for _, h := range items {
+					if h = strings.TrimSpace(h); h != "" {
+						policy.Vary = append(policy.Vary, http.CanonicalHeaderKey(h))
+					}
+				}
+			case "status":
+				for _, s := range items {
+					code, err := strconv.Atoi(strings.TrimSpace(s))
+					if err != nil || code < 100 || code > 599 {
+						return nil, fmt.Errorf("cache policy for route %q has invalid status %q", route, s)
+					}
+					policy.Statuses = append(policy.Statuses, code)
+				}
+			default:
+				return nil, fmt.Errorf("cache policy for route %q has unknown field %q (want vary or status)", route, name)
+			}
+		}
+		policies[route] = policy
+	}
+	return policies, nil
+}
+
+// Validate checks that every policy names a registered GET route template
+func (p CachePolicies) Validate(routes gin.RoutesInfo) error {
+	known := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		if r.Method == http.MethodGet {
+			known[r.Path] = true
+		}
+	}
+	for template := range p {
+		if !known[template] {
+			return fmt.Errorf("cache policy for unknown GET route %q", template)
+		}
+	}
+	return nil
+}
+
+// ResponseCache middleware caches successful GET responses for ttl and
+// coalesces concurrent identical requests so that only one of them runs the
+// handler while the others wait for and share its result. This protects
+// expensive endpoints from a stampede when a cache entry expires. Requests
+// carrying credentials are never cached, since their responses may be
+// specific to the caller, and neither are responses that set cookies.
+func ResponseCache(ttl time.Duration) gin.HandlerFunc {
+	policy := CachePolicy{TTL: ttl}
+	return responseCache(func(*gin.Context) (CachePolicy, bool) {
+		return policy, ttl > 0
+	})
+}
+
+// ResponseCacheWithPolicies is ResponseCache with the TTL, vary headers and
+// cacheable statuses chosen per route by policies. Routes without a policy
+// pass through uncached.
+func ResponseCacheWithPolicies(policies CachePolicies) gin.HandlerFunc {
+	return responseCache(func(c *gin.Context) (CachePolicy, bool) {
+		policy, ok := policies[c.FullPath()]
+		return policy, ok
+	})
+}
+
+func responseCache(policyFor func(*gin.Context) (CachePolicy, bool)) gin.HandlerFunc {
+	store := &responseStore{entries: make(map[string]*cachedResponse)}
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || c.GetHeader("Authorization") != "" || c.GetHeader("X-API-Key") != "" {
+			c.Next()
+			return
+		}
+		policy, ok := policyFor(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		vary := varyHeaders(policy.Vary)
+		key := cacheKey(c, vary)
+		if entry, ok := store.get(key, time.Now()); ok {
+			replay(c, entry)
+			return
+		}
+
+		leader := false
+		v, _, _ := group.Do(key, func() (interface{}, error) {
+			leader = true
+			// Added before the capture so replays carry it too
+			for _, h := range vary {
+				c.Writer.Header().Add("Vary", h)
+			}
+			w := &captureWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+
+			// The captured body is what the handler wrote, before any outer
+			// compression, so drop the encoding that compression added
+			header := w.Header().Clone()
+			header.Del("Content-Encoding")
+
+			// A cookie belongs to the caller it was issued to, so the response
+			// is neither stored nor shared with waiting requests
+			if len(header.Values("Set-Cookie")) > 0 {
+				return nil, nil
+			}
+
+			entry := &cachedResponse{
+				status:  w.Status(),
+				header:  header,
+				body:    w.body.Bytes(),
+				expires: time.Now().Add(policy.TTL),
+			}
+			if policy.cacheable(entry.status) {
+				store.set(key, entry)
+			}
+			return entry, nil
+		})
+
+		// The leader already wrote its response; followers replay the shared
+		// one, or run the handler themselves when it can't be shared
+		if leader {
+			return
+		}
+		if entry, ok := v.(*cachedResponse); ok {
+			replay(c, entry)
+			return
+		}
+		c.Next()
+	}
+}
+
+// varyHeaders returns the policy's vary headers with Accept added, since
+// handlers negotiate the response format (JSON or protobuf) from it
+func varyHeaders(vary []string) []string {
+	for _, h := range vary {
+		if h == "Accept" {
+			return vary
+		}
+	}
+	return append([]string{"Accept"}, vary...)
+}
+
+// cacheKey identifies a request by its route template, path parameters,
+// query string and the values of the vary headers
+func cacheKey(c *gin.Context, vary []string) string {
+	var b strings.Builder
+	b.WriteString(c.FullPath())
+	for _, p := range c.Params {
+		b.WriteString("|")
+		b.WriteString(p.Key)
+		b.WriteString("=")
+		b.WriteString(p.Value)
+	}
+
+	query := c.Request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString("|")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(strings.Join(query[k], ","))
+	}
+	for _, h := range vary {
+		b.WriteString("|")
+		b.WriteString(h)
+		b.WriteString(":")
+		b.WriteString(strings.Join(c.Request.Header.Values(h), ","))
+	}
+	return b.String()
+}
+
+// replay writes a cached response to the client and stops the chain
+func replay(c *gin.Context, entry *cachedResponse) {
+	for k, values := range entry.header {
+		for _, v := range values {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	c.Data(entry.status, entry.header.Get("Content-Type"), entry.body)
+	c.Abort()
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResponseCacheCoalescesConcurrentRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(ResponseCache(time.Minute))
+	r.GET("/expensive", func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.String(http.StatusOK, "computed")
+	})
+
+	const n = 10
+	var wg sync.WaitGroup
+	bodies := make([]string, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/expensive", nil))
+			bodies[i] = w.Body.String()
+		}()
+	}
+	// Give the requests time to join the in-flight computation
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler ran %d times for %d concurrent requests, want 1", got, n)
+	}
+	for i, body := range bodies {
+		if body != "computed" {
+			t.Fatalf("request %d got body %q, want %q", i, body, "computed")
+		}
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   gin.HandlerFunc
+		first     http.Header
+		second    http.Header
+		wantCalls int32
+		wantBody  string
+	}{
+		{
+			name:      "repeat request is served from the cache",
+			handler:   func(c *gin.Context) { c.String(http.StatusOK, "ok") },
+			wantCalls: 1,
+			wantBody:  "ok",
+		},
+		{
+			name:      "different Accept gets its own entry",
+			handler:   func(c *gin.Context) { c.String(http.StatusOK, c.GetHeader("Accept")) },
+			first:     http.Header{"Accept": {"application/json"}},
+			second:    http.Header{"Accept": {"application/x-protobuf"}},
+			wantCalls: 2,
+			wantBody:  "application/x-protobuf",
+		},
+		{
+			name: "response setting a cookie is not cached",
+			handler: func(c *gin.Context) {
+				c.SetCookie("session", "abc", 60, "/", "", false, true)
+				c.String(http.StatusOK, "ok")
+			},
+			wantCalls: 2,
+			wantBody:  "ok",
+		},
+		{
+			name:      "credentialed requests bypass the cache",
+			handler:   func(c *gin.Context) { c.String(http.StatusOK, "ok") },
+			first:     http.Header{"Authorization": {"Bearer token"}},
+			second:    http.Header{"Authorization": {"Bearer token"}},
+			wantCalls: 2,
+			wantBody:  "ok",
+		},
+		{
+			name:      "errors are not cached",
+			handler:   func(c *gin.Context) { c.String(http.StatusInternalServerError, "boom") },
+			wantCalls: 2,
+			wantBody:  "boom",
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			r := gin.New()
+			r.Use(ResponseCache(time.Minute))
+			r.GET("/resource", func(c *gin.Context) {
+				calls.Add(1)
+				tt.handler(c)
+			})
+
+			var responses []*httptest.ResponseRecorder
+			for _, header := range []http.Header{tt.first, tt.second} {
+				req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+				for k, v := range header {
+					req.Header[k] = v
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				responses = append(responses, w)
+			}
+
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Fatalf("handler ran %d times, want %d", got, tt.wantCalls)
+			}
+			if got := responses[1].Body.String(); got != tt.wantBody {
+				t.Fatalf("second response = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestResponseCacheDoesNotShareCookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(ResponseCache(time.Minute))
+	r.GET("/login", func(c *gin.Context) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-release
+		}
+		c.SetCookie("session", string(rune('a'+n)), 60, "/", "", false, true)
+		c.String(http.StatusOK, "ok")
+	})
+
+	leader := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		r.ServeHTTP(leader, httptest.NewRequest(http.MethodGet, "/login", nil))
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	follower := httptest.NewRecorder()
+	followerDone := make(chan struct{})
+	go func() {
+		r.ServeHTTP(follower, httptest.NewRequest(http.MethodGet, "/login", nil))
+		close(followerDone)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	<-done
+	<-followerDone
+
+	if leader.Header().Get("Set-Cookie") == follower.Header().Get("Set-Cookie") {
+		t.Fatalf("follower received the leader's cookie %q", leader.Header().Get("Set-Cookie"))
+	}
+}
+
+func TestResponseCacheWithPolicies(t *testing.T) {
+	policies, err := ParseCachePolicies(map[string]string{
+		"/items/:id": "1m;vary=accept-language;status=200|404",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := CachePolicy{TTL: time.Minute, Vary: []string{"Accept-Language"}, Statuses: []int{200, 404}}
+	if got := policies["/items/:id"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parsed policy = %+v, want %+v", got, want)
+	}
+
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	r := gin.New()
+	r.Use(ResponseCacheWithPolicies(policies))
+	count := func(status int) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			calls.Add(1)
+			c.String(status, "ok")
+		}
+	}
+	r.GET("/items/:id", func(c *gin.Context) {
+		status := http.StatusOK
+		if c.Param("id") == "missing" {
+			status = http.StatusNotFound
+		}
+		count(status)(c)
+	})
+	r.GET("/uncached", count(http.StatusOK))
+
+	tests := []struct {
+		name      string
+		path      string
+		language  []string
+		wantCalls int32
+	}{
+		{name: "configured route", path: "/items/1", wantCalls: 1},
+		{name: "configured status", path: "/items/missing", wantCalls: 1},
+		{name: "vary header", path: "/items/2", language: []string{"en", "fr"}, wantCalls: 2},
+		{name: "unconfigured route", path: "/uncached", wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			for i := range 2 {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				if tt.language != nil {
+					req.Header.Set("Accept-Language", tt.language[i])
+				}
+				r.ServeHTTP(httptest.NewRecorder(), req)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler ran %d times for two requests, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestParseCachePoliciesRejects(t *testing.T) {
+	for _, value := range []string{"", "0s", "-1m", "1m;vary", "1m;status=abc", "1m;status=700", "1m;ttl=5s"} {
+		if _, err := ParseCachePolicies(map[string]string{"/items": value}); err == nil {
+			t.Errorf("ParseCachePolicies(%q) succeeded, want an error", value)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Cancellation reasons reported by CancellationMetrics
+const (
+	CancelReasonTimeout    = "timeout"
+	CancelReasonDisconnect = "client_disconnect"
+)
+
+// CancellationMetrics middleware records handlers whose request context was
+// cancelled before they returned, distinguishing deadline expiry (timeouts)
+// from the client going away
+func CancellationMetrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		reason := cancelReason(c.Request.Context().Err())
+		if reason == "" {
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HandlerCancelledTotal.WithLabelValues(route, reason).Inc()
+	}
+}
+
+// cancelReason maps a context error to a cancellation reason label
+func cancelReason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CancelReasonTimeout
+	case errors.Is(err, context.Canceled):
+		return CancelReasonDisconnect
+	default:
+		return ""
+	}
+}
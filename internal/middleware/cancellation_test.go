@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCancellationMetricsWithTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		path    string
+		handler gin.HandlerFunc
+		status  int
+		reason  string
+	}{
+		{
+			name:    "completed in time",
+			path:    "/ok",
+			handler: func(c *gin.Context) { c.String(http.StatusOK, "ok") },
+			status:  http.StatusOK,
+		},
+		{
+			name: "deadline passed",
+			path: "/slow",
+			handler: func(c *gin.Context) {
+				<-c.Request.Context().Done()
+			},
+			status: http.StatusServiceUnavailable,
+			reason: CancelReasonTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(CancellationMetrics())
+			router.Use(Timeout(50 * time.Millisecond))
+			router.GET(tt.path, tt.handler)
+
+			before := map[string]float64{}
+			for _, reason := range []string{CancelReasonTimeout, CancelReasonDisconnect} {
+				before[reason] = testutil.ToFloat64(metrics.HandlerCancelledTotal.WithLabelValues(tt.path, reason))
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+
+			for _, reason := range []string{CancelReasonTimeout, CancelReasonDisconnect} {
+				want := before[reason]
+				if reason == tt.reason {
+					want++
+				}
+				got := testutil.ToFloat64(metrics.HandlerCancelledTotal.WithLabelValues(tt.path, reason))
+				if got != want {
+					t.Errorf("cancellations with reason %q = %g, want %g", reason, got, want)
+				}
+			}
+		})
+	}
+}
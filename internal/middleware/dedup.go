@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+)
+
+// DuplicateDeliveryHeader marks a response replayed for a duplicate delivery
+const DuplicateDeliveryHeader = "X-Duplicate-Delivery"
+
+// DedupKeyBody identifies deliveries by a hash of the request body
+const DedupKeyBody = "body"
+
+// dedupHeaderPrefix selects a request header, e.g. header:X-Delivery-ID, as
+// the delivery identifier
+const dedupHeaderPrefix = "header:"
+
+// defaultDedupBodyLimit caps the body read for a "body" key when no request
+// body limit is configured
+const defaultDedupBodyLimit = 1 << 20
+
+// errDedupBodyTooLarge reports a body too large to hash for a "body" key
+var errDedupBodyTooLarge = errors.New("request body too large to deduplicate")
+
+// DedupKeyFunc extracts the identity of a delivery from a request, returning
+// "" when the request can't be identified and shouldn't be deduplicated
+type DedupKeyFunc func(c *gin.Context) (string, error)
+
+// DedupScopeFunc names the authenticated principal a request belongs to, so
+// one caller's deliveries are never replayed to another. It returns "" for
+// anonymous requests.
+type DedupScopeFunc func(c *gin.Context) string
+
+// ParseDedupKey returns the key function for source, which is either "body"
+// or "header:<name>". A "body" key reads at most limit bytes, or 1 MiB when
+// limit is 0, and rejects larger bodies.
+func ParseDedupKey(source string, limit int64) (DedupKeyFunc, error) {
+	if limit <= 0 {
+		limit = defaultDedupBodyLimit
+	}
+	switch {
+	case source == DedupKeyBody:
+		return func(c *gin.Context) (string, error) {
+			return bodyHash(c, limit)
+		}, nil
+	case strings.HasPrefix(source, dedupHeaderPrefix):
+		name := strings.TrimSpace(strings.TrimPrefix(source, dedupHeaderPrefix))
+		if name == "" {
+			return nil, fmt.Errorf("dedup key %q names no header", source)
+		}
+		return func(c *gin.Context) (string, error) {
+			return c.GetHeader(name), nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown dedup key source %q (want %q or %q)", source, DedupKeyBody, dedupHeaderPrefix+"<name>")
+	}
+}
+
+// bodyHash hashes the request body, up to limit bytes, and restores it for
+// the handler
+func bodyHash(c *gin.Context, limit int64) (string, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(body)) > limit {
+		return "", errDedupBodyTooLarge
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Deduplicate middleware ignores repeated deliveries within window, as sent
+// by at-least-once webhook senders, and replays the response given to the
+// first delivery instead of running the handler again. It belongs after the
+// route's auth middleware: deliveries are remembered per route and per
+// principal from scope, and requests carrying credentials that scope can't
+// attribute aren't deduplicated, like the response cache. Concurrent
+// duplicates wait for the first to finish. Server errors aren't remembered
+// so the sender's retry is processed. Responses are held in memory, so each
+// instance deduplicates only the deliveries it receives.
+func Deduplicate(window time.Duration, key DedupKeyFunc, scope DedupScopeFunc) gin.HandlerFunc {
+	store := &responseStore{entries: make(map[string]*cachedResponse)}
+	var group singleflight.Group
+
+	return func(c *gin.Context) {
+		principal := scope(c)
+		if principal == "" && (c.GetHeader("Authorization") != "" || c.GetHeader("X-API-Key") != "") {
+			c.Next()
+			return
+		}
+
+		id, err := key(c)
+		if errors.Is(err, errDedupBodyTooLarge) {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "failed to read request body",
+			})
+			return
+		}
+		if id == "" {
+			c.Next()
+			return
+		}
+
+		// Quoting keeps a principal containing "|" from colliding with another
+		k := c.FullPath() + "|" + strconv.Quote(principal) + "|" + id
+		if entry, ok := store.get(k, time.Now()); ok {
+			c.Header(DuplicateDeliveryHeader, "true")
+			replay(c, entry)
+			return
+		}
+
+		leader := false
+		v, _, _ := group.Do(k, func() (interface{}, error) {
+			leader = true
+			w := &captureWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			c.Next()
+			c.Writer = w.ResponseWriter
+
+			header := w.Header().Clone()
+			header.Del("Content-Encoding")
+
+			entry := &cachedResponse{
+				status:  w.Status(),
+				header:  header,
+				body:    w.body.Bytes(),
+				expires: time.Now().Add(window),
+			}
+			if entry.status < http.StatusInternalServerError {
+				store.set(k, entry)
+			}
+			return entry, nil
+		})
+
+		if !leader {
+			c.Header(DuplicateDeliveryHeader, "true")
+			replay(c, v.(*cachedResponse))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestDeduplicate(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     time.Duration
+		status     int
+		first      http.Header
+		second     http.Header
+		wait       time.Duration
+		wantCalls  int
+		wantReplay bool
+	}{
+		{
+			name:       "duplicate within window is replayed",
+			window:     time.Minute,
+			status:     http.StatusAccepted,
+			first:      http.Header{"X-Delivery-Id": {"d1"}, "X-Principal": {"alice"}},
+			second:     http.Header{"X-Delivery-Id": {"d1"}, "X-Principal": {"alice"}},
+			wantCalls:  1,
+			wantReplay: true,
+		},
+		{
+			name:      "different delivery runs",
+			window:    time.Minute,
+			status:    http.StatusAccepted,
+			first:     http.Header{"X-Delivery-Id": {"d1"}},
+			second:    http.Header{"X-Delivery-Id": {"d2"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "other principal is not replayed",
+			window:    time.Minute,
+			status:    http.StatusAccepted,
+			first:     http.Header{"X-Delivery-Id": {"d1"}, "X-Principal": {"alice"}},
+			second:    http.Header{"X-Delivery-Id": {"d1"}, "X-Principal": {"bob"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "duplicate after window runs",
+			window:    20 * time.Millisecond,
+			status:    http.StatusAccepted,
+			first:     http.Header{"X-Delivery-Id": {"d1"}},
+			second:    http.Header{"X-Delivery-Id": {"d1"}},
+			wait:      50 * time.Millisecond,
+			wantCalls: 2,
+		},
+		{
+			name:      "server error is not remembered",
+			window:    time.Minute,
+			status:    http.StatusServiceUnavailable,
+			first:     http.Header{"X-Delivery-Id": {"d1"}},
+			second:    http.Header{"X-Delivery-Id": {"d1"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "unattributed credentials skip deduplication",
+			window:    time.Minute,
+			status:    http.StatusAccepted,
+			first:     http.Header{"X-Delivery-Id": {"d1"}, "Authorization": {"Bearer a"}},
+			second:    http.Header{"X-Delivery-Id": {"d1"}, "Authorization": {"Bearer b"}},
+			wantCalls: 2,
+		},
+		{
+			name:      "missing identifier runs",
+			window:    time.Minute,
+			status:    http.StatusAccepted,
+			first:     http.Header{},
+			second:    http.Header{},
+			wantCalls: 2,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseDedupKey("header:X-Delivery-ID", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scope := func(c *gin.Context) string { return c.GetHeader("X-Principal") }
+			calls := 0
+			r := gin.New()
+			r.POST("/webhooks/:source", Deduplicate(tt.window, key, scope), func(c *gin.Context) {
+				calls++
+				c.String(tt.status, "delivery %d", calls)
+			})
+
+			send := func(h http.Header) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/webhooks/github", nil)
+				req.Header = h
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				return w
+			}
+			first := send(tt.first)
+			time.Sleep(tt.wait)
+			second := send(tt.second)
+
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.wantCalls)
+			}
+			replayed := second.Header().Get(DuplicateDeliveryHeader) == "true"
+			if replayed != tt.wantReplay {
+				t.Errorf("second delivery replayed = %v, want %v", replayed, tt.wantReplay)
+			}
+			if tt.wantReplay && (second.Code != first.Code || second.Body.String() != first.Body.String()) {
+				t.Errorf("replay = %d %q, want %d %q", second.Code, second.Body.String(), first.Code, first.Body.String())
+			}
+		})
+	}
+}
+
+func TestDeduplicateBodyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, err := ParseDedupKey(DedupKeyBody, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	r := gin.New()
+	r.POST("/hook", Deduplicate(time.Minute, key, func(*gin.Context) string { return "" }), func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		got = append(got, string(body))
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		body       string
+		wantStatus int
+	}{
+		{"payload", http.StatusNoContent},
+		{"payload", http.StatusNoContent},
+		{"too large payload", http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(tt.body)))
+		if w.Code != tt.wantStatus {
+			t.Errorf("POST %q = %d, want %d", tt.body, w.Code, tt.wantStatus)
+		}
+	}
+	// The handler sees the restored body once; the duplicate and the
+	// oversized body never reach it
+	if len(got) != 1 || got[0] != "payload" {
+		t.Errorf("handler bodies = %q, want [payload]", got)
+	}
+}
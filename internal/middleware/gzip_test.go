@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGzipExemptCIDRs(t *testing.T) {
+	exempt, err := ParsePrefixes([]string{"10.0.0.0/8", "192.0.2.7", "fd00::/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := strings.Repeat("dahlia ", 100)
+
+	tests := []struct {
+		name       string
+		remote     string
+		compressed bool
+	}{
+		{name: "outside the exempt ranges", remote: "203.0.113.1:1000", compressed: true},
+		{name: "inside an exempt CIDR", remote: "10.1.2.3:1000"},
+		{name: "exempt single host", remote: "192.0.2.7:1000"},
+		{name: "next to the exempt host", remote: "192.0.2.8:1000", compressed: true},
+		{name: "inside an exempt IPv6 CIDR", remote: "[fd00::1]:1000"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Gzip(exempt))
+	router.GET("/", func(c *gin.Context) { c.String(http.StatusOK, body) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding") == "gzip"; got != tt.compressed {
+				t.Fatalf("compressed = %v, want %v", got, tt.compressed)
+			}
+			var reader io.Reader = w.Body
+			if tt.compressed {
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reader = gz
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != body {
+				t.Errorf("body = %q, want the handler's body", got)
+			}
+		})
+	}
+}
+
+func TestParsePrefixesRejects(t *testing.T) {
+	for _, cidr := range []string{"10.0.0.0/33", "not-an-ip", "10.0.0/8"} {
+		if _, err := ParsePrefixes([]string{cidr}); err == nil {
+			t.Errorf("ParsePrefixes(%q) succeeded, want an error", cidr)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// InFlight tracks the number of requests currently being served
+type InFlight struct {
+	count atomic.Int64
+}
+
+// Count returns the number of requests in flight
+func (f *InFlight) Count() int64 {
+	return f.count.Load()
+}
+
+// Track middleware counts the request as in flight until it completes
+func (f *InFlight) Track() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		metrics.InFlightRequests.Set(float64(f.count.Add(1)))
+		defer func() {
+			metrics.InFlightRequests.Set(float64(f.count.Add(-1)))
+		}()
+		c.Next()
+	}
+}
+
+// LoadShed middleware rejects new requests with 503 while more than threshold
+// requests are in flight, keeping latency bounded under overload instead of
+// queueing without limit. Paths in exempt (such as health checks) are never
+// shed; entries ending in "*" exempt every path with that prefix. It must run
+// after InFlight.Track so the count includes the current request.
+func LoadShed(inflight *InFlight, threshold int64, exempt []string) gin.HandlerFunc {
+	skip := NewPathSet(exempt)
+
+	return func(c *gin.Context) {
+		if threshold <= 0 || skip.Match(c.Request.URL.Path) || inflight.Count() <= threshold {
+			c.Next()
+			return
+		}
+
+		metrics.LoadShedTotal.Inc()
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": "server overloaded",
+		})
+	}
+}
@@ -2,51 +2,62 @@ package middleware
 
 import (
 	"fmt"
-	"time"
+	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RequestLogger middleware for logging HTTP requests
-func RequestLogger() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		// Custom log format
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	})
+// CORSWildcard allows requests from any origin
+const CORSWildcard = "*"
+
+// ValidateCORSOrigins rejects a wildcard origin in production, where an
+// explicit allowlist is required
+func ValidateCORSOrigins(origins []string, environment string) error {
+	if environment == "production" && slices.Contains(origins, CORSWildcard) {
+		return fmt.Errorf("wildcard CORS origin is not allowed in production; list allowed origins explicitly")
+	}
+	return nil
 }
 
-// CORS middleware for handling Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
+// CORS middleware for handling Cross-Origin Resource Sharing. Requests from
+// the allowed origins, or any origin when the list contains "*", get CORS
+// headers and preflight requests are answered directly with methods as the
+// allowed methods; other origins get no CORS headers, so browsers block the
+// response. Credentials are only allowed for explicitly listed origins.
+func CORS(origins, methods []string) gin.HandlerFunc {
+	wildcard := slices.Contains(origins, CORSWildcard)
+	allowMethods := strings.Join(methods, ", ")
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[origin] = true
+	}
+
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		origin := c.GetHeader("Origin")
+		if origin == "" || (!wildcard && !allowed[origin]) {
+			c.Next()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		h := c.Writer.Header()
+		if wildcard {
+			// Browsers refuse credentials with a wildcard origin
+			h.Set("Access-Control-Allow-Origin", CORSWildcard)
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Credentials", "true")
+			h.Add("Vary", "Origin")
+		}
+		h.Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		h.Set("Access-Control-Allow-Methods", allowMethods)
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
 	}
 }
-
-// RateLimit middleware for basic rate limiting
-func RateLimit() gin.HandlerFunc {
-	// Simple in-memory rate limiter - in production use Redis
-	return func(c *gin.Context) {
-		// TODO: Implement proper rate limiting with Redis
-		c.Next()
-	}
-}
\ No newline at end of file
@@ -0,0 +1,27 @@
+package middleware
+
+import "testing"
+
+func TestValidateCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		environment string
+		wantErr     bool
+	}{
+		{name: "wildcard in production", origins: []string{"https://app.example.com", CORSWildcard}, environment: "production", wantErr: true},
+		{name: "explicit origins in production", origins: []string{"https://app.example.com"}, environment: "production"},
+		{name: "wildcard in development", origins: []string{CORSWildcard}, environment: "development"},
+		{name: "wildcard in staging", origins: []string{CORSWildcard}, environment: "staging"},
+		{name: "no origins in production", environment: "production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCORSOrigins(tt.origins, tt.environment)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCORSOrigins(%q, %q) = %v, want error: %v", tt.origins, tt.environment, err, tt.wantErr)
+			}
+		})
+	}
+}
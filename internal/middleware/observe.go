@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Observe middleware records request counts and latencies by route template
+// and feeds each request to the metrics aggregator
+func Observe(agg *metrics.Aggregator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+		code := strconv.Itoa(status)
+		metrics.RequestsTotal.WithLabelValues(c.Request.Method, route, code).Inc()
+		metrics.RequestDuration.WithLabelValues(c.Request.Method, route, code).Observe(elapsed.Seconds())
+		agg.Observe(status, elapsed)
+	}
+}
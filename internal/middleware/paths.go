@@ -0,0 +1,36 @@
+package middleware
+
+import "strings"
+
+// PathSet matches request paths exactly, or by prefix for entries ending
+// in "*" (e.g. "/admin/*")
+type PathSet struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+// NewPathSet builds a PathSet from paths
+func NewPathSet(paths []string) PathSet {
+	s := PathSet{exact: make(map[string]bool, len(paths))}
+	for _, path := range paths {
+		if prefix, ok := strings.CutSuffix(path, "*"); ok {
+			s.prefixes = append(s.prefixes, prefix)
+			continue
+		}
+		s.exact[path] = true
+	}
+	return s
+}
+
+// Match reports whether path is in the set
+func (s PathSet) Match(path string) bool {
+	if s.exact[path] {
+		return true
+	}
+	for _, prefix := range s.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
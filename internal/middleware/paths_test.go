@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPathSet(t *testing.T) {
+	set := NewPathSet([]string{"/health", "/ready", "/ready/*"})
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/health", want: true},
+		{path: "/ready", want: true},
+		{path: "/ready/database", want: true},
+		{path: "/ready/", want: true},
+		{path: "/healthz", want: false},
+		{path: "/readyz", want: false},
+		{path: "/api/v1/info", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := set.Match(tt.path); got != tt.want {
+				t.Fatalf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadShedExemptPrefix(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "/ready/database", want: http.StatusOK},
+		{path: "/ready", want: http.StatusOK},
+		{path: "/api/v1/info", want: http.StatusServiceUnavailable},
+	}
+
+	gin.SetMode(gin.TestMode)
+	inflight := &InFlight{}
+	inflight.count.Store(10)
+	router := gin.New()
+	router.Use(LoadShed(inflight, 1, []string{"/health", "/ready", "/ready/*"}))
+	router.NoRoute(func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("GET %s under overload = %d, want %d", tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
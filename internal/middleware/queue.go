@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// ConcurrencyQueue middleware allows at most limit requests to run at once.
+// Requests arriving while all slots are busy wait in a FIFO queue of up to
+// queueSize entries for at most wait; requests that can't be queued or whose
+// wait expires are rejected with 503. Queued requests whose client disconnects
+// leave the queue without being served. Paths in exempt bypass the limit;
+// entries ending in "*" exempt every path with that prefix.
+func ConcurrencyQueue(limit, queueSize int, wait time.Duration, exempt []string) gin.HandlerFunc {
+	if limit <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	skip := NewPathSet(exempt)
+	slots := make(chan struct{}, limit)
+	var queued atomic.Int64
+
+	reject := func(c *gin.Context, reason string) {
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error": reason,
+		})
+	}
+
+	return func(c *gin.Context) {
+		if skip.Match(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			if queued.Add(1) > int64(queueSize) {
+				queued.Add(-1)
+				reject(c, "request queue full")
+				return
+			}
+			metrics.RequestQueueDepth.Set(float64(queued.Load()))
+
+			timer := time.NewTimer(wait)
+			var acquired bool
+			select {
+			// Channel senders are woken in arrival order, which keeps the queue FIFO
+			case slots <- struct{}{}:
+				acquired = true
+			case <-timer.C:
+				reject(c, "timed out waiting in request queue")
+			case <-c.Request.Context().Done():
+				c.Abort()
+			}
+			timer.Stop()
+			metrics.RequestQueueDepth.Set(float64(queued.Add(-1)))
+			if !acquired {
+				return
+			}
+		}
+
+		defer func() { <-slots }()
+		c.Next()
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// queueRouter serves /hold, which occupies a slot until release is closed,
+// and /fast behind a one-slot ConcurrencyQueue
+func queueRouter(queueSize int, wait time.Duration) (router *gin.Engine, entered chan struct{}, release chan struct{}) {
+	gin.SetMode(gin.TestMode)
+	entered = make(chan struct{}, 1)
+	release = make(chan struct{})
+	router = gin.New()
+	router.Use(ConcurrencyQueue(1, queueSize, wait, []string{"/health"}))
+	router.GET("/hold", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router, entered, release
+}
+
+func serve(router http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+// waitForQueueDepth polls the queue depth gauge until it reaches want
+func waitForQueueDepth(t *testing.T, want float64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for testutil.ToFloat64(metrics.RequestQueueDepth) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth = %v, want %v", testutil.ToFloat64(metrics.RequestQueueDepth), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func assertRejected(t *testing.T, w *httptest.ResponseRecorder, reason string) {
+	t.Helper()
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != reason {
+		t.Errorf("error = %q, want %q", body.Error, reason)
+	}
+}
+
+func TestConcurrencyQueueProceeds(t *testing.T) {
+	router, entered, release := queueRouter(1, time.Second)
+	go serve(router, "/hold")
+	<-entered
+
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() { done <- serve(router, "/fast") }()
+	waitForQueueDepth(t, 1)
+
+	if w := serve(router, "/health"); w.Code != http.StatusOK {
+		t.Errorf("exempt path status = %d, want %d while the slot is held", w.Code, http.StatusOK)
+	}
+
+	close(release)
+	if w := <-done; w.Code != http.StatusOK {
+		t.Fatalf("queued request status = %d, want %d once the slot is free", w.Code, http.StatusOK)
+	}
+	waitForQueueDepth(t, 0)
+}
+
+func TestConcurrencyQueueFull(t *testing.T) {
+	router, entered, release := queueRouter(1, time.Second)
+	defer close(release)
+	go serve(router, "/hold")
+	<-entered
+
+	go serve(router, "/fast")
+	waitForQueueDepth(t, 1)
+
+	start := time.Now()
+	assertRejected(t, serve(router, "/fast"), "request queue full")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("full queue took %v to reject, want an immediate rejection", elapsed)
+	}
+}
+
+func TestConcurrencyQueueWaitExpires(t *testing.T) {
+	router, entered, release := queueRouter(1, 20*time.Millisecond)
+	defer close(release)
+	go serve(router, "/hold")
+	<-entered
+
+	assertRejected(t, serve(router, "/fast"), "timed out waiting in request queue")
+	waitForQueueDepth(t, 0)
+}
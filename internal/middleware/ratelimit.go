@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultBucketTTL is how long an idle client's bucket is kept when no TTL
+// is configured
+const defaultBucketTTL = 10 * time.Minute
+
+// tenantKeyPrefix separates tenant buckets from client IP buckets
+const tenantKeyPrefix = "tenant:"
+
+// bucket is a token bucket for a single client or tenant
+type bucket struct {
+	key    string
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// TenantLimits configures per-tenant rate limiting; see RateLimiter.LimitTenants
+type TenantLimits struct {
+	// Identify returns the tenant a request belongs to, or "" when it has
+	// none and should be limited by client IP
+	Identify func(*gin.Context) string
+	// Rate and Burst apply to every tenant without an override
+	Rate  float64
+	Burst int
+	// Overrides sets the rate for individual tenants
+	Overrides map[string]float64
+}
+
+// ParseTenantRates converts a mapping from tenant to requests per second
+// into rate overrides
+func ParseTenantRates(mapping map[string]string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(mapping))
+	for tenant, value := range mapping {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rate limit for tenant %q must be a positive number, got %q", tenant, value)
+		}
+		rates[tenant] = rate
+	}
+	return rates, nil
+}
+
+// RateLimiter is an in-memory token bucket rate limiter keyed by client IP,
+// or by tenant when LimitTenants is configured.
+// Buckets unused for longer than the TTL are evicted by Sweep so one-off
+// clients don't accumulate forever, and at most maxBuckets are held so a
+// spray of unique source IPs can't grow memory without bound.
+type RateLimiter struct {
+	rate       float64
+	burst      float64
+	ttl        time.Duration
+	maxBuckets int
+
+	// tenants, when set, moves requests that identify a tenant onto a
+	// bucket shared by that tenant
+	tenants *TenantLimits
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	// lru orders buckets from most (front) to least recently used
+	lru *list.List
+}
+
+// NewRateLimiter allows each client rate requests per second with bursts of
+// up to burst requests. Idle buckets are evicted after ttl; a non-positive
+// ttl uses a default of 10 minutes. When maxBuckets are held, a new client
+// evicts the least recently used bucket; zero or less means no cap.
+func NewRateLimiter(rate float64, burst int, ttl time.Duration, maxBuckets int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	if ttl <= 0 {
+		ttl = defaultBucketTTL
+	}
+	return &RateLimiter{
+		rate:       rate,
+		burst:      float64(burst),
+		ttl:        ttl,
+		maxBuckets: maxBuckets,
+		buckets:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// LimitTenants gives each tenant identified by t its own bucket, shared by
+// all of the tenant's clients, instead of limiting by client IP. Requests
+// without a tenant are still limited by IP. Call it before Limit.
+func (l *RateLimiter) LimitTenants(t TenantLimits) {
+	if t.Burst < 1 {
+		t.Burst = 1
+	}
+	l.tenants = &t
+}
+
+// Allow takes a token from key's bucket, reporting whether one was available
+func (l *RateLimiter) Allow(key string, now time.Time) bool {
+	return l.allow(key, l.rate, l.burst, now)
+}
+
+// allow takes a token from key's bucket, creating it with rate and burst
+func (l *RateLimiter) allow(key string, rate, burst float64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var b *bucket
+	if el, ok := l.buckets[key]; ok {
+		b = el.Value.(*bucket)
+		l.lru.MoveToFront(el)
+	} else {
+		if l.maxBuckets > 0 && len(l.buckets) >= l.maxBuckets {
+			l.remove(l.lru.Back())
+		}
+		b = &bucket{key: key, rate: rate, burst: burst, tokens: burst, last: now}
+		l.buckets[key] = l.lru.PushFront(b)
+		metrics.RateLimitBuckets.Set(float64(len(l.buckets)))
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Len returns the number of client buckets currently held
+func (l *RateLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// remove drops a bucket; the caller holds the lock
+func (l *RateLimiter) remove(el *list.Element) {
+	delete(l.buckets, el.Value.(*bucket).key)
+	l.lru.Remove(el)
+}
+
+// evict removes buckets idle for longer than the TTL, walking from the least
+// recently used end until it reaches one still in use
+func (l *RateLimiter) evict(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for el := l.lru.Back(); el != nil && now.Sub(el.Value.(*bucket).last) > l.ttl; el = l.lru.Back() {
+		l.remove(el)
+	}
+	metrics.RateLimitBuckets.Set(float64(len(l.buckets)))
+}
+
+// Sweep evicts idle buckets every half TTL until ctx is cancelled
+func (l *RateLimiter) Sweep(ctx context.Context) {
+	ticker := time.NewTicker(l.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.evict(now)
+		}
+	}
+}
+
+// Limit middleware rejects requests from clients or tenants that have
+// exhausted their bucket with 429. Requests without a tenant are not limited
+// when the per-IP rate is zero. It is meant for the API group, so health
+// checks and admin routes are never limited.
+func (l *RateLimiter) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, rate, burst := l.limitFor(c)
+		if rate <= 0 || l.allow(key, rate, burst, time.Now()) {
+			c.Next()
+			return
+		}
+
+		metrics.RateLimitedTotal.Inc()
+		c.Header("Retry-After", strconv.Itoa(max(1, int(1/rate))))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "rate limit exceeded",
+		})
+	}
+}
+
+// limitFor returns the bucket key and limits for a request: its tenant's if
+// it identifies one, otherwise its client IP's
+func (l *RateLimiter) limitFor(c *gin.Context) (key string, rate, burst float64) {
+	if t := l.tenants; t != nil {
+		if tenant := t.Identify(c); tenant != "" {
+			rate := t.Rate
+			if override, ok := t.Overrides[tenant]; ok {
+				rate = override
+			}
+			return tenantKeyPrefix + tenant, rate, float64(t.Burst)
+		}
+	}
+	return c.ClientIP(), l.rate, l.burst
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRateLimiterLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		remote string
+		tenant string
+		want   int
+	}
+	tests := []struct {
+		name     string
+		tenants  bool
+		requests []request
+	}{
+		{
+			name: "per client IP",
+			requests: []request{
+				{remote: "203.0.113.1:1000", want: http.StatusOK},
+				{remote: "203.0.113.1:1001", want: http.StatusTooManyRequests},
+				{remote: "203.0.113.2:1000", want: http.StatusOK},
+			},
+		},
+		{
+			name:    "tenants sharing an IP are limited independently",
+			tenants: true,
+			requests: []request{
+				{remote: "203.0.113.1:1000", tenant: "acme", want: http.StatusOK},
+				{remote: "203.0.113.1:1000", tenant: "globex", want: http.StatusOK},
+				{remote: "203.0.113.1:1000", tenant: "acme", want: http.StatusTooManyRequests},
+				{remote: "203.0.113.1:1000", want: http.StatusOK},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(0.001, 1, time.Minute, 0)
+			if tt.tenants {
+				limiter.LimitTenants(TenantLimits{
+					Identify: func(c *gin.Context) string { return c.GetHeader("X-Tenant-ID") },
+					Rate:     0.001,
+					Burst:    1,
+				})
+			}
+			router := gin.New()
+			router.Use(limiter.Limit())
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			for i, r := range tt.requests {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = r.remote
+				if r.tenant != "" {
+					req.Header.Set("X-Tenant-ID", r.tenant)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != r.want {
+					t.Fatalf("request %d: status = %d, want %d", i, w.Code, r.want)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+					t.Errorf("request %d: 429 without Retry-After", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterEvict(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, time.Minute, 0)
+	start := time.Now()
+	limiter.Allow("idle", start)
+	limiter.Allow("active", start)
+	limiter.Allow("active", start.Add(45*time.Second))
+
+	limiter.evict(start.Add(30 * time.Second))
+	if got := limiter.Len(); got != 2 {
+		t.Fatalf("buckets inside the TTL = %d, want 2", got)
+	}
+
+	limiter.evict(start.Add(90 * time.Second))
+	if got := limiter.Len(); got != 1 {
+		t.Fatalf("buckets after the idle one expired = %d, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.RateLimitBuckets); got != 1 {
+		t.Errorf("rate limit buckets gauge = %v, want 1", got)
+	}
+	// An evicted client starts over with a full bucket
+	if !limiter.Allow("idle", start.Add(90*time.Second)) {
+		t.Error("evicted client was limited")
+	}
+
+	limiter.evict(start.Add(10 * time.Minute))
+	if got := limiter.Len(); got != 0 {
+		t.Errorf("buckets after every TTL passed = %d, want 0", got)
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	limiter := NewRateLimiter(1, 1, 20*time.Millisecond, 0)
+	limiter.Allow("client", time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		limiter.Sweep(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for limiter.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Sweep didn't evict an idle bucket")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RetryBudgetHeader tells clients whether retries are welcome
+const RetryBudgetHeader = "X-Retry-Budget"
+
+// Retry budget states, from most to least room for retries
+const (
+	RetryBudgetOK        = "ok"
+	RetryBudgetLow       = "low"
+	RetryBudgetExhausted = "exhausted"
+)
+
+// retryBudgetLowRatio is the fraction of the threshold at which clients are
+// asked to slow their retries
+const retryBudgetLowRatio = 0.8
+
+// RetryBudget middleware advertises the server's remaining capacity in the
+// X-Retry-Budget header so well-behaved clients can back off before the load
+// shedder starts rejecting them: "ok" below 80% of threshold in-flight
+// requests, "low" up to the threshold and "exhausted" above it. It must run
+// after InFlight.Track and before LoadShed so shed responses carry the header.
+func RetryBudget(inflight *InFlight, threshold int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(RetryBudgetHeader, retryBudgetState(inflight.Count(), threshold))
+		c.Next()
+	}
+}
+
+func retryBudgetState(count, threshold int64) string {
+	switch {
+	case count > threshold:
+		return RetryBudgetExhausted
+	case float64(count) > float64(threshold)*retryBudgetLowRatio:
+		return RetryBudgetLow
+	default:
+		return RetryBudgetOK
+	}
+}
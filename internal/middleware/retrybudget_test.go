@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRetryBudgetState(t *testing.T) {
+	tests := []struct {
+		count, threshold int64
+		want             string
+	}{
+		{0, 10, RetryBudgetOK},
+		{8, 10, RetryBudgetOK},
+		{9, 10, RetryBudgetLow},
+		{10, 10, RetryBudgetLow},
+		{11, 10, RetryBudgetExhausted},
+	}
+	for _, tt := range tests {
+		if got := retryBudgetState(tt.count, tt.threshold); got != tt.want {
+			t.Errorf("retryBudgetState(%d, %d) = %q, want %q", tt.count, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestRetryBudgetHeaderUnderLoad(t *testing.T) {
+	const threshold = 5
+	tests := []struct {
+		held       int
+		wantHeader string
+		wantStatus int
+	}{
+		{held: 0, wantHeader: RetryBudgetOK, wantStatus: http.StatusOK},
+		{held: 3, wantHeader: RetryBudgetOK, wantStatus: http.StatusOK},
+		{held: 4, wantHeader: RetryBudgetLow, wantStatus: http.StatusOK},
+		{held: 5, wantHeader: RetryBudgetExhausted, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		inflight := &InFlight{}
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		router := gin.New()
+		router.Use(inflight.Track(), RetryBudget(inflight, threshold), LoadShed(inflight, threshold, nil))
+		router.GET("/hold", func(c *gin.Context) {
+			entered <- struct{}{}
+			<-release
+		})
+		router.GET("/fast", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		var wg sync.WaitGroup
+		for range tt.held {
+			wg.Go(func() { serve(router, "/hold") })
+			<-entered
+		}
+
+		// The probe is in flight too, on top of the held requests
+		w := serve(router, "/fast")
+		close(release)
+		wg.Wait()
+
+		if got := w.Header().Get(RetryBudgetHeader); got != tt.wantHeader {
+			t.Errorf("with %d requests held, %s = %q, want %q", tt.held, RetryBudgetHeader, got, tt.wantHeader)
+		}
+		if w.Code != tt.wantStatus {
+			t.Errorf("with %d requests held, status = %d, want %d", tt.held, w.Code, tt.wantStatus)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutPolicy decides how long a request may run
+type TimeoutPolicy struct {
+	// Default applies to routes without an override; zero disables the timeout
+	Default time.Duration
+	// Methods overrides Default by HTTP method (e.g. "POST"), for routes
+	// without a route override
+	Methods map[string]time.Duration
+	// Routes overrides the timeout by Gin route template (e.g. "/api/v1/report")
+	Routes map[string]time.Duration
+}
+
+// For returns the timeout for the matched route of c: its route override,
+// else its method's, else the default
+func (p TimeoutPolicy) For(c *gin.Context) time.Duration {
+	if d, ok := p.Routes[c.FullPath()]; ok {
+		return d
+	}
+	if d, ok := p.Methods[c.Request.Method]; ok {
+		return d
+	}
+	return p.Default
+}
+
+// knownMethods are the methods accepted as timeout overrides
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// Validate checks that every overridden method is an HTTP method, written in
+// upper case, and every overridden route template is registered
+func (p TimeoutPolicy) Validate(routes gin.RoutesInfo) error {
+	for method := range p.Methods {
+		if !knownMethods[method] {
+			return fmt.Errorf("timeout override for unknown method %q (want one of GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS)", method)
+		}
+	}
+
+	known := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		known[r.Path] = true
+	}
+	for template := range p.Routes {
+		if !known[template] {
+			return fmt.Errorf("timeout override for unknown route %q", template)
+		}
+	}
+	return nil
+}
+
+// timeoutBody is the response to a request that timed out
+const timeoutBody = `{"error":"request timed out"}`
+
+// timeoutWriter guards the response so that only one of the handler or the
+// timeout path can write it. The handler writes into its own header map,
+// which is copied to the real response when the handler first writes.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu        sync.Mutex
+	header    http.Header
+	committed bool
+	timedOut  bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// commit copies the handler's headers to the real response; callers hold mu
+func (w *timeoutWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	dst := w.ResponseWriter.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.commit()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.commit()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return
+	}
+	w.commit()
+	w.ResponseWriter.Flush()
+}
+
+// Timeout middleware caps handlers at d
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return TimeoutWithPolicy(TimeoutPolicy{Default: d})
+}
+
+// TimeoutWithPolicy middleware caps handlers at the duration chosen by the
+// policy. The handler sees a context that is cancelled at the deadline; if it
+// hasn't written a response by then the client receives a 503 with a JSON
+// body. At the deadline the response is completed and flushed, with a
+// Content-Length and the connection marked for closing, so the client is
+// done even if the handler hangs; later writes from the handler are
+// discarded. A request the client cancels first is left to finish normally.
+// The middleware itself still waits for the handler to return before
+// releasing the request, because Gin reuses the context afterwards and it
+// must never be used concurrently. Once the handler returns in time the
+// original request is restored, so outer middleware only sees a cancelled
+// context when the client went away or the deadline passed.
+func TimeoutWithPolicy(policy TimeoutPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := policy.For(c)
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		req := c.Request
+		ctx, cancel := context.WithTimeout(req.Context(), d)
+		defer cancel()
+		c.Request = req.WithContext(ctx)
+
+		w := &timeoutWriter{ResponseWriter: c.Writer, header: make(http.Header)}
+		c.Writer = w
+
+		done := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				done <- recover()
+			}()
+			c.Next()
+		}()
+
+		var recovered interface{}
+		select {
+		case recovered = <-done:
+			w.mu.Lock()
+			w.commit()
+			w.mu.Unlock()
+			c.Request = req
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				// The client went away; nobody is waiting for a 503
+				recovered = <-done
+				w.mu.Lock()
+				w.commit()
+				w.mu.Unlock()
+				c.Request = req
+				break
+			}
+			w.mu.Lock()
+			w.timedOut = true
+			if !w.committed {
+				w.ResponseWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.ResponseWriter.Header().Set("Content-Length", strconv.Itoa(len(timeoutBody)))
+				w.ResponseWriter.Header().Set("Connection", "close")
+				w.ResponseWriter.WriteHeader(http.StatusServiceUnavailable)
+				w.ResponseWriter.WriteString(timeoutBody)
+			}
+			w.ResponseWriter.Flush()
+			w.mu.Unlock()
+			recovered = <-done
+		}
+
+		c.Writer = w.ResponseWriter
+		if recovered != nil {
+			// Re-raise on the request goroutine so Recovery can handle it
+			panic(recovered)
+		}
+	}
+}
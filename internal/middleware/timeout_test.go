@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutRespondsWithoutWaitingForHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		status  int
+		body    string
+	}{
+		{
+			name:    "in time",
+			handler: func(c *gin.Context) { c.String(http.StatusOK, "ok") },
+			status:  http.StatusOK,
+			body:    "ok",
+		},
+		{
+			name: "handler ignores its context",
+			handler: func(c *gin.Context) {
+				time.Sleep(time.Second)
+				c.String(http.StatusOK, "late")
+			},
+			status: http.StatusServiceUnavailable,
+			body:   timeoutBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Timeout(50 * time.Millisecond))
+			router.GET("/", tt.handler)
+			srv := httptest.NewServer(router)
+			defer srv.Close()
+
+			started := time.Now()
+			resp, err := http.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+				t.Errorf("response took %s, want it at the deadline", elapsed)
+			}
+			if resp.StatusCode != tt.status || string(body) != tt.body {
+				t.Errorf("response = %d %q, want %d %q", resp.StatusCode, body, tt.status, tt.body)
+			}
+		})
+	}
+}
+
+func TestTimeoutPolicyFor(t *testing.T) {
+	policy := TimeoutPolicy{
+		Default: 30 * time.Second,
+		Methods: map[string]time.Duration{http.MethodPost: time.Minute},
+		Routes:  map[string]time.Duration{"/reports/:id": 5 * time.Second},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   time.Duration
+	}{
+		{name: "default", method: http.MethodGet, path: "/items", want: 30 * time.Second},
+		{name: "method override", method: http.MethodPost, path: "/items", want: time.Minute},
+		{name: "route override beats method", method: http.MethodPost, path: "/reports/7", want: 5 * time.Second},
+		{name: "route override beats default", method: http.MethodGet, path: "/reports/7", want: 5 * time.Second},
+	}
+
+	gin.SetMode(gin.TestMode)
+	var got time.Duration
+	router := gin.New()
+	record := func(c *gin.Context) { got = policy.For(c) }
+	router.GET("/items", record)
+	router.POST("/items", record)
+	router.GET("/reports/:id", record)
+	router.POST("/reports/:id", record)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = 0
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+			if got != tt.want {
+				t.Errorf("For(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutPolicyValidate(t *testing.T) {
+	routes := gin.RoutesInfo{{Method: http.MethodGet, Path: "/reports/:id"}}
+
+	tests := []struct {
+		name    string
+		policy  TimeoutPolicy
+		wantErr bool
+	}{
+		{name: "known overrides", policy: TimeoutPolicy{
+			Methods: map[string]time.Duration{http.MethodPost: time.Minute},
+			Routes:  map[string]time.Duration{"/reports/:id": time.Second},
+		}},
+		{name: "lower case method", policy: TimeoutPolicy{Methods: map[string]time.Duration{"post": time.Minute}}, wantErr: true},
+		{name: "unknown method", policy: TimeoutPolicy{Methods: map[string]time.Duration{"FETCH": time.Minute}}, wantErr: true},
+		{name: "unknown route", policy: TimeoutPolicy{Routes: map[string]time.Duration{"/reports": time.Second}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(routes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeoutWithPolicyPerMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TimeoutWithPolicy(TimeoutPolicy{
+		Default: 20 * time.Millisecond,
+		Methods: map[string]time.Duration{http.MethodPost: time.Second},
+	}))
+	// the handler outlasts the default but not the POST timeout
+	slow := func(c *gin.Context) {
+		time.Sleep(60 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	}
+	router.GET("/items", slow)
+	router.POST("/items", slow)
+
+	tests := []struct {
+		method string
+		want   int
+	}{
+		{method: http.MethodGet, want: http.StatusServiceUnavailable},
+		{method: http.MethodPost, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, "/items", nil))
+			if w.Code != tt.want {
+				t.Errorf("%s status = %d, want %d", tt.method, w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Logger interface for dependency injection
+type Logger interface {
+	Warn(msg string)
+}
+
+// timingFrameKey is the context key holding the innermost timed middleware
+const timingFrameKey = "middleware_timing_frame"
+
+// timingFrame collects how long a timed middleware spent downstream, in
+// c.Next, so only its own time is attributed to it
+type timingFrame struct {
+	downstream time.Duration
+}
+
+// MiddlewareTimer flags middlewares whose own time, excluding everything
+// they call through c.Next, exceeds a threshold. Wrap on a nil timer returns
+// middlewares unchanged.
+type MiddlewareTimer struct {
+	threshold time.Duration
+	logger    Logger
+}
+
+// NewMiddlewareTimer returns a timer flagging middlewares slower than
+// threshold, or nil when threshold is not positive
+func NewMiddlewareTimer(threshold time.Duration, logger Logger) *MiddlewareTimer {
+	if threshold <= 0 {
+		return nil
+	}
+	return &MiddlewareTimer{threshold: threshold, logger: logger}
+}
+
+// Wrap times h under name. Everything downstream of the last wrapped
+// middleware is only excluded from its time if End follows it in the chain.
+func (t *MiddlewareTimer) Wrap(name string, h gin.HandlerFunc) gin.HandlerFunc {
+	if t == nil {
+		return h
+	}
+	return func(c *gin.Context) {
+		parent, _ := c.Get(timingFrameKey)
+		frame := &timingFrame{}
+		c.Set(timingFrameKey, frame)
+
+		start := time.Now()
+		h(c)
+		total := time.Since(start)
+
+		if p, ok := parent.(*timingFrame); ok {
+			p.downstream += total
+		}
+		if own := total - frame.downstream; own > t.threshold {
+			metrics.SlowMiddlewareTotal.WithLabelValues(name).Inc()
+			t.logger.Warn(fmt.Sprintf("Slow middleware name=%s duration=%s path=%s", name, own, c.Request.URL.Path))
+		}
+	}
+}
+
+// End marks the end of the timed middlewares, so the route's own handlers
+// aren't counted against the last of them
+func (t *MiddlewareTimer) End() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parent, _ := c.Get(timingFrameKey)
+		start := time.Now()
+		c.Next()
+		if p, ok := parent.(*timingFrame); ok {
+			p.downstream += time.Since(start)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// warnRecorder keeps the warnings logged
+type warnRecorder struct {
+	warns []string
+}
+
+func (l *warnRecorder) Warn(msg string) { l.warns = append(l.warns, msg) }
+
+// warnFields returns the key=value pairs of a warning
+func warnFields(msg string) map[string]string {
+	fields := make(map[string]string)
+	for _, f := range strings.Fields(msg) {
+		if k, v, ok := strings.Cut(f, "="); ok {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+// sleeping returns a middleware spending d before calling through
+func sleeping(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		time.Sleep(d)
+		c.Next()
+	}
+}
+
+func TestMiddlewareTimerOwnTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const threshold = 20 * time.Millisecond
+	logger := &warnRecorder{}
+	timer := NewMiddlewareTimer(threshold, logger)
+
+	// the fast middleware wraps the slow one and the handler, neither of
+	// which should count against it
+	router := gin.New()
+	router.Use(
+		timer.Wrap("fast", sleeping(0)),
+		timer.Wrap("slow", sleeping(2*threshold)),
+		timer.End(),
+	)
+	router.GET("/work", func(c *gin.Context) {
+		time.Sleep(3 * threshold)
+		c.Status(http.StatusOK)
+	})
+
+	slowBefore := testutil.ToFloat64(metrics.SlowMiddlewareTotal.WithLabelValues("slow"))
+	fastBefore := testutil.ToFloat64(metrics.SlowMiddlewareTotal.WithLabelValues("fast"))
+	if w := serve(router, "/work"); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if len(logger.warns) != 1 {
+		t.Fatalf("warns = %q, want only the slow middleware flagged", logger.warns)
+	}
+	fields := warnFields(logger.warns[0])
+	own, err := time.ParseDuration(fields["duration"])
+	if err != nil {
+		t.Fatalf("warning %q: %v", logger.warns[0], err)
+	}
+	if fields["name"] != "slow" || fields["path"] != "/work" {
+		t.Errorf("warning %q, want name=slow path=/work", logger.warns[0])
+	}
+	if own < 2*threshold || own >= 5*threshold {
+		t.Errorf("own time = %s, want the middleware's own %s without the handler", own, 2*threshold)
+	}
+
+	if got := testutil.ToFloat64(metrics.SlowMiddlewareTotal.WithLabelValues("slow")) - slowBefore; got != 1 {
+		t.Errorf("slow middleware counter for slow grew by %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.SlowMiddlewareTotal.WithLabelValues("fast")) - fastBefore; got != 0 {
+		t.Errorf("slow middleware counter for fast grew by %v, want 0", got)
+	}
+}
+
+func TestNewMiddlewareTimerDisabled(t *testing.T) {
+	timer := NewMiddlewareTimer(0, &warnRecorder{})
+	if timer != nil {
+		t.Fatal("NewMiddlewareTimer(0) returned a timer, want nil")
+	}
+	called := false
+	h := timer.Wrap("noop", func(*gin.Context) { called = true })
+	h(nil)
+	if !called {
+		t.Error("Wrap on a nil timer did not return the middleware unchanged")
+	}
+}
@@ -0,0 +1,302 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: dahlia/v1/dahlia.proto
+
+package dahliav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Status mirrors the /api/v1/status response
+type Status struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Service string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Uptime  string                 `protobuf:"bytes,3,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Status  string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// Build metadata injected at link time; see internal/buildinfo.
+	Commit    string `protobuf:"bytes,5,opt,name=commit,proto3" json:"commit,omitempty"`
+	BuildTime string `protobuf:"bytes,6,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`
+	// Go runtime details for the serving process.
+	GoVersion     string `protobuf:"bytes,7,opt,name=go_version,json=goVersion,proto3" json:"go_version,omitempty"`
+	Goroutines    int32  `protobuf:"varint,8,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_dahlia_v1_dahlia_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_dahlia_v1_dahlia_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_dahlia_v1_dahlia_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Status) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Status) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Status) GetUptime() string {
+	if x != nil {
+		return x.Uptime
+	}
+	return ""
+}
+
+func (x *Status) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Status) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *Status) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
+func (x *Status) GetGoVersion() string {
+	if x != nil {
+		return x.GoVersion
+	}
+	return ""
+}
+
+func (x *Status) GetGoroutines() int32 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+// Info mirrors the /api/v1/info response
+type Info struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Version     string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	Languages   []string               `protobuf:"bytes,4,rep,name=languages,proto3" json:"languages,omitempty"`
+	Features    []string               `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"`
+	// Build metadata injected at link time; see internal/buildinfo.
+	Commit        string `protobuf:"bytes,6,opt,name=commit,proto3" json:"commit,omitempty"`
+	BuildTime     string `protobuf:"bytes,7,opt,name=build_time,json=buildTime,proto3" json:"build_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Info) Reset() {
+	*x = Info{}
+	mi := &file_dahlia_v1_dahlia_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Info) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Info) ProtoMessage() {}
+
+func (x *Info) ProtoReflect() protoreflect.Message {
+	mi := &file_dahlia_v1_dahlia_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Info.ProtoReflect.Descriptor instead.
+func (*Info) Descriptor() ([]byte, []int) {
+	return file_dahlia_v1_dahlia_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Info) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Info) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Info) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Info) GetLanguages() []string {
+	if x != nil {
+		return x.Languages
+	}
+	return nil
+}
+
+func (x *Info) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Info) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *Info) GetBuildTime() string {
+	if x != nil {
+		return x.BuildTime
+	}
+	return ""
+}
+
+var File_dahlia_v1_dahlia_proto protoreflect.FileDescriptor
+
+const file_dahlia_v1_dahlia_proto_rawDesc = "" +
+	"\n" +
+	"\x16dahlia/v1/dahlia.proto\x12\tdahlia.v1\x1a\x1bgoogle/protobuf/empty.proto\"\xe2\x01\n" +
+	"\x06Status\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06uptime\x18\x03 \x01(\tR\x06uptime\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x16\n" +
+	"\x06commit\x18\x05 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\x06 \x01(\tR\tbuildTime\x12\x1d\n" +
+	"\n" +
+	"go_version\x18\a \x01(\tR\tgoVersion\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\b \x01(\x05R\n" +
+	"goroutines\"\xc7\x01\n" +
+	"\x04Info\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12\x1c\n" +
+	"\tlanguages\x18\x04 \x03(\tR\tlanguages\x12\x1a\n" +
+	"\bfeatures\x18\x05 \x03(\tR\bfeatures\x12\x16\n" +
+	"\x06commit\x18\x06 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_time\x18\a \x01(\tR\tbuildTime2G\n" +
+	"\rDahliaService\x126\n" +
+	"\tGetStatus\x12\x16.google.protobuf.Empty\x1a\x11.dahlia.v1.StatusB:Z8github.com/divijg19/Dahlia/internal/pb/dahliav1;dahliav1b\x06proto3"
+
+var (
+	file_dahlia_v1_dahlia_proto_rawDescOnce sync.Once
+	file_dahlia_v1_dahlia_proto_rawDescData []byte
+)
+
+func file_dahlia_v1_dahlia_proto_rawDescGZIP() []byte {
+	file_dahlia_v1_dahlia_proto_rawDescOnce.Do(func() {
+		file_dahlia_v1_dahlia_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_dahlia_v1_dahlia_proto_rawDesc), len(file_dahlia_v1_dahlia_proto_rawDesc)))
+	})
+	return file_dahlia_v1_dahlia_proto_rawDescData
+}
+
+var file_dahlia_v1_dahlia_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_dahlia_v1_dahlia_proto_goTypes = []any{
+	(*Status)(nil),        // 0: dahlia.v1.Status
+	(*Info)(nil),          // 1: dahlia.v1.Info
+	(*emptypb.Empty)(nil), // 2: google.protobuf.Empty
+}
+var file_dahlia_v1_dahlia_proto_depIdxs = []int32{
+	2, // 0: dahlia.v1.DahliaService.GetStatus:input_type -> google.protobuf.Empty
+	0, // 1: dahlia.v1.DahliaService.GetStatus:output_type -> dahlia.v1.Status
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_dahlia_v1_dahlia_proto_init() }
+func file_dahlia_v1_dahlia_proto_init() {
+	if File_dahlia_v1_dahlia_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_dahlia_v1_dahlia_proto_rawDesc), len(file_dahlia_v1_dahlia_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_dahlia_v1_dahlia_proto_goTypes,
+		DependencyIndexes: file_dahlia_v1_dahlia_proto_depIdxs,
+		MessageInfos:      file_dahlia_v1_dahlia_proto_msgTypes,
+	}.Build()
+	File_dahlia_v1_dahlia_proto = out.File
+	file_dahlia_v1_dahlia_proto_goTypes = nil
+	file_dahlia_v1_dahlia_proto_depIdxs = nil
+}
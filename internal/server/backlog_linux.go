@@ -0,0 +1,32 @@
+//go:build linux
+
+package server
+
+import (
+	"net"
+	"syscall"
+)
+
+// setBacklog changes the accept queue length of a listening socket. Go
+// always listens with the system maximum (net.core.somaxconn) and a
+// net.ListenConfig Control hook runs before listen, so it can't choose the
+// backlog. Linux lets listen be called again on a listening socket to change
+// it instead, still capped at somaxconn.
+func setBacklog(ln net.Listener, backlog int) error {
+	tcp, ok := ln.(*net.TCPListener)
+	if !ok {
+		return errBacklogUnsupported
+	}
+	raw, err := tcp.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var listenErr error
+	if err := raw.Control(func(fd uintptr) {
+		listenErr = syscall.Listen(int(fd), backlog)
+	}); err != nil {
+		return err
+	}
+	return listenErr
+}
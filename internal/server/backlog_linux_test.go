@@ -0,0 +1,59 @@
+//go:build linux
+
+package server
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// queuedConnections dials ln without it accepting, up to limit times, and
+// returns how many connections the kernel completed before one stalled
+func queuedConnections(t *testing.T, ln net.Listener, limit int) int {
+	t.Helper()
+	var conns []net.Conn
+	defer func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}()
+	for range limit {
+		c, err := net.DialTimeout("tcp", ln.Addr().String(), 200*time.Millisecond)
+		if err != nil {
+			break
+		}
+		conns = append(conns, c)
+	}
+	return len(conns)
+}
+
+func TestListenBacklog(t *testing.T) {
+	const backlog = 2
+	ln, mode, err := Listen("127.0.0.1:0", backlog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if !strings.HasSuffix(mode, ", backlog 2") {
+		t.Errorf("mode = %q, want it to report the backlog", mode)
+	}
+
+	// Linux queues up to backlog+1 completed connections it hasn't accepted
+	if got := queuedConnections(t, ln, 20); got > backlog+1 {
+		t.Errorf("%d connections queued with backlog %d", got, backlog)
+	}
+
+	def, mode, err := Listen("127.0.0.1:0", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer def.Close()
+	if strings.Contains(mode, "backlog") {
+		t.Errorf("mode = %q for the OS default backlog", mode)
+	}
+	if got := queuedConnections(t, def, 20); got != 20 {
+		t.Errorf("%d of 20 connections queued with the OS default backlog", got)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package server
+
+import "net"
+
+// setBacklog is unavailable on this platform; the OS default backlog is kept
+func setBacklog(ln net.Listener, backlog int) error {
+	return errBacklogUnsupported
+}
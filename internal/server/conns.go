@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// ConnTracker follows the connections of an http.Server and their states,
+// so shutdown can tell connections that closed on their own from those it
+// had to cut off and the debug endpoint can report what is open
+type ConnTracker struct {
+	mu       sync.Mutex
+	open     map[net.Conn]http.ConnState
+	draining bool
+	graceful int
+}
+
+// ShutdownResult reports how a server's connections ended during Shutdown
+type ShutdownResult struct {
+	// Graceful is the number of connections that closed while draining
+	Graceful int
+	// Forced are the remote addresses of connections still open when the
+	// drain timed out, which were then closed forcibly
+	Forced []string
+}
+
+// NewConnTracker creates a ConnTracker following no server yet
+func NewConnTracker() *ConnTracker {
+	return &ConnTracker{open: make(map[net.Conn]http.ConnState)}
+}
+
+// TrackConns installs a new ConnTracker on srv
+func TrackConns(srv *http.Server) *ConnTracker {
+	t := NewConnTracker()
+	t.Track(srv)
+	return t
+}
+
+// Track follows the connections of srv, chaining any existing ConnState hook
+func (t *ConnTracker) Track(srv *http.Server) {
+	next := srv.ConnState
+	srv.ConnState = func(conn net.Conn, state http.ConnState) {
+		t.observe(conn, state)
+		if next != nil {
+			next(conn, state)
+		}
+	}
+}
+
+func (t *ConnTracker) observe(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch state {
+	case http.StateNew, http.StateActive, http.StateIdle:
+		t.open[conn] = state
+	case http.StateClosed, http.StateHijacked:
+		if _, ok := t.open[conn]; !ok {
+			return
+		}
+		delete(t.open, conn)
+		if t.draining {
+			t.graceful++
+		}
+	}
+}
+
+// ConnCounts reports the connections open at one moment
+type ConnCounts struct {
+	Total   int            `json:"total"`
+	ByState map[string]int `json:"by_state"`
+}
+
+// Counts returns the number of open connections, in total and by state
+// (new, active or idle)
+func (t *ConnTracker) Counts() ConnCounts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := ConnCounts{
+		Total: len(t.open),
+		ByState: map[string]int{
+			http.StateNew.String():    0,
+			http.StateActive.String(): 0,
+			http.StateIdle.String():   0,
+		},
+	}
+	for _, state := range t.open {
+		counts.ByState[state.String()]++
+	}
+	return counts
+}
+
+// OpenConn describes one open connection
+type OpenConn struct {
+	RemoteAddr string `json:"remote_addr"`
+	State      string `json:"state"`
+}
+
+// Open returns the open connections and their states (new, active or
+// idle), ordered by remote address
+func (t *ConnTracker) Open() []OpenConn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	open := make([]OpenConn, 0, len(t.open))
+	for conn, state := range t.open {
+		open = append(open, OpenConn{RemoteAddr: conn.RemoteAddr().String(), State: state.String()})
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].RemoteAddr < open[j].RemoteAddr })
+	return open
+}
+
+// Shutdown gracefully shuts srv down like http.Server.Shutdown. When ctx is
+// done before every connection has closed, the rest are closed forcibly and
+// reported in the result along with ctx's error.
+func (t *ConnTracker) Shutdown(ctx context.Context, srv *http.Server) (ShutdownResult, error) {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	err := srv.Shutdown(ctx)
+
+	t.mu.Lock()
+	result := ShutdownResult{Graceful: t.graceful}
+	if err != nil {
+		for conn := range t.open {
+			result.Forced = append(result.Forced, conn.RemoteAddr().String())
+		}
+		// Closing the server below closes these; don't count them as graceful
+		t.draining = false
+	}
+	t.mu.Unlock()
+
+	if err != nil {
+		srv.Close()
+	}
+	sort.Strings(result.Forced)
+	return result, err
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestConnTrackerOpen(t *testing.T) {
+	tracker := NewConnTracker()
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tracker.Track(ts.Config)
+	ts.Start()
+	defer ts.Close()
+
+	client := ts.Client()
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	// The kept-alive connection turns idle once the response is written
+	deadline := time.Now().Add(time.Second)
+	for {
+		open := tracker.Open()
+		if len(open) == 1 && open[0].State == http.StateIdle.String() {
+			if open[0].RemoteAddr == "" {
+				t.Fatal("open connection has no remote address")
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Open() = %+v, want one idle connection", open)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	client.CloseIdleConnections()
+	for len(tracker.Open()) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Open() = %+v after the client closed its connection", tracker.Open())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConnTrackerShutdownForcesLingering(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hold" {
+			close(entered)
+			<-release
+		}
+	}))
+	tracker := TrackConns(ts.Config)
+	ts.Start()
+	defer ts.Close()
+	// the held handler must return before the server can close
+	defer close(release)
+
+	// an idle kept-alive connection closes gracefully, the held one lingers
+	resp, err := ts.Client().Get(ts.URL + "/idle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	held, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+	fmt.Fprintf(held, "GET /hold HTTP/1.1\r\nHost: test\r\n\r\n")
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	result, err := tracker.Shutdown(ctx, ts.Config)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() error = %v, want the drain deadline", err)
+	}
+	if want := []string{held.LocalAddr().String()}; !slices.Equal(result.Forced, want) {
+		t.Errorf("forced = %q, want %q", result.Forced, want)
+	}
+
+	// the lingering connection is closed rather than left open
+	held.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := io.ReadAll(held); err != nil {
+		t.Errorf("reading the force-closed connection: %v, want EOF", err)
+	}
+}
+
+func TestConnTrackerShutdownDrained(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tracker := TrackConns(ts.Config)
+	ts.Start()
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	result, err := tracker.Shutdown(context.Background(), ts.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Forced) != 0 {
+		t.Errorf("forced = %q, want none after a complete drain", result.Forced)
+	}
+}
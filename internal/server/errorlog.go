@@ -0,0 +1,47 @@
+package server
+
+import (
+	"log"
+	"strings"
+)
+
+// Logger is the subset of the application logger the server uses
+type Logger interface {
+	Debug(msg string)
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+}
+
+// clientErrorPrefixes mark net/http errors caused by a misbehaving or
+// disconnecting client rather than by the server itself
+var clientErrorPrefixes = []string{
+	"http: TLS handshake error",
+	"http2: received GOAWAY",
+	"http2: server: error reading preface",
+}
+
+// errorLogWriter forwards each net/http error log line to a Logger
+type errorLogWriter struct {
+	logger Logger
+}
+
+func (w errorLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	for _, prefix := range clientErrorPrefixes {
+		if strings.HasPrefix(msg, prefix) {
+			w.logger.Warn(msg)
+			return len(p), nil
+		}
+	}
+	w.logger.Error(msg)
+	return len(p), nil
+}
+
+// ErrorLog returns a logger for http.Server.ErrorLog that routes the
+// server's own errors, such as failed TLS handshakes and panics in the
+// connection handling, into logger: errors caused by clients at WARN and
+// the rest at ERROR
+func ErrorLog(logger Logger) *log.Logger {
+	return log.New(errorLogWriter{logger: logger}, "", 0)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// levelLogger keeps the messages logged at WARN and ERROR
+type levelLogger struct {
+	nopLogger
+	mu     sync.Mutex
+	warns  []string
+	errors []string
+}
+
+func (l *levelLogger) Warn(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, msg)
+}
+
+func (l *levelLogger) Error(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+}
+
+// logged waits for a message with prefix at WARN or ERROR and reports which
+func (l *levelLogger) logged(t *testing.T, prefix string) string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		l.mu.Lock()
+		for level, msgs := range map[string][]string{"warn": l.warns, "error": l.errors} {
+			for _, msg := range msgs {
+				if strings.HasPrefix(msg, prefix) {
+					l.mu.Unlock()
+					return level
+				}
+			}
+		}
+		l.mu.Unlock()
+		if time.Now().After(deadline) {
+			t.Fatalf("no message starting %q logged", prefix)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestErrorLog(t *testing.T) {
+	logger := &levelLogger{}
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("handler bug")
+	}))
+	ts.Config.ErrorLog = ErrorLog(logger)
+	ts.StartTLS()
+	defer ts.Close()
+
+	// a client speaking plain HTTP to the TLS port fails the handshake
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\n\r\n"))
+	conn.Close()
+	if level := logger.logged(t, "http: TLS handshake error"); level != "warn" {
+		t.Errorf("TLS handshake error logged at %s, want warn", level)
+	}
+
+	// a panic in the connection handling is the server's own error
+	if resp, err := ts.Client().Get(ts.URL); err == nil {
+		resp.Body.Close()
+	}
+	if level := logger.logged(t, "http: panic serving"); level != "error" {
+		t.Errorf("panic logged at %s, want error", level)
+	}
+}
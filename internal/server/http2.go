@@ -0,0 +1,13 @@
+package server
+
+import "net/http"
+
+// ConfigureHTTP2 limits each HTTP/2 connection to maxConcurrentStreams open
+// streams, so a single client can't flood the server with streams. Requests
+// beyond the limit are refused by the HTTP/2 layer until a stream closes.
+func ConfigureHTTP2(srv *http.Server, maxConcurrentStreams int) {
+	if srv.HTTP2 == nil {
+		srv.HTTP2 = &http.HTTP2Config{}
+	}
+	srv.HTTP2.MaxConcurrentStreams = maxConcurrentStreams
+}
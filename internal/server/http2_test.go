@@ -0,0 +1,70 @@
+package server
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestConfigureHTTP2MaxStreams(t *testing.T) {
+	const maxStreams, requests = 2, 6
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	entered := make(chan struct{}, requests)
+	release := make(chan struct{})
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		entered <- struct{}{}
+		<-release
+		mu.Lock()
+		running--
+		mu.Unlock()
+		io.WriteString(w, r.Proto)
+	}))
+	srv.EnableHTTP2 = true
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	ConfigureHTTP2(srv.Config, maxStreams)
+	srv.StartTLS()
+	defer srv.Close()
+
+	client := srv.Client()
+
+	var wg sync.WaitGroup
+	protos := make(chan string, requests)
+	for range requests {
+		wg.Go(func() {
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			protos <- string(body)
+		})
+	}
+
+	// Wait for the limit to fill, then let the rest through
+	for range maxStreams {
+		<-entered
+	}
+	close(release)
+	wg.Wait()
+	close(protos)
+
+	for proto := range protos {
+		if proto != "HTTP/2.0" {
+			t.Fatalf("request served over %s, want HTTP/2.0", proto)
+		}
+	}
+	if peak != maxStreams {
+		t.Errorf("peak concurrent streams = %d, want %d", peak, maxStreams)
+	}
+}
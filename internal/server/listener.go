@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor systemd passes to an activated
+// service (SD_LISTEN_FDS_START)
+const listenFDsStart = 3
+
+// errBacklogUnsupported reports a platform where the listen backlog can't be set
+var errBacklogUnsupported = errors.New("setting the listen backlog is not supported on this platform")
+
+// Listen returns a TCP listener for addr with the given accept backlog, or
+// the OS default when backlog is zero. When the process was started through
+// systemd socket activation the inherited socket is used instead of binding a
+// new one, and its backlog comes from the socket unit. The returned mode
+// describes which path was taken, for logging; where the backlog can't be
+// set it notes that the OS default is used rather than failing.
+func Listen(addr string, backlog int) (net.Listener, string, error) {
+	ln, err := systemdListener(os.Getenv, os.Getpid(), listenFDsStart)
+	if err != nil {
+		return nil, "", err
+	}
+	if ln != nil {
+		// Don't leak the activation environment to child processes
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+		return ln, "systemd socket activation", nil
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", err
+	}
+	mode := "bound " + addr
+	if backlog <= 0 {
+		return ln, mode, nil
+	}
+
+	if err := setBacklog(ln, backlog); err != nil {
+		if errors.Is(err, errBacklogUnsupported) {
+			return ln, mode + " (listen backlog unsupported, using OS default)", nil
+		}
+		ln.Close()
+		return nil, "", fmt.Errorf("set listen backlog: %w", err)
+	}
+	return ln, fmt.Sprintf("%s, backlog %d", mode, backlog), nil
+}
+
+// systemdListener returns the first socket passed by systemd, or nil when the
+// environment doesn't indicate socket activation for this process
+func systemdListener(getenv func(string) string, pid int, fd uintptr) (net.Listener, error) {
+	if getenv("LISTEN_PID") != strconv.Itoa(pid) {
+		return nil, nil
+	}
+
+	count, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+
+	f := os.NewFile(fd, "LISTEN_FD_"+strconv.Itoa(int(fd)))
+	if f == nil {
+		return nil, fmt.Errorf("socket activation: invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("socket activation: %w", err)
+	}
+	return ln, nil
+}
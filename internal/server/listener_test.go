@@ -0,0 +1,99 @@
+//go:build unix
+
+package server
+
+import (
+	"net"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+// inheritedSocket returns a listener and a duplicate of its descriptor, as
+// systemd would pass it to the service
+func inheritedSocket(t *testing.T) (net.Listener, uintptr) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	f, err := ln.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln, uintptr(fd)
+}
+
+func TestSystemdListener(t *testing.T) {
+	const pid = 4242
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantListener bool
+	}{
+		{name: "not activated", env: map[string]string{}},
+		{name: "activated for another process", env: map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1"}},
+		{name: "no sockets passed", env: map[string]string{"LISTEN_PID": strconv.Itoa(pid), "LISTEN_FDS": "0"}},
+		{name: "activated", env: map[string]string{"LISTEN_PID": strconv.Itoa(pid), "LISTEN_FDS": "1"}, wantListener: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, fd := inheritedSocket(t)
+			if !tt.wantListener {
+				defer syscall.Close(int(fd))
+			}
+
+			ln, err := systemdListener(func(key string) string { return tt.env[key] }, pid, fd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantListener {
+				if ln != nil {
+					ln.Close()
+					t.Fatal("systemdListener() returned a listener without socket activation")
+				}
+				return
+			}
+			if ln == nil {
+				t.Fatal("systemdListener() = nil with socket activation")
+			}
+			defer ln.Close()
+			if ln.Addr().String() != original.Addr().String() {
+				t.Fatalf("inherited listener on %s, want the passed socket %s", ln.Addr(), original.Addr())
+			}
+
+			// The inherited socket accepts connections
+			go func() {
+				if conn, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+					conn.Close()
+				}
+			}()
+			conn, err := ln.Accept()
+			if err != nil {
+				t.Fatalf("Accept() on the inherited socket: %v", err)
+			}
+			conn.Close()
+		})
+	}
+}
+
+func TestSystemdListenerRejectsNonSocket(t *testing.T) {
+	var fds [2]int
+	if err := syscall.Pipe(fds[:]); err != nil {
+		t.Fatal(err)
+	}
+	defer syscall.Close(fds[1])
+
+	env := map[string]string{"LISTEN_PID": "7", "LISTEN_FDS": "1"}
+	if _, err := systemdListener(func(key string) string { return env[key] }, 7, uintptr(fds[0])); err == nil {
+		t.Fatal("systemdListener() accepted a descriptor that is not a socket")
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+	"github.com/divijg19/Dahlia/internal/metrics"
+)
+
+// Options are the collaborators of Run besides the configuration
+type Options struct {
+	// Logger receives the server's lifecycle messages and error log; required
+	Logger Logger
+	// Conns follows the server's connections; Run creates one when nil
+	Conns *ConnTracker
+	// Configure, when set, is called with the server before it listens
+	Configure func(srv *http.Server)
+	// Cleanup, when set, runs after the server has stopped serving, with
+	// what remains of the shutdown timeout, to release resources such as
+	// database and Redis connections. It also runs when the server fails to
+	// start.
+	Cleanup func(ctx context.Context) error
+}
+
+// Run serves handler on cfg.Port until ctx is done or serving fails, then
+// shuts down: the server drains its connections, bounded by
+// cfg.ConnDrainTimeout, and Cleanup runs, all within cfg.ShutdownTimeout.
+// Cleanup runs even when the drain times out, and the errors are returned
+// rather than exiting, so callers decide how to report them.
+func Run(ctx context.Context, cfg *config.Config, handler http.Handler, opts Options) error {
+	logger := opts.Logger
+	conns := opts.Conns
+	if conns == nil {
+		conns = NewConnTracker()
+	}
+
+	srv := &http.Server{
+		Addr:     fmt.Sprintf(":%d", cfg.Port),
+		Handler:  handler,
+		ErrorLog: ErrorLog(logger),
+	}
+	conns.Track(srv)
+	tlsConfig, err := TLSConfig(cfg)
+	if err != nil {
+		return errors.Join(fmt.Errorf("tls: %w", err), cleanup(cfg, opts))
+	}
+	srv.TLSConfig = tlsConfig
+	ConfigureHTTP2(srv, cfg.MaxConcurrentStreams)
+	if opts.Configure != nil {
+		opts.Configure(srv)
+	}
+
+	ln, mode, err := Listen(srv.Addr, cfg.ListenBacklog)
+	if err != nil {
+		return errors.Join(fmt.Errorf("listen: %w", err), cleanup(cfg, opts))
+	}
+	logger.Info(fmt.Sprintf("Listener ready (%s)", mode))
+
+	served := make(chan error, 1)
+	go func() {
+		logger.Info(fmt.Sprintf("🌸 Dahlia server starting on port %d", cfg.Port))
+		if tlsConfig != nil {
+			served <- srv.ServeTLS(ln, cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			served <- srv.Serve(ln)
+		}
+	}()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case err := <-served:
+		serveErr = fmt.Errorf("serve: %w", err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	started := time.Now()
+
+	// Stop draining ahead of the shutdown deadline so forced closes are
+	// counted and cleanup still gets time
+	drainCtx := shutdownCtx
+	if cfg.ConnDrainTimeout > 0 {
+		var cancelDrain context.CancelFunc
+		drainCtx, cancelDrain = context.WithTimeout(shutdownCtx, cfg.ConnDrainTimeout)
+		defer cancelDrain()
+	}
+	result, drainErr := conns.Shutdown(drainCtx, srv)
+	logger.Info(fmt.Sprintf("HTTP connections closed: %d gracefully, %d forcibly", result.Graceful, len(result.Forced)))
+	if len(result.Forced) > 0 {
+		metrics.ForcedConnectionClosesTotal.Add(float64(len(result.Forced)))
+		logger.Debug(fmt.Sprintf("Force-closed connections from %s", strings.Join(result.Forced, ", ")))
+	}
+
+	var cleanupErr error
+	if opts.Cleanup != nil {
+		cleanupErr = opts.Cleanup(shutdownCtx)
+	}
+	drained := time.Since(started)
+	if err := errors.Join(serveErr, drainErr, cleanupErr); err != nil {
+		return fmt.Errorf("forced to shut down after %.2fs (timeout %s): %w", drained.Seconds(), cfg.ShutdownTimeout, err)
+	}
+	logger.Info(fmt.Sprintf("Server exited after draining for %.2fs", drained.Seconds()))
+	return nil
+}
+
+// cleanup runs opts.Cleanup within the shutdown timeout, for a server that
+// failed to start
+func cleanup(cfg *config.Config, opts Options) error {
+	if opts.Cleanup == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	return opts.Cleanup(ctx)
+}
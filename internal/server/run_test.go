@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/config"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string) {}
+func (nopLogger) Info(string)  {}
+func (nopLogger) Warn(string)  {}
+func (nopLogger) Error(string) {}
+
+// freePort returns a port nothing is listening on
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		// hold keeps requests in flight for this long
+		hold time.Duration
+		// occupied binds the port before Run does
+		occupied bool
+		wantErr  bool
+	}{
+		{name: "clean shutdown"},
+		{name: "drain exceeds timeout", hold: 2 * time.Second, wantErr: true},
+		{name: "listen fails", occupied: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := freePort(t)
+			if tt.occupied {
+				ln, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer ln.Close()
+			}
+			cfg := &config.Config{Port: port, ShutdownTimeout: 200 * time.Millisecond}
+
+			inFlight := make(chan struct{})
+			var once sync.Once
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				once.Do(func() { close(inFlight) })
+				time.Sleep(tt.hold)
+			})
+
+			var configured bool
+			cleanedUp := make(chan bool, 1)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			errc := make(chan error, 1)
+			go func() {
+				errc <- Run(ctx, cfg, handler, Options{
+					Logger:    nopLogger{},
+					Configure: func(*http.Server) { configured = true },
+					Cleanup: func(ctx context.Context) error {
+						_, ok := ctx.Deadline()
+						cleanedUp <- ok
+						return nil
+					},
+				})
+			}()
+
+			if !tt.occupied {
+				go waitAndGet(port)
+				select {
+				case <-inFlight:
+				case err := <-errc:
+					t.Fatalf("Run returned before serving: %v", err)
+				}
+			}
+			cancel()
+
+			select {
+			case err := <-errc:
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("Run error = %v, want error %v", err, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run did not return after its context was cancelled")
+			}
+			select {
+			case bounded := <-cleanedUp:
+				if !bounded {
+					t.Error("cleanup ran without the shutdown deadline")
+				}
+			default:
+				t.Error("cleanup did not run")
+			}
+			if !configured {
+				t.Error("Configure was not called")
+			}
+		})
+	}
+}
+
+// waitAndGet requests / on port until the server answers
+func waitAndGet(port int) {
+	for range 50 {
+		resp, err := http.Get("http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) + "/")
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
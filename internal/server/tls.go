@@ -0,0 +1,60 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/divijg19/Dahlia/internal/config"
+)
+
+// Client certificate verification modes
+const (
+	// ClientAuthRequire rejects connections without a valid client certificate
+	ClientAuthRequire = "require"
+	// ClientAuthVerifyIfGiven verifies client certificates only when presented
+	ClientAuthVerifyIfGiven = "verify-if-given"
+)
+
+// TLSConfig builds the server TLS configuration. It returns nil when TLS is
+// not enabled. When a client CA bundle is configured, client certificates are
+// verified against it according to cfg.ClientAuthMode.
+func TLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if cfg.TLSCertFile == "" {
+		if cfg.ClientCAFile != "" {
+			return nil, fmt.Errorf("client CA file requires TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if cfg.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("client CA file %s contains no certificates", cfg.ClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+
+	switch cfg.ClientAuthMode {
+	case ClientAuthRequire, "":
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	case ClientAuthVerifyIfGiven:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q", cfg.ClientAuthMode)
+	}
+	return tlsConfig, nil
+}
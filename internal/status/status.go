@@ -0,0 +1,29 @@
+// Package status builds the service status returned by both the HTTP and
+// gRPC APIs, so the two always report the same fields and uptime
+package status
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/buildinfo"
+	"github.com/divijg19/Dahlia/internal/pb/dahliav1"
+)
+
+// started stands in for the process start time: package variables are
+// initialized before main runs
+var started = time.Now()
+
+// Current snapshots build and runtime details for status responses
+func Current() *dahliav1.Status {
+	return &dahliav1.Status{
+		Service:    "dahlia",
+		Version:    buildinfo.Version,
+		Uptime:     time.Since(started).String(),
+		Status:     "running",
+		Commit:     buildinfo.Commit,
+		BuildTime:  buildinfo.BuildTime,
+		GoVersion:  runtime.Version(),
+		Goroutines: int32(runtime.NumGoroutine()),
+	}
+}
@@ -0,0 +1,127 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Supported span exporters
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Provider is the installed tracer provider, counting sampled spans as they
+// end and as they are exported so shutdown can report what was lost
+type Provider struct {
+	*sdktrace.TracerProvider
+	ended    atomic.Int64
+	exported atomic.Int64
+}
+
+// ShutdownStats reports the outcome of Provider.Shutdown
+type ShutdownStats struct {
+	// Flushed is the number of spans exported while shutting down
+	Flushed int64
+	// Dropped is the number of sampled spans that were never exported,
+	// because the queue was full, an export failed or the flush timed out
+	Dropped int64
+}
+
+// Shutdown flushes queued spans to the exporter and stops the provider,
+// giving up when ctx is done
+func (p *Provider) Shutdown(ctx context.Context) (ShutdownStats, error) {
+	before := p.exported.Load()
+	err := p.TracerProvider.Shutdown(ctx)
+	after := p.exported.Load()
+	return ShutdownStats{
+		Flushed: after - before,
+		Dropped: p.ended.Load() - after,
+	}, err
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagator.
+// Requests are sampled at sampleRate (0.0-1.0), except that a request carrying
+// an incoming traceparent follows the caller's sampling decision. With no
+// exporter tracing is disabled and a no-op provider is installed; the
+// returned provider is nil in that case.
+func Setup(ctx context.Context, exporter string, sampleRate float64) (*Provider, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("trace sample rate %v outside 0.0-1.0", sampleRate)
+	}
+
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone:
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return nil, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		// Endpoint and headers come from the standard OTEL_EXPORTER_OTLP_* variables
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s exporter: %w", exporter, err)
+	}
+
+	p := newProvider(spanExporter, sampleRate)
+	otel.SetTracerProvider(p.TracerProvider)
+	return p, nil
+}
+
+// newProvider creates a provider batching sampled spans to spanExporter
+func newProvider(spanExporter sdktrace.SpanExporter, sampleRate float64) *Provider {
+	p := &Provider{}
+	p.TracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(endCounter{&p.ended}),
+		sdktrace.WithBatcher(countingExporter{SpanExporter: spanExporter, exported: &p.exported}),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate))),
+	)
+	return p
+}
+
+// endCounter counts sampled spans as they end
+type endCounter struct {
+	ended *atomic.Int64
+}
+
+func (endCounter) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (c endCounter) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		c.ended.Add(1)
+	}
+}
+
+func (endCounter) Shutdown(context.Context) error   { return nil }
+func (endCounter) ForceFlush(context.Context) error { return nil }
+
+// countingExporter counts spans the wrapped exporter accepted
+type countingExporter struct {
+	sdktrace.SpanExporter
+	exported *atomic.Int64
+}
+
+func (e countingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	if err == nil {
+		e.exported.Add(int64(len(spans)))
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sampledFraction starts n root spans and returns the fraction sampled
+func sampledFraction(p *Provider, n int) float64 {
+	tracer := p.Tracer("test")
+	sampled := 0
+	for range n {
+		_, span := tracer.Start(context.Background(), "request")
+		if span.SpanContext().IsSampled() {
+			sampled++
+		}
+		span.End()
+	}
+	return float64(sampled) / float64(n)
+}
+
+func TestSampleRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		min, max float64
+	}{
+		{name: "never", rate: 0, min: 0, max: 0},
+		{name: "always", rate: 1, min: 1, max: 1},
+		{name: "fraction", rate: 0.25, min: 0.2, max: 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProvider(tracetest.NewInMemoryExporter(), tt.rate)
+			defer p.Shutdown(context.Background())
+
+			if got := sampledFraction(p, 4000); got < tt.min || got > tt.max {
+				t.Errorf("sampled fraction = %v, want %v-%v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestSampleRateFollowsParent(t *testing.T) {
+	remote := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+			Remote:     true,
+		}))
+	}
+	tests := []struct {
+		name        string
+		rate        float64
+		parent      trace.TraceFlags
+		wantSampled bool
+	}{
+		{name: "sampled parent at rate 0", rate: 0, parent: trace.FlagsSampled, wantSampled: true},
+		{name: "unsampled parent at rate 1", rate: 1, parent: 0, wantSampled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProvider(tracetest.NewInMemoryExporter(), tt.rate)
+			defer p.Shutdown(context.Background())
+
+			_, span := p.Tracer("test").Start(remote(tt.parent), "request")
+			defer span.End()
+			if got := span.SpanContext().IsSampled(); got != tt.wantSampled {
+				t.Errorf("sampled = %v, want %v", got, tt.wantSampled)
+			}
+		})
+	}
+}
+
+func TestSetupRejectsSampleRate(t *testing.T) {
+	for _, rate := range []float64{-0.1, 1.5} {
+		if _, err := Setup(context.Background(), ExporterNone, rate); err == nil {
+			t.Errorf("Setup with sample rate %v succeeded, want an error", rate)
+		}
+	}
+}
+
+// stubExporter counts the spans it is given, failing every export with err
+// when set. Unlike tracetest's exporter it keeps its spans after Shutdown.
+type stubExporter struct {
+	err      error
+	exported atomic.Int64
+}
+
+func (e *stubExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.exported.Add(int64(len(spans)))
+	return nil
+}
+
+func (e *stubExporter) Shutdown(context.Context) error { return nil }
+
+func TestShutdownFlushesSpans(t *testing.T) {
+	tests := []struct {
+		name                  string
+		err                   error
+		wantFlushed, wantDrop int64
+	}{
+		{name: "flushed", wantFlushed: 5},
+		{name: "export fails", err: errors.New("collector unavailable"), wantDrop: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &stubExporter{err: tt.err}
+			p := newProvider(exporter, 1)
+			// the batcher holds the spans until its next scheduled export,
+			// so they are only written by the flush on shutdown
+			sampledFraction(p, 5)
+			if got := exporter.exported.Load(); got != 0 {
+				t.Fatalf("exported %d spans before shutdown, want them still queued", got)
+			}
+
+			// a failed export is reported through the stats, not the error
+			stats, err := p.Shutdown(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (ShutdownStats{Flushed: tt.wantFlushed, Dropped: tt.wantDrop}); stats != want {
+				t.Errorf("stats = %+v, want %+v", stats, want)
+			}
+			if got := exporter.exported.Load(); got != tt.wantFlushed {
+				t.Errorf("exporter received %d spans, want %d", got, tt.wantFlushed)
+			}
+		})
+	}
+}
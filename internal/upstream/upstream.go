@@ -0,0 +1,188 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/divijg19/Dahlia/internal/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created for outbound calls
+const tracerName = "github.com/divijg19/Dahlia/upstream"
+
+// Options configures the timeout and connection pool of an upstream
+type Options struct {
+	// Timeout bounds a single call; zero leaves only the caller's deadline
+	Timeout time.Duration
+	// MaxIdleConns is the number of keep-alive connections kept to the upstream
+	MaxIdleConns int
+	// MaxConns caps connections to the upstream, including active ones; zero is unlimited
+	MaxConns int
+	// IdleConnTimeout closes keep-alive connections unused for this long
+	IdleConnTimeout time.Duration
+	// Latency, when set, records the duration of every call under the
+	// upstream's name
+	Latency *metrics.LatencyWindow
+}
+
+// Client calls a single upstream service, such as the Rust or Python
+// helpers. Every call runs under a deadline that is the earlier of the
+// upstream timeout and the deadline of the caller's context, so an upstream
+// call made while serving a request can't outlive that request, and is
+// cancelled along with it.
+type Client struct {
+	name    string
+	baseURL *url.URL
+	timeout time.Duration
+	http    *http.Client
+	latency *metrics.LatencyWindow
+}
+
+// New creates a client for the upstream called name at baseURL. Errors name
+// the upstream and at most the URL's scheme, since the URL may carry
+// credentials.
+func New(name, baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("upstream %s: invalid URL", name)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("upstream %s: scheme %q must be http or https", name, u.Scheme)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConns
+	transport.MaxConnsPerHost = opts.MaxConns
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+
+	return &Client{
+		name:    name,
+		baseURL: u,
+		timeout: opts.Timeout,
+		http:    &http.Client{Transport: transport},
+		latency: opts.Latency,
+	}, nil
+}
+
+// Name returns the upstream name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// Do sends a request for path, relative to the upstream base URL. The
+// response body must be closed, which also releases the call's deadline.
+func (c *Client) Do(ctx context.Context, method, path string, body io.Reader, header http.Header) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, fmt.Sprintf("%s %s", method, c.name),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("server.address", c.baseURL.Host),
+		),
+	)
+	done := func() {
+		span.End()
+		cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL.JoinPath(path).String(), body)
+	if err != nil {
+		done()
+		return nil, fmt.Errorf("upstream %s: %w", c.name, err)
+	}
+	for k, values := range header {
+		req.Header[k] = values
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if c.latency != nil {
+		c.latency.Record(c.name, time.Since(start))
+	}
+	if err != nil {
+		outcome := "error"
+		if errors.Is(err, context.DeadlineExceeded) {
+			outcome = "timeout"
+		} else if errors.Is(err, context.Canceled) {
+			outcome = "cancelled"
+		}
+		metrics.UpstreamRequestDuration.WithLabelValues(c.name, outcome).Observe(time.Since(start).Seconds())
+		span.SetStatus(codes.Error, outcome)
+		done()
+		return nil, fmt.Errorf("upstream %s: %w", c.name, err)
+	}
+
+	metrics.UpstreamRequestDuration.WithLabelValues(c.name, fmt.Sprintf("%d", resp.StatusCode)).Observe(time.Since(start).Seconds())
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: done}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the pooled keep-alive connections
+func (c *Client) CloseIdleConnections() {
+	c.http.CloseIdleConnections()
+}
+
+// releaseBody releases the call's span and deadline once the body is closed,
+// since cancelling earlier would abort reading it
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// NewSet creates a client for every upstream in urls, applying the per-upstream
+// timeouts and the shared pool settings in opts. Timeouts for upstreams
+// without a URL are rejected.
+func NewSet(urls map[string]string, timeouts map[string]time.Duration, opts Options) (map[string]*Client, error) {
+	var unknown []string
+	for name := range timeouts {
+		if _, ok := urls[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("timeouts for unknown upstreams %s", strings.Join(unknown, ", "))
+	}
+
+	clients := make(map[string]*Client, len(urls))
+	for name, baseURL := range urls {
+		o := opts
+		if d, ok := timeouts[name]; ok {
+			o.Timeout = d
+		}
+		client, err := New(name, baseURL, o)
+		if err != nil {
+			return nil, err
+		}
+		clients[name] = client
+	}
+	return clients, nil
+}
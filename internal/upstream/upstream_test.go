@@ -0,0 +1,69 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewErrorOmitsURL(t *testing.T) {
+	const password = "hunter2"
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "unparseable", url: "http://svc:" + password + "@rust:port", want: "upstream rust: invalid URL"},
+		{name: "wrong scheme", url: "ftp://svc:" + password + "@rust", want: `upstream rust: scheme "ftp" must be http or https`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New("rust", tt.url, Options{})
+			if err == nil {
+				t.Fatal("New() = nil error, want one")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("New() error = %q, want %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), password) {
+				t.Errorf("New() error %q leaks the password", err)
+			}
+		})
+	}
+}
+
+func TestDoCancelsSlowUpstream(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client, err := New("slow", srv.URL, Options{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(context.Background(), http.MethodGet, "/", nil, nil)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Do() = nil error, want the call cancelled at the timeout")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Do() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Do() returned after %s, want about the 50ms timeout", elapsed)
+	}
+}
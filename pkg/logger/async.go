@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Overflow policies for buffered logging
+const (
+	// OverflowBlock makes callers wait for room in a full buffer
+	OverflowBlock = "block"
+	// OverflowDrop discards lines that don't fit in a full buffer
+	OverflowDrop = "drop"
+)
+
+// ValidateBuffer checks buffered logging settings; a size of zero disables
+// buffering
+func ValidateBuffer(size int, policy string) error {
+	if size < 0 {
+		return fmt.Errorf("log buffer size must not be negative, got %d", size)
+	}
+	if policy != OverflowBlock && policy != OverflowDrop {
+		return fmt.Errorf("unknown log overflow policy %q (want %s or %s)", policy, OverflowBlock, OverflowDrop)
+	}
+	return nil
+}
+
+// WithBuffer writes log lines from a background goroutine through a buffer of
+// size lines, so slow output doesn't stall callers. When the buffer is full
+// the overflow policy either blocks the caller or drops the line. A size of
+// zero or less keeps logging synchronous. Call Close to flush on shutdown.
+func WithBuffer(size int, policy string) Option {
+	return func(l *Logger) {
+		if size <= 0 {
+			return
+		}
+		l.async = newAsyncWriter(size, policy == OverflowDrop, l.onDrop, l.writeEntry)
+		l.async.drainTimeout = l.drainTimeout
+	}
+}
+
+// WithDrainTimeout bounds how long Close waits for buffered lines to be
+// written, so a stuck writer can't hang shutdown. Lines still buffered when
+// it expires are dropped and counted. Zero or less waits indefinitely.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(l *Logger) {
+		l.drainTimeout = d
+		if l.async != nil {
+			l.async.drainTimeout = d
+		}
+	}
+}
+
+// WithDropHook registers fn to be called for every line dropped by a full
+// buffer in drop mode
+func WithDropHook(fn func()) Option {
+	return func(l *Logger) {
+		l.onDrop = fn
+		if l.async != nil {
+			l.async.onDrop = fn
+		}
+	}
+}
+
+// Close logs any pending rate limit summary, flushes buffered log lines and
+// stops the background writer. If the drain timeout expires first, the
+// remaining lines are dropped and the count is logged synchronously at ERROR,
+// which goes to the error output in case the normal one is the stuck writer.
+// Lines logged afterwards are written synchronously.
+func (l *Logger) Close() error {
+	if l.limiter != nil {
+		l.limiter.close()
+	}
+	if l.async != nil {
+		if dropped := l.async.close(); dropped > 0 {
+			l.write(ERROR, fmt.Sprintf("Log drain timed out after %s, dropped %d buffered lines", l.async.drainTimeout, dropped))
+		}
+	}
+	return nil
+}
+
+// Dropped returns the number of lines discarded because the buffer was full
+func (l *Logger) Dropped() uint64 {
+	if l.async == nil {
+		return 0
+	}
+	return l.async.dropped.Load()
+}
+
+type entry struct {
+	level LogLevel
+	line  []byte
+}
+
+// asyncWriter drains queued log lines in a background goroutine
+type asyncWriter struct {
+	mu      sync.RWMutex
+	closed  bool
+	entries chan entry
+	done    chan struct{}
+	sink    func(entry)
+
+	// closing releases writers blocked on a full buffer so close can take
+	// the lock; abandoned makes run discard what is left after the drain
+	// timeout
+	closing      chan struct{}
+	closeOnce    sync.Once
+	drainTimeout time.Duration
+	abandoned    atomic.Bool
+
+	drop    bool
+	onDrop  func()
+	dropped atomic.Uint64
+}
+
+func newAsyncWriter(size int, drop bool, onDrop func(), sink func(entry)) *asyncWriter {
+	w := &asyncWriter{
+		entries: make(chan entry, size),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+		sink:    sink,
+		drop:    drop,
+		onDrop:  onDrop,
+	}
+	go w.run()
+	return w
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+	for e := range w.entries {
+		if w.abandoned.Load() {
+			continue
+		}
+		w.sink(e)
+	}
+}
+
+// write queues a line, falling back to a direct write once closed, made
+// without holding the lock, so a stuck writer can't block close
+func (w *asyncWriter) write(e entry) {
+	if !w.enqueue(e) {
+		w.sink(e)
+	}
+}
+
+// enqueue queues a line under the read lock, reporting false when it must be
+// written directly because the writer is closed or closing
+func (w *asyncWriter) enqueue(e entry) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return false
+	}
+	if !w.drop {
+		select {
+		case w.entries <- e:
+			return true
+		case <-w.closing:
+			return false
+		}
+	}
+
+	select {
+	case w.entries <- e:
+	default:
+		w.dropped.Add(1)
+		if w.onDrop != nil {
+			w.onDrop()
+		}
+	}
+	return true
+}
+
+// close stops accepting lines and waits for the queue to drain, up to the
+// drain timeout. It returns the number of lines dropped because the timeout
+// expired.
+func (w *asyncWriter) close() int {
+	w.closeOnce.Do(func() { close(w.closing) })
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.entries)
+	}
+	w.mu.Unlock()
+
+	if w.drainTimeout <= 0 {
+		<-w.done
+		return 0
+	}
+	timer := time.NewTimer(w.drainTimeout)
+	defer timer.Stop()
+	select {
+	case <-w.done:
+		return 0
+	case <-timer.C:
+	}
+
+	w.abandoned.Store(true)
+	dropped := len(w.entries)
+	w.dropped.Add(uint64(dropped))
+	if w.onDrop != nil {
+		for range dropped {
+			w.onDrop()
+		}
+	}
+	return dropped
+}
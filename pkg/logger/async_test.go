@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedWriter blocks every write until release is closed, signalling on
+// started when a write begins
+type gatedWriter struct {
+	syncBuffer
+	started chan struct{}
+	release chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.release
+	return w.syncBuffer.Write(p)
+}
+
+func TestBufferDropsOnOverflow(t *testing.T) {
+	out := newGatedWriter()
+	var hooked atomic.Int64
+	l := New("info", WithOutput(out, out), WithColor(ColorNever), WithBuffer(2, OverflowDrop), WithDropHook(func() { hooked.Add(1) }))
+
+	// The first line is held by the background writer, the next two fill
+	// the buffer and the rest overflow
+	l.Info("line 0")
+	<-out.started
+	for i := 1; i < 6; i++ {
+		l.Info(fmt.Sprintf("line %d", i))
+	}
+
+	if got := l.Dropped(); got != 3 {
+		t.Errorf("dropped = %d, want 3", got)
+	}
+	if got := hooked.Load(); got != 3 {
+		t.Errorf("drop hook calls = %d, want 3", got)
+	}
+
+	close(out.release)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	written := out.String()
+	for i := range 6 {
+		line := fmt.Sprintf("line %d\n", i)
+		if want := i < 3; strings.Contains(written, line) != want {
+			t.Errorf("%q written = %v, want %v in %q", line, !want, want, written)
+		}
+	}
+}
+
+func TestBufferBlocksOnOverflow(t *testing.T) {
+	out := newGatedWriter()
+	l := New("info", WithOutput(out, out), WithColor(ColorNever), WithBuffer(1, OverflowBlock))
+
+	l.Info("line 0")
+	<-out.started
+	l.Info("line 1")
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		l.Info("line 2")
+	}()
+	select {
+	case <-logged:
+		t.Fatal("logging into a full buffer returned in block mode")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(out.release)
+	<-logged
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Dropped(); got != 0 {
+		t.Errorf("dropped = %d, want 0 in block mode", got)
+	}
+	if got := strings.Count(out.String(), "\n"); got != 3 {
+		t.Errorf("wrote %d lines, want 3: %q", got, out.String())
+	}
+}
+
+func TestCloseDrainTimeout(t *testing.T) {
+	out := newGatedWriter()
+	var errOut syncBuffer
+	const timeout = 30 * time.Millisecond
+	l := New("info", WithOutput(out, &errOut), WithColor(ColorNever), WithBuffer(4, OverflowBlock), WithDrainTimeout(timeout))
+	defer close(out.release)
+
+	// The first line is stuck in the writer, the other two stay buffered
+	l.Info("line 0")
+	<-out.started
+	l.Info("line 1")
+	l.Info("line 2")
+
+	closed := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		l.Close()
+		closed <- time.Since(start)
+	}()
+	select {
+	case elapsed := <-closed:
+		if elapsed < timeout {
+			t.Errorf("Close returned after %s, want it to wait the %s drain timeout", elapsed, timeout)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close hung on a stuck writer despite the drain timeout")
+	}
+
+	if got := l.Dropped(); got != 2 {
+		t.Errorf("dropped = %d, want 2", got)
+	}
+	if got := errOut.String(); !strings.Contains(got, "Log drain timed out after 30ms, dropped 2 buffered lines") {
+		t.Errorf("error output = %q, want the dropped lines reported", got)
+	}
+}
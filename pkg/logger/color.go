@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Color modes
+const (
+	// ColorAuto colors text output written to a terminal unless NO_COLOR is set
+	ColorAuto = "auto"
+	// ColorAlways colors text output regardless of the destination
+	ColorAlways = "always"
+	// ColorNever disables color
+	ColorNever = "never"
+)
+
+// ANSI color codes for each level token
+var levelColors = map[LogLevel]string{
+	DEBUG: "\x1b[90m",
+	INFO:  "\x1b[36m",
+	WARN:  "\x1b[33m",
+	ERROR: "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// ValidateColor checks a color mode
+func ValidateColor(mode string) error {
+	switch strings.ToLower(mode) {
+	case ColorAuto, ColorAlways, ColorNever:
+		return nil
+	default:
+		return fmt.Errorf("unknown log color mode %q (want %s, %s or %s)", mode, ColorAuto, ColorAlways, ColorNever)
+	}
+}
+
+// WithColor sets when level tokens in text output are colored: "auto" (the
+// default) colors them on each output stream only when that stream is a
+// terminal and NO_COLOR is unset, "always" forces color and "never"
+// disables it. JSON and syslog output are never colored.
+func WithColor(mode string) Option {
+	return func(l *Logger) {
+		if ValidateColor(mode) == nil {
+			l.colorMode = strings.ToLower(mode)
+		}
+	}
+}
+
+// resolveColor decides whether each output stream gets color
+func (l *Logger) resolveColor() {
+	o := l.output
+	switch l.colorMode {
+	case ColorAlways:
+		o.colorOut.Store(true)
+		o.colorErr.Store(true)
+	case ColorNever:
+		o.colorOut.Store(false)
+		o.colorErr.Store(false)
+	default:
+		_, noColor := os.LookupEnv("NO_COLOR")
+		o.colorOut.Store(!noColor && isTerminal(o.out.Writer()))
+		o.colorErr.Store(!noColor && isTerminal(o.err.Writer()))
+	}
+}
+
+// isTerminal reports whether w is a file attached to a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// levelToken returns the level name, colored when the destination stream
+// supports it
+func (l *Logger) levelToken(level LogLevel) string {
+	colored := l.output.colorOut.Load()
+	if l.output.toErr(level) {
+		colored = l.output.colorErr.Load()
+	}
+	if !colored || l.syslog != nil {
+		return level.String()
+	}
+	return levelColors[level] + level.String() + colorReset
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		format string
+		want   string
+	}{
+		{name: "always", mode: ColorAlways, format: FormatText, want: "[\x1b[33mWARN\x1b[0m] careful\n"},
+		{name: "never", mode: ColorNever, format: FormatText, want: "[WARN] careful\n"},
+		{name: "auto to a buffer", mode: ColorAuto, format: FormatText, want: "[WARN] careful\n"},
+		{name: "json is never colored", mode: ColorAlways, format: FormatJSON, want: `"level":"WARN"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			l := New("info", WithFormat(tt.format), WithOutput(&out, &out), WithColor(tt.mode))
+			l.Warn("careful")
+			if got := out.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("output = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorAutoTerminalOnly(t *testing.T) {
+	tty, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal: %v", err)
+	}
+	defer tty.Close()
+	if !isTerminal(tty) {
+		t.Skip("/dev/ptmx isn't reported as a terminal")
+	}
+	pipeR, pipe, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pipeR.Close()
+	defer pipe.Close()
+
+	colored := func(l *Logger, level LogLevel) bool {
+		return strings.Contains(l.levelToken(level), "\x1b[")
+	}
+
+	// Setenv restores NO_COLOR afterwards; it must be unset, not empty
+	t.Setenv("NO_COLOR", "")
+	os.Unsetenv("NO_COLOR")
+	l := New("info", WithOutput(tty, pipe), WithColor(ColorAuto))
+	if !colored(l, INFO) {
+		t.Error("INFO to a terminal isn't colored")
+	}
+	if colored(l, ERROR) {
+		t.Error("ERROR to a pipe is colored")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	l = New("info", WithOutput(tty, tty), WithColor(ColorAuto))
+	if colored(l, INFO) {
+		t.Error("terminal output colored with NO_COLOR set")
+	}
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Escalator raises a logger's level while the service is unhealthy, to
+// capture extra detail during an incident, and restores the configured
+// level once it has been healthy for the restore delay
+type Escalator struct {
+	logger       *Logger
+	level        string
+	restoreDelay time.Duration
+
+	mu        sync.Mutex
+	base      string
+	escalated bool
+	restore   *time.Timer
+	// generation is advanced whenever a pending restore is cancelled, so a
+	// timer that already fired and is waiting on mu can tell it is stale
+	generation uint64
+}
+
+// NewEscalator creates an escalator switching l to level while unhealthy.
+// base is the level to restore, normally the configured log level.
+func NewEscalator(l *Logger, level, base string, restoreDelay time.Duration) *Escalator {
+	return &Escalator{
+		logger:       l,
+		level:        level,
+		restoreDelay: restoreDelay,
+		base:         base,
+	}
+}
+
+// SetBaseLevel changes the level restored after an incident, applying it
+// immediately unless the logger is currently escalated. Use it in place of
+// Logger.SetLevel so a config reload doesn't cut an incident short.
+func (e *Escalator) SetBaseLevel(level string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.base = level
+	if !e.escalated {
+		e.logger.SetLevel(level)
+	}
+}
+
+// Update records the service health. Becoming unhealthy escalates the log
+// level at once; becoming healthy restores it after the restore delay,
+// unless health is lost again first.
+func (e *Escalator) Update(healthy bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !healthy {
+		if e.restore != nil {
+			e.restore.Stop()
+			e.restore = nil
+			e.generation++
+		}
+		if !e.escalated {
+			e.escalated = true
+			e.logger.SetLevel(e.level)
+			e.logger.Warn(fmt.Sprintf("Service unhealthy, raising log level to %s", e.level))
+		}
+		return
+	}
+
+	if !e.escalated || e.restore != nil {
+		return
+	}
+	generation := e.generation
+	e.restore = time.AfterFunc(e.restoreDelay, func() { e.restoreLevel(generation) })
+}
+
+// restoreLevel ends the incident scheduled for restore in generation, unless
+// health was lost again since
+func (e *Escalator) restoreLevel(generation uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.escalated || generation != e.generation {
+		return
+	}
+	e.escalated = false
+	e.restore = nil
+	e.logger.Info(fmt.Sprintf("Service healthy again, restoring log level to %s", e.base))
+	e.logger.SetLevel(e.base)
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from the restore timer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// debugEnabled reports whether l currently writes debug lines to out
+func debugEnabled(l *Logger, out *syncBuffer, probe string) bool {
+	l.Debug(probe)
+	return strings.Contains(out.String(), probe)
+}
+
+func TestEscalatorRestoresAfterDelay(t *testing.T) {
+	var out syncBuffer
+	l := New("info", WithOutput(&out, &out), WithColor(ColorNever))
+	e := NewEscalator(l, "debug", "info", 20*time.Millisecond)
+
+	e.Update(false)
+	if !debugEnabled(l, &out, "while unhealthy") {
+		t.Fatal("level not raised to debug while unhealthy")
+	}
+	e.Update(true)
+	if !debugEnabled(l, &out, "before restore delay") {
+		t.Fatal("level restored before the restore delay")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for i := 0; debugEnabled(l, &out, fmt.Sprintf("waiting for restore %d", i)); i++ {
+		if time.Now().After(deadline) {
+			t.Fatal("level not restored to info after the restore delay")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEscalatorStaleRestoreKeepsLevel(t *testing.T) {
+	var out syncBuffer
+	l := New("info", WithOutput(&out, &out), WithColor(ColorNever))
+	e := NewEscalator(l, "debug", "info", time.Hour)
+
+	e.Update(false)
+	e.Update(true)
+	stale := e.generation
+
+	// The restore timer fires but, before it takes the lock, health is lost
+	// again: Stop can no longer prevent it, so it must notice it is stale
+	e.Update(false)
+	e.restoreLevel(stale)
+
+	if !debugEnabled(l, &out, "after stale restore") {
+		t.Fatal("stale restore lowered the level while unhealthy")
+	}
+
+	// A restore scheduled after the new incident still applies
+	e.Update(true)
+	e.restoreLevel(e.generation)
+	if debugEnabled(l, &out, "after restore") {
+		t.Fatal("level not restored to info")
+	}
+}
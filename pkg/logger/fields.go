@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// field is a key/value pair attached to every line of a derived logger
+type field struct {
+	key   string
+	value any
+}
+
+// WithFields returns a logger that adds fields to every line it writes. The
+// derived logger shares the parent's level, stack level, output and buffer,
+// so changing the level of either affects both; the parent's own fields are
+// kept and those in fields override them. The parent is not modified.
+func (l *Logger) WithFields(fields map[string]any) *Logger {
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	for _, f := range l.fields {
+		merged[f.key] = f.value
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	derived := &Logger{
+		level:        l.level,
+		stackEnabled: l.stackEnabled,
+		stackLevel:   l.stackLevel,
+		format:       l.format,
+		keys:         l.keys,
+		order:        l.order,
+		now:          l.now,
+		async:        l.async,
+		onDrop:       l.onDrop,
+		syslog:       l.syslog,
+		limiter:      l.limiter,
+		onSuppress:   l.onSuppress,
+		output:       l.output,
+		colorMode:    l.colorMode,
+		fields:       make([]field, 0, len(keys)),
+	}
+	for _, k := range keys {
+		derived.fields = append(derived.fields, field{key: k, value: merged[k]})
+	}
+	return derived
+}
+
+// appendTextFields renders fields as key=value pairs, quoting values that
+// contain spaces, quotes or equal signs
+func appendTextFields(msg string, fields []field) string {
+	if len(fields) == 0 {
+		return msg
+	}
+	var b strings.Builder
+	b.WriteString(msg)
+	for _, f := range fields {
+		v := sanitize(fmt.Sprint(f.value))
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			v = strconv.Quote(v)
+		}
+		b.WriteByte(' ')
+		b.WriteString(sanitize(f.key))
+		b.WriteByte('=')
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// appendJSONFields adds each field as its own key. Fields named like a
+// standard field are prefixed with "field." so they can't replace it.
+func (l *Logger) appendJSONFields(b []byte) []byte {
+	for _, f := range l.fields {
+		key := f.key
+		switch key {
+		case l.keys.Level, l.keys.Timestamp, l.keys.Message, l.keys.Stack:
+			key = "field." + key
+		}
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(f.value))
+		}
+		k, _ := json.Marshal(key)
+		if b[len(b)-1] != '{' {
+			b = append(b, ',')
+		}
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	return b
+}
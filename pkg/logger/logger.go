@@ -1,14 +1,160 @@
 package logger
 
 import (
-	"log"
-	"os"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
 // Logger provides structured logging capabilities
 type Logger struct {
-	level LogLevel
+	level *atomic.Int32
+
+	// stack traces are attached to messages at or above stackLevel; like
+	// level these are shared with loggers derived by WithFields
+	stackEnabled *atomic.Bool
+	stackLevel   *atomic.Int32
+
+	format string
+	keys   FieldKeys
+	order  []string
+
+	// now timestamps log lines; see WithClock
+	now func() time.Time
+
+	// async is set when output is buffered; see WithBuffer
+	async        *asyncWriter
+	onDrop       func()
+	drainTimeout time.Duration
+
+	// syslog replaces stdout and stderr when set; see WithSyslog
+	syslog SyslogWriter
+
+	// limiter drops lines over the per-level rate limits; see WithRateLimits
+	limiter    *rateLimiter
+	onSuppress func(LogLevel)
+
+	// fields are added to every line, sorted by key; see WithFields
+	fields []field
+
+	// output holds the writers for normal and error lines; see WithOutput
+	output *output
+
+	// colorMode decides whether level tokens in text output are colored;
+	// see WithColor
+	colorMode string
+}
+
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// FormatForEnvironment returns the default output format for environment:
+// JSON in production, where lines are usually shipped to a log pipeline, and
+// human-friendly text everywhere else
+func FormatForEnvironment(environment string) string {
+	if strings.EqualFold(environment, "production") {
+		return FormatJSON
+	}
+	return FormatText
+}
+
+// FieldKeys names the standard fields of JSON log lines
+type FieldKeys struct {
+	Level     string
+	Timestamp string
+	Message   string
+	Stack     string
+}
+
+// DefaultFieldKeys are the JSON field names used unless remapped
+var DefaultFieldKeys = FieldKeys{
+	Level:     "level",
+	Timestamp: "timestamp",
+	Message:   "message",
+	Stack:     "stack",
+}
+
+// ParseFieldKeys applies a mapping from standard field names (level,
+// timestamp, message, stack) to custom ones on top of DefaultFieldKeys. It
+// rejects unknown standard names and mappings where two fields would share a
+// key.
+func ParseFieldKeys(mapping map[string]string) (FieldKeys, error) {
+	keys := DefaultFieldKeys
+	for field, key := range mapping {
+		if key == "" {
+			return keys, fmt.Errorf("empty key for log field %q", field)
+		}
+		switch field {
+		case "level":
+			keys.Level = key
+		case "timestamp":
+			keys.Timestamp = key
+		case "message":
+			keys.Message = key
+		case "stack":
+			keys.Stack = key
+		default:
+			return keys, fmt.Errorf("unknown log field %q", field)
+		}
+	}
+
+	seen := make(map[string]string, 4)
+	for _, f := range []struct{ field, key string }{
+		{"level", keys.Level},
+		{"timestamp", keys.Timestamp},
+		{"message", keys.Message},
+		{"stack", keys.Stack},
+	} {
+		if other, ok := seen[f.key]; ok {
+			return keys, fmt.Errorf("log fields %q and %q both map to key %q", other, f.field, f.key)
+		}
+		seen[f.key] = f.field
+	}
+	return keys, nil
+}
+
+// DefaultFieldOrder is the order of JSON log line fields unless configured
+// otherwise; "fields" stands for the fields added by WithFields, sorted by key
+var DefaultFieldOrder = []string{"timestamp", "level", "message", "fields", "stack"}
+
+// ParseFieldOrder validates an order of JSON log line fields, named like
+// DefaultFieldOrder. Fields left out keep their default relative order after
+// the listed ones, so "level,message" puts those two first. It rejects
+// unknown and repeated names.
+func ParseFieldOrder(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return DefaultFieldOrder, nil
+	}
+
+	listed := make(map[string]bool, len(DefaultFieldOrder))
+	for _, name := range DefaultFieldOrder {
+		listed[name] = false
+	}
+	order := make([]string, 0, len(DefaultFieldOrder))
+	for _, name := range names {
+		done, known := listed[name]
+		switch {
+		case !known:
+			return nil, fmt.Errorf("unknown log field %q in field order (want %s)", name, strings.Join(DefaultFieldOrder, ", "))
+		case done:
+			return nil, fmt.Errorf("log field %q appears twice in field order", name)
+		}
+		listed[name] = true
+		order = append(order, name)
+	}
+	for _, name := range DefaultFieldOrder {
+		if !listed[name] {
+			order = append(order, name)
+		}
+	}
+	return order, nil
 }
 
 // LogLevel represents different log levels
@@ -17,50 +163,242 @@ type LogLevel int
 const (
 	DEBUG LogLevel = iota
 	INFO
+	WARN
 	ERROR
 )
 
-// New creates a new logger instance
-func New(level string) *Logger {
-	var logLevel LogLevel
+// String returns the level name as printed in log lines
+func (l LogLevel) String() string {
+	switch l {
+	case DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case WARN:
+		return "WARN"
+	case ERROR:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel converts a level name to a LogLevel, reporting whether it was recognized
+func ParseLevel(level string) (LogLevel, bool) {
 	switch strings.ToLower(level) {
 	case "debug":
-		logLevel = DEBUG
+		return DEBUG, true
 	case "info":
-		logLevel = INFO
+		return INFO, true
+	case "warn", "warning":
+		return WARN, true
 	case "error":
-		logLevel = ERROR
+		return ERROR, true
 	default:
-		logLevel = INFO
+		return INFO, false
 	}
+}
+
+// Option configures optional Logger behavior
+type Option func(*Logger)
 
-	return &Logger{
-		level: logLevel,
+// WithStackLevel attaches a stack trace to every message at or above level.
+// An empty or unrecognized level leaves stack traces disabled, which is the
+// default because capturing them is expensive.
+func WithStackLevel(level string) Option {
+	return func(l *Logger) {
+		l.SetStackLevel(level)
 	}
 }
 
-var (
-    outLogger = log.New(os.Stdout, "", log.LstdFlags)
-    errLogger = log.New(os.Stderr, "", log.LstdFlags)
-)
+// WithFormat selects the output format: "text" (the default) or "json"
+func WithFormat(format string) Option {
+	return func(l *Logger) {
+		if strings.EqualFold(format, FormatJSON) {
+			l.format = FormatJSON
+		}
+	}
+}
+
+// WithFieldKeys renames the standard fields of JSON log lines
+func WithFieldKeys(keys FieldKeys) Option {
+	return func(l *Logger) {
+		l.keys = keys
+	}
+}
+
+// WithFieldOrder sets the order of JSON log line fields, as returned by
+// ParseFieldOrder
+func WithFieldOrder(order []string) Option {
+	return func(l *Logger) {
+		if len(order) > 0 {
+			l.order = order
+		}
+	}
+}
+
+// WithClock makes the logger take timestamps from now instead of the system
+// clock, so tests can assert exact output
+func WithClock(now func() time.Time) Option {
+	return func(l *Logger) {
+		if now != nil {
+			l.now = now
+		}
+	}
+}
+
+// New creates a new logger instance
+func New(level string, opts ...Option) *Logger {
+	logLevel, _ := ParseLevel(level)
+
+	l := &Logger{
+		level:        new(atomic.Int32),
+		stackEnabled: new(atomic.Bool),
+		stackLevel:   new(atomic.Int32),
+		format:       FormatText,
+		keys:         DefaultFieldKeys,
+		order:        DefaultFieldOrder,
+		now:          time.Now,
+		output:       defaultOutput(),
+		colorMode:    ColorAuto,
+	}
+	l.level.Store(int32(logLevel))
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.resolveColor()
+	return l
+}
+
+// SetLevel changes the minimum level logged. It is safe to call while other
+// goroutines are logging.
+func (l *Logger) SetLevel(level string) {
+	logLevel, _ := ParseLevel(level)
+	l.level.Store(int32(logLevel))
+}
+
+// SetStackLevel changes the level at which stack traces are attached; an
+// empty or unrecognized level disables them
+func (l *Logger) SetStackLevel(level string) {
+	parsed, ok := ParseLevel(level)
+	l.stackLevel.Store(int32(parsed))
+	l.stackEnabled.Store(ok)
+}
 
 // Debug logs debug messages
 func (l *Logger) Debug(msg string) {
-	if l.level <= DEBUG {
-		outLogger.Printf("[DEBUG] %s", msg)
-	}
+	l.log(DEBUG, msg)
 }
 
 // Info logs info messages
 func (l *Logger) Info(msg string) {
-	if l.level <= INFO {
-		outLogger.Printf("[INFO] %s", msg)
-	}
+	l.log(INFO, msg)
+}
+
+// Warn logs warning messages
+func (l *Logger) Warn(msg string) {
+	l.log(WARN, msg)
 }
 
 // Error logs error messages
 func (l *Logger) Error(msg string) {
-	if l.level <= ERROR {
-		errLogger.Printf("[ERROR] %s", msg)
+	l.log(ERROR, msg)
+}
+
+func (l *Logger) log(level LogLevel, msg string) {
+	if level < LogLevel(l.level.Load()) {
+		return
+	}
+	if l.limiter != nil && !l.limiter.allow(level, l.now()) {
+		return
+	}
+	l.write(level, msg)
+}
+
+// write formats and outputs a line that passed level and rate filtering
+func (l *Logger) write(level LogLevel, msg string) {
+	msg = sanitize(msg)
+	var stack string
+	if l.stackEnabled.Load() && level >= LogLevel(l.stackLevel.Load()) {
+		stack = string(debug.Stack())
+	}
+
+	var line []byte
+	if l.format == FormatJSON {
+		line = l.encodeJSON(level, msg, stack)
+	} else {
+		msg = appendTextFields(msg, l.fields)
+		if stack != "" {
+			msg += "\nstack=" + stack
+		}
+		if l.syslog != nil {
+			// syslog records its own timestamp
+			line = fmt.Appendf(nil, "[%s] %s\n", level, msg)
+		} else {
+			line = fmt.Appendf(nil, "%s [%s] %s\n", l.now().Format("2006/01/02 15:04:05"), l.levelToken(level), msg)
+		}
+	}
+
+	e := entry{level: level, line: line}
+	if l.async != nil {
+		l.async.write(e)
+		return
+	}
+	l.writeEntry(e)
+}
+
+// writeEntry writes a formatted line to syslog or the output stream for its
+// level
+func (l *Logger) writeEntry(e entry) {
+	if l.syslog != nil {
+		writeSyslog(l.syslog, e.level, string(e.line))
+		return
+	}
+	l.output.stream(e.level).Print(string(e.line))
+}
+
+// encodeJSON renders a single JSON log line using the configured field keys,
+// in the configured field order
+func (l *Logger) encodeJSON(level LogLevel, msg, stack string) []byte {
+	b := make([]byte, 0, len(msg)+64)
+	b = append(b, '{')
+	for _, name := range l.order {
+		switch name {
+		case "timestamp":
+			b = appendField(b, l.keys.Timestamp, l.now().Format(time.RFC3339))
+		case "level":
+			b = appendField(b, l.keys.Level, level.String())
+		case "message":
+			b = appendField(b, l.keys.Message, msg)
+		case "fields":
+			b = l.appendJSONFields(b)
+		case "stack":
+			if stack != "" {
+				b = appendField(b, l.keys.Stack, stack)
+			}
+		}
 	}
-}
\ No newline at end of file
+	return append(b, '}', '\n')
+}
+
+// appendField adds a key/value pair, preceded by a comma unless it is the
+// first in the object
+func appendField(b []byte, key, value string) []byte {
+	if b[len(b)-1] != '{' {
+		b = append(b, ',')
+	}
+	k, _ := json.Marshal(key)
+	v, _ := json.Marshal(value)
+	b = append(b, k...)
+	b = append(b, ':')
+	return append(b, v...)
+}
+
+// sanitize replaces invalid UTF-8 byte sequences with the Unicode replacement
+// character so accidentally logged binary data can't corrupt the output
+func sanitize(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
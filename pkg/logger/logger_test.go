@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestInvalidUTF8(t *testing.T) {
+	const invalid = "bad \xff\xfe bytes"
+	tests := []struct {
+		name   string
+		format string
+		fields map[string]any
+		msg    string
+	}{
+		{name: "json message", format: FormatJSON, msg: invalid},
+		{name: "json field value", format: FormatJSON, msg: "ok", fields: map[string]any{"payload": invalid}},
+		{name: "json field key", format: FormatJSON, msg: "ok", fields: map[string]any{"k\xff": "v"}},
+		{name: "text message", format: FormatText, msg: invalid},
+		{name: "text field", format: FormatText, msg: "ok", fields: map[string]any{"k\xff": invalid}},
+	}
+
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			l := New("info", WithFormat(tt.format), WithOutput(&out, &out), WithColor(ColorNever), WithClock(func() time.Time { return fixed }))
+			if tt.fields != nil {
+				l = l.WithFields(tt.fields)
+			}
+			l.Info(tt.msg)
+
+			line := strings.TrimSuffix(out.String(), "\n")
+			if !utf8.ValidString(line) {
+				t.Fatalf("output %q is not valid UTF-8", line)
+			}
+			if !strings.Contains(line, "�") {
+				t.Fatalf("output %q doesn't mark the invalid bytes with U+FFFD", line)
+			}
+			if tt.format != FormatJSON {
+				return
+			}
+			var decoded map[string]any
+			if err := json.Unmarshal([]byte(line), &decoded); err != nil {
+				t.Fatalf("output %q is not valid JSON: %v", line, err)
+			}
+		})
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name   string
+		format string
+		fields map[string]any
+		log    func(l *Logger)
+		want   string
+	}{
+		{
+			name:   "text",
+			format: FormatText,
+			log:    func(l *Logger) { l.Info("server started") },
+			want:   "2026/01/02 03:04:05 [INFO] server started\n",
+		},
+		{
+			name:   "text with fields",
+			format: FormatText,
+			fields: map[string]any{"request_id": "abc", "path": "/api v1"},
+			log:    func(l *Logger) { l.Warn("slow request") },
+			want:   "2026/01/02 03:04:05 [WARN] slow request path=\"/api v1\" request_id=abc\n",
+		},
+		{
+			name:   "json",
+			format: FormatJSON,
+			log:    func(l *Logger) { l.Error("query failed") },
+			want:   `{"timestamp":"2026-01-02T03:04:05Z","level":"ERROR","message":"query failed"}` + "\n",
+		},
+		{
+			name:   "json with fields",
+			format: FormatJSON,
+			fields: map[string]any{"status": 503, "level": "shadowed"},
+			log:    func(l *Logger) { l.Info("probe") },
+			want:   `{"timestamp":"2026-01-02T03:04:05Z","level":"INFO","message":"probe","field.level":"shadowed","status":503}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			l := New("debug", WithFormat(tt.format), WithOutput(&out, &out), WithColor(ColorNever), WithClock(func() time.Time { return fixed }))
+			if tt.fields != nil {
+				l = l.WithFields(tt.fields)
+			}
+			tt.log(l)
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		stackLevel string
+		log        func(l *Logger)
+		wantStack  bool
+	}{
+		{name: "below stack level", stackLevel: "error", log: func(l *Logger) { l.Warn("m") }},
+		{name: "at stack level", stackLevel: "error", log: func(l *Logger) { l.Error("m") }, wantStack: true},
+		{name: "above stack level", stackLevel: "warn", log: func(l *Logger) { l.Error("m") }, wantStack: true},
+		{name: "disabled by default", log: func(l *Logger) { l.Error("m") }},
+		{name: "unrecognized level disables", stackLevel: "loud", log: func(l *Logger) { l.Error("m") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			l := New("debug", WithFormat(FormatJSON), WithOutput(&out, &out), WithStackLevel(tt.stackLevel))
+			tt.log(l)
+
+			var decoded map[string]any
+			if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+				t.Fatalf("output %q is not valid JSON: %v", out.String(), err)
+			}
+			stack, ok := decoded["stack"].(string)
+			if ok != tt.wantStack {
+				t.Fatalf("stack present = %v, want %v in %q", ok, tt.wantStack, out.String())
+			}
+			if ok && !strings.Contains(stack, "goroutine") {
+				t.Errorf("stack %q doesn't look like a goroutine trace", stack)
+			}
+		})
+	}
+}
+
+func TestSetStackLevel(t *testing.T) {
+	var out bytes.Buffer
+	l := New("debug", WithFormat(FormatText), WithOutput(&out, &out), WithColor(ColorNever))
+
+	l.Error("before")
+	if strings.Contains(out.String(), "stack=") {
+		t.Fatalf("stack attached before SetStackLevel: %q", out.String())
+	}
+
+	l.SetStackLevel("warn")
+	out.Reset()
+	l.Info("info")
+	if strings.Contains(out.String(), "stack=") {
+		t.Fatalf("stack attached below the stack level: %q", out.String())
+	}
+	l.Warn("warn")
+	if !strings.Contains(out.String(), "[WARN] warn\nstack=goroutine") {
+		t.Fatalf("stack missing at the stack level: %q", out.String())
+	}
+
+	l.SetStackLevel("")
+	out.Reset()
+	l.Error("after")
+	if strings.Contains(out.String(), "stack=") {
+		t.Fatalf("stack attached after disabling: %q", out.String())
+	}
+}
+
+func TestFieldKeys(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		fields  map[string]any
+		want    string
+	}{
+		{
+			name: "defaults",
+			want: `{"timestamp":"2026-01-02T03:04:05Z","level":"INFO","message":"probe"}` + "\n",
+		},
+		{
+			name:    "renamed",
+			mapping: map[string]string{"level": "severity", "timestamp": "@timestamp", "message": "msg"},
+			want:    `{"@timestamp":"2026-01-02T03:04:05Z","severity":"INFO","msg":"probe"}` + "\n",
+		},
+		{
+			name:    "field named like a renamed key",
+			mapping: map[string]string{"message": "msg"},
+			fields:  map[string]any{"msg": "shadowed", "message": "kept"},
+			want:    `{"timestamp":"2026-01-02T03:04:05Z","level":"INFO","msg":"probe","message":"kept","field.msg":"shadowed"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseFieldKeys(tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			l := New("info", WithFormat(FormatJSON), WithOutput(&out, &out), WithFieldKeys(keys), WithClock(func() time.Time { return fixed }))
+			if tt.fields != nil {
+				l = l.WithFields(tt.fields)
+			}
+			l.Info("probe")
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldKeysRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+	}{
+		{name: "unknown field", mapping: map[string]string{"severity": "sev"}},
+		{name: "empty key", mapping: map[string]string{"level": ""}},
+		{name: "two fields share a key", mapping: map[string]string{"level": "msg", "message": "msg"}},
+		{name: "renamed onto a default key", mapping: map[string]string{"level": "message"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseFieldKeys(tt.mapping); err == nil {
+				t.Errorf("ParseFieldKeys(%v) succeeded, want an error", tt.mapping)
+			}
+		})
+	}
+}
+
+func TestFormatForEnvironment(t *testing.T) {
+	tests := []struct {
+		environment string
+		want        string
+	}{
+		{environment: "production", want: FormatJSON},
+		{environment: "Production", want: FormatJSON},
+		{environment: "staging", want: FormatText},
+		{environment: "development", want: FormatText},
+		{environment: "", want: FormatText},
+	}
+
+	for _, tt := range tests {
+		if got := FormatForEnvironment(tt.environment); got != tt.want {
+			t.Errorf("FormatForEnvironment(%q) = %q, want %q", tt.environment, got, tt.want)
+		}
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		order []string
+		want  string
+	}{
+		{
+			name: "default",
+			want: `{"timestamp":"2026-01-02T03:04:05Z","level":"INFO","message":"probe","user":"alice"}` + "\n",
+		},
+		{
+			name:  "listed fields first",
+			order: []string{"message", "level"},
+			want:  `{"message":"probe","level":"INFO","timestamp":"2026-01-02T03:04:05Z","user":"alice"}` + "\n",
+		},
+		{
+			name:  "context fields first",
+			order: []string{"fields", "timestamp", "level", "message", "stack"},
+			want:  `{"user":"alice","timestamp":"2026-01-02T03:04:05Z","level":"INFO","message":"probe"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := ParseFieldOrder(tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			l := New("info", WithFormat(FormatJSON), WithOutput(&out, &out), WithFieldOrder(order), WithClock(func() time.Time { return fixed }))
+			l.WithFields(map[string]any{"user": "alice"}).Info("probe")
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldOrderRejects(t *testing.T) {
+	for _, names := range [][]string{
+		{"severity"},
+		{"level", "message", "level"},
+	} {
+		if _, err := ParseFieldOrder(names); err == nil {
+			t.Errorf("ParseFieldOrder(%q) succeeded, want an error", names)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// output holds a logger's destinations, shared with loggers derived by
+// WithFields. Each stream has its own *log.Logger, which serializes writes.
+type output struct {
+	out, err *log.Logger
+
+	// colorOut and colorErr are resolved from the color mode and the current
+	// writers; see WithColor
+	colorOut, colorErr atomic.Bool
+
+	// unified sends error lines to the normal writer too; see
+	// WithSeparateErrorStream
+	unified atomic.Bool
+}
+
+func newOutput(out, errOut io.Writer) *output {
+	return &output{
+		out: log.New(out, "", 0),
+		err: log.New(errOut, "", 0),
+	}
+}
+
+// toErr reports whether lines at level go to the error writer
+func (o *output) toErr(level LogLevel) bool {
+	return level == ERROR && !o.unified.Load()
+}
+
+// stream returns the destination for lines at level: errors go to the error
+// writer unless the streams are unified, everything else to the normal one
+func (o *output) stream(level LogLevel) *log.Logger {
+	if o.toErr(level) {
+		return o.err
+	}
+	return o.out
+}
+
+// WithOutput writes ERROR lines to errOut and all other lines to out instead
+// of stderr and stdout. A nil writer keeps its default.
+func WithOutput(out, errOut io.Writer) Option {
+	return func(l *Logger) {
+		if out != nil {
+			l.output.out.SetOutput(out)
+		}
+		if errOut != nil {
+			l.output.err.SetOutput(errOut)
+		}
+	}
+}
+
+// WithSeparateErrorStream chooses whether ERROR lines go to the error writer
+// (the default) or, when separate is false, to the normal writer along with
+// every other level, for deployments that collect a single stream
+func WithSeparateErrorStream(separate bool) Option {
+	return func(l *Logger) {
+		l.output.unified.Store(!separate)
+	}
+}
+
+// SetOutput sends lines at every level to w, including those of loggers
+// derived by WithFields. It is safe to call while other goroutines are
+// logging.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.output.out.SetOutput(w)
+	l.output.err.SetOutput(w)
+	l.resolveColor()
+}
+
+// defaultOutput writes to stdout, with errors to stderr
+func defaultOutput() *output {
+	return newOutput(os.Stdout, os.Stderr)
+}
@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetOutput(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var before, after bytes.Buffer
+	l := New("info", WithOutput(&before, &before), WithColor(ColorNever), WithClock(func() time.Time { return fixed }))
+	derived := l.WithFields(map[string]any{"component": "api"})
+
+	l.Info("first")
+	l.SetOutput(&after)
+	l.Info("second")
+	l.Error("failed")
+	derived.Warn("derived")
+
+	if want := "2026/01/02 03:04:05 [INFO] first\n"; before.String() != want {
+		t.Errorf("original writer got %q, want %q", before.String(), want)
+	}
+	want := "2026/01/02 03:04:05 [INFO] second\n" +
+		"2026/01/02 03:04:05 [ERROR] failed\n" +
+		"2026/01/02 03:04:05 [WARN] derived component=api\n"
+	if after.String() != want {
+		t.Errorf("injected writer got %q, want %q", after.String(), want)
+	}
+}
+
+func TestConcurrentWritesToInjectedWriters(t *testing.T) {
+	var out, errOut bytes.Buffer
+	l := New("info", WithOutput(&out, &errOut), WithColor(ColorNever))
+
+	const n = 50
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(2)
+		go func() { defer wg.Done(); l.Info("info line") }()
+		go func() { defer wg.Done(); l.Error("error line") }()
+	}
+	wg.Wait()
+
+	if got := strings.Count(out.String(), "[INFO] info line\n"); got != n {
+		t.Errorf("normal writer has %d info lines, want %d", got, n)
+	}
+	if got := strings.Count(errOut.String(), "[ERROR] error line\n"); got != n {
+		t.Errorf("error writer has %d error lines, want %d", got, n)
+	}
+}
+
+func TestSeparateErrorStream(t *testing.T) {
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name              string
+		opts              []Option
+		wantOut, wantErrs string
+	}{
+		{
+			name:     "separate by default",
+			wantOut:  "2026/01/02 03:04:05 [INFO] started\n2026/01/02 03:04:05 [WARN] slow\n",
+			wantErrs: "2026/01/02 03:04:05 [ERROR] failed\n",
+		},
+		{
+			name:    "unified",
+			opts:    []Option{WithSeparateErrorStream(false)},
+			wantOut: "2026/01/02 03:04:05 [INFO] started\n2026/01/02 03:04:05 [WARN] slow\n2026/01/02 03:04:05 [ERROR] failed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+			opts := append([]Option{WithOutput(&out, &errOut), WithColor(ColorNever), WithClock(func() time.Time { return fixed })}, tt.opts...)
+			l := New("info", opts...)
+			l.Info("started")
+			l.Warn("slow")
+			l.Error("failed")
+
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("normal writer got %q, want %q", got, tt.wantOut)
+			}
+			if got := errOut.String(); got != tt.wantErrs {
+				t.Errorf("error writer got %q, want %q", got, tt.wantErrs)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateWindow is the period over which per-level rate limits are counted and
+// suppressed lines are summarized
+const rateWindow = time.Second
+
+// ParseRateLimits converts a mapping from level names to lines per second
+// into rate limits. ERROR lines are only limited when listed explicitly.
+func ParseRateLimits(mapping map[string]string) (map[LogLevel]int, error) {
+	limits := make(map[LogLevel]int, len(mapping))
+	for name, value := range mapping {
+		level, ok := ParseLevel(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown log level %q in rate limits", name)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("log rate limit for %s must be a positive integer, got %q", level, value)
+		}
+		limits[level] = n
+	}
+	return limits, nil
+}
+
+// WithRateLimits caps how many lines per second are logged at each level in
+// limits; levels not listed are never limited. Excess lines are dropped and
+// a "N messages suppressed" line is logged at that level once per second
+// while suppression continues. Call Close to stop the summarizer.
+func WithRateLimits(limits map[LogLevel]int) Option {
+	return func(l *Logger) {
+		if len(limits) == 0 {
+			return
+		}
+		l.limiter = newRateLimiter(limits, l.onSuppress)
+		go l.summarize(l.limiter)
+	}
+}
+
+// WithSuppressHook registers fn to be called for every line suppressed by a
+// rate limit
+func WithSuppressHook(fn func(level LogLevel)) Option {
+	return func(l *Logger) {
+		l.onSuppress = fn
+		if l.limiter != nil {
+			l.limiter.onSuppress = fn
+		}
+	}
+}
+
+// rateLimiter counts lines per level within the current window
+type rateLimiter struct {
+	limits     map[LogLevel]int
+	onSuppress func(LogLevel)
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[LogLevel]int
+	suppressed  map[LogLevel]uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newRateLimiter(limits map[LogLevel]int, onSuppress func(LogLevel)) *rateLimiter {
+	return &rateLimiter{
+		limits:     limits,
+		onSuppress: onSuppress,
+		counts:     make(map[LogLevel]int, len(limits)),
+		suppressed: make(map[LogLevel]uint64, len(limits)),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// allow reports whether a line at level fits within its limit, recording it
+// as suppressed otherwise
+func (r *rateLimiter) allow(level LogLevel, now time.Time) bool {
+	limit, ok := r.limits[level]
+	if !ok {
+		return true
+	}
+
+	r.mu.Lock()
+	if now.Sub(r.windowStart) >= rateWindow {
+		r.windowStart = now
+		clear(r.counts)
+	}
+	r.counts[level]++
+	allowed := r.counts[level] <= limit
+	if !allowed {
+		r.suppressed[level]++
+	}
+	r.mu.Unlock()
+
+	if !allowed && r.onSuppress != nil {
+		r.onSuppress(level)
+	}
+	return allowed
+}
+
+// takeSuppressed returns and resets the suppressed line counts
+func (r *rateLimiter) takeSuppressed() map[LogLevel]uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.suppressed) == 0 {
+		return nil
+	}
+	taken := r.suppressed
+	r.suppressed = make(map[LogLevel]uint64, len(r.limits))
+	return taken
+}
+
+// summarize logs suppression summaries every window until the limiter is stopped
+func (l *Logger) summarize(r *rateLimiter) {
+	defer close(r.done)
+	ticker := time.NewTicker(rateWindow)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			l.flushSuppressed(r)
+			return
+		case <-ticker.C:
+			l.flushSuppressed(r)
+		}
+	}
+}
+
+// flushSuppressed logs one summary line per level that had lines suppressed,
+// bypassing the rate limit
+func (l *Logger) flushSuppressed(r *rateLimiter) {
+	suppressed := r.takeSuppressed()
+	for _, level := range []LogLevel{DEBUG, INFO, WARN, ERROR} {
+		if n := suppressed[level]; n > 0 {
+			l.write(level, fmt.Sprintf("%d %s messages suppressed by rate limit", n, level))
+		}
+	}
+}
+
+// close stops the summarizer after logging a final summary
+func (r *rateLimiter) close() {
+	select {
+	case <-r.stop:
+	default:
+		close(r.stop)
+	}
+	<-r.done
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitSuppression(t *testing.T) {
+	var out syncBuffer
+	var now atomic.Pointer[time.Time]
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	now.Store(&start)
+	var suppressed atomic.Int64
+
+	l := New("debug", WithOutput(&out, &out), WithColor(ColorNever),
+		WithClock(func() time.Time { return *now.Load() }),
+		WithRateLimits(map[LogLevel]int{INFO: 2}),
+		WithSuppressHook(func(level LogLevel) {
+			if level == INFO {
+				suppressed.Add(1)
+			}
+		}))
+
+	for i := range 5 {
+		l.Info(fmt.Sprintf("info %d", i))
+		l.Warn(fmt.Sprintf("warn %d", i))
+	}
+	// A new window starts over
+	later := start.Add(rateWindow)
+	now.Store(&later)
+	l.Info("info in the next window")
+
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	written := out.String()
+
+	for _, line := range []string{"info 0", "info 1", "info in the next window"} {
+		if !strings.Contains(written, line+"\n") {
+			t.Errorf("%q was suppressed: %q", line, written)
+		}
+	}
+	for _, line := range []string{"info 2", "info 3", "info 4"} {
+		if strings.Contains(written, line+"\n") {
+			t.Errorf("%q was logged past the limit: %q", line, written)
+		}
+	}
+	if got := strings.Count(written, "[WARN] warn"); got != 5 {
+		t.Errorf("logged %d warnings, want all 5 as WARN isn't limited", got)
+	}
+	if !strings.Contains(written, "[INFO] 3 INFO messages suppressed by rate limit\n") {
+		t.Errorf("output %q has no suppression summary", written)
+	}
+	if got := suppressed.Load(); got != 3 {
+		t.Errorf("suppress hook calls = %d, want 3", got)
+	}
+}
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := ParseRateLimits(map[string]string{"debug": "10", "warn": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limits) != 2 || limits[DEBUG] != 10 || limits[WARN] != 5 {
+		t.Errorf("limits = %v, want DEBUG:10 WARN:5", limits)
+	}
+
+	for _, mapping := range []map[string]string{
+		{"loud": "10"},
+		{"info": "0"},
+		{"info": "many"},
+	} {
+		if _, err := ParseRateLimits(mapping); err == nil {
+			t.Errorf("ParseRateLimits(%v) succeeded, want an error", mapping)
+		}
+	}
+}
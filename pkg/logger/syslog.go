@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Output destinations
+const (
+	OutputStdout = "stdout"
+	OutputSyslog = "syslog"
+)
+
+// SyslogWriter writes messages at syslog severities; *syslog.Writer
+// implements it
+type SyslogWriter interface {
+	Debug(m string) error
+	Info(m string) error
+	Warning(m string) error
+	Err(m string) error
+}
+
+// WithSyslog sends log lines to w instead of stdout and stderr, at the
+// severity matching their level
+func WithSyslog(w SyslogWriter) Option {
+	return func(l *Logger) {
+		l.syslog = w
+	}
+}
+
+// ValidateOutput checks a log output destination name
+func ValidateOutput(output string) error {
+	switch strings.ToLower(output) {
+	case OutputStdout, OutputSyslog:
+		return nil
+	default:
+		return fmt.Errorf("unknown log output %q (want %s or %s)", output, OutputStdout, OutputSyslog)
+	}
+}
+
+// writeSyslog writes msg to w at the syslog severity for level
+func writeSyslog(w SyslogWriter, level LogLevel, msg string) error {
+	switch level {
+	case DEBUG:
+		return w.Debug(msg)
+	case WARN:
+		return w.Warning(msg)
+	case ERROR:
+		return w.Err(msg)
+	default:
+		return w.Info(msg)
+	}
+}
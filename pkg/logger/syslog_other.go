@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logger
+
+import "errors"
+
+// DialSyslog is unavailable on this platform
+func DialSyslog(network, address, facility, tag string) (SyslogWriter, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
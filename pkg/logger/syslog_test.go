@@ -0,0 +1,42 @@
+package logger
+
+import "testing"
+
+// mockSyslog records each message with the severity it was written at
+type mockSyslog struct {
+	lines []string
+}
+
+func (m *mockSyslog) record(severity, msg string) error {
+	m.lines = append(m.lines, severity+" "+msg)
+	return nil
+}
+
+func (m *mockSyslog) Debug(msg string) error   { return m.record("debug", msg) }
+func (m *mockSyslog) Info(msg string) error    { return m.record("info", msg) }
+func (m *mockSyslog) Warning(msg string) error { return m.record("warning", msg) }
+func (m *mockSyslog) Err(msg string) error     { return m.record("err", msg) }
+
+func TestSyslogSeverity(t *testing.T) {
+	tests := []struct {
+		level string
+		log   func(l *Logger, msg string)
+		want  string
+	}{
+		{level: "debug", log: (*Logger).Debug, want: "debug [DEBUG] probe\n"},
+		{level: "info", log: (*Logger).Info, want: "info [INFO] probe\n"},
+		{level: "warn", log: (*Logger).Warn, want: "warning [WARN] probe\n"},
+		{level: "error", log: (*Logger).Error, want: "err [ERROR] probe\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			w := &mockSyslog{}
+			l := New("debug", WithSyslog(w), WithColor(ColorNever))
+			tt.log(l, "probe")
+			if len(w.lines) != 1 || w.lines[0] != tt.want {
+				t.Errorf("syslog lines = %q, want [%q]", w.lines, tt.want)
+			}
+		})
+	}
+}